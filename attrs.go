@@ -0,0 +1,207 @@
+package otelx
+
+import (
+	"container/list"
+	"hash/maphash"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// maxCachedAttributeSets bounds the number of precomputed attribute sets kept
+// by the request instrumentation.
+const maxCachedAttributeSets = 1024
+
+// attrKey identifies a combination of request attributes recorded by the
+// middleware and interceptors.
+//
+// It is a comparable struct so that lookups do not allocate.
+type attrKey struct {
+	method string
+	path   string
+	code   int
+	http   bool
+
+	// Optional dimensions, omitted when empty.
+	userAgent     string
+	clientVersion string
+
+	// Target service dimensions of outgoing gRPC calls.
+	rpcService    string
+	serverAddress string
+
+	// flags holds the feature flag dimensions (see featureFlagDimensions).
+	flags string
+
+	// synthetic is the synthetic traffic dimension (see syntheticDimension).
+	synthetic string
+
+	// rejected is the rejection reason of requests turned away before
+	// reaching the handler (see RecordRejectedRequest).
+	rejected string
+}
+
+// attributes builds the attribute list for k.
+//
+// HTTP requests carry method, path and status_code while gRPC calls only carry
+// method and status_code, matching the attributes documented on the
+// middleware and interceptors. Optional dimensions are appended when set.
+func (k attrKey) attributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("method", k.method)}
+	if k.http {
+		attrs = append(attrs, attribute.String("path", k.path))
+	}
+	attrs = append(attrs, attribute.Int("status_code", k.code))
+
+	if k.userAgent != "" {
+		attrs = append(attrs, attribute.String("user_agent", k.userAgent))
+	}
+	if k.clientVersion != "" {
+		attrs = append(attrs, attribute.String("client_version", k.clientVersion))
+	}
+	if k.rpcService != "" {
+		attrs = append(attrs, attribute.String("rpc.service", k.rpcService))
+	}
+	if k.serverAddress != "" {
+		attrs = append(attrs, attribute.String("server.address", k.serverAddress))
+	}
+	if k.synthetic != "" {
+		attrs = append(attrs, attribute.String("synthetic", k.synthetic))
+	}
+	if k.rejected != "" {
+		attrs = append(attrs, attribute.String("rejected", k.rejected))
+	}
+	attrs = append(attrs, flagDimensionAttributes(k.flags)...)
+
+	if allowed := settings().metricAttributes; allowed != nil {
+		kept := attrs[:0]
+		for _, kv := range attrs {
+			if allowed[kv.Key] {
+				kept = append(kept, kv)
+			}
+		}
+		attrs = kept
+	}
+	return attrs
+}
+
+// guarded returns k with each string attribute passed through the
+// cardinality guard configured with WithMetricCardinalityLimit.
+func (k attrKey) guarded() attrKey {
+	if settings().attributeValueLimit <= 0 {
+		return k
+	}
+	k.method = attributeValues.admit("method", k.method)
+	k.path = attributeValues.admit("path", k.path)
+	k.userAgent = attributeValues.admit("user_agent", k.userAgent)
+	k.clientVersion = attributeValues.admit("client_version", k.clientVersion)
+	return k
+}
+
+// attrCacheShards is the number of independently locked shards of an
+// attrCache.
+const attrCacheShards = 16
+
+// attrCache is a bounded LRU cache of precomputed attribute sets wrapped in
+// metric.WithAttributeSet.
+//
+// Building attributes and measurement options on every request is a noticeable
+// source of allocations at high request rates. Caching the resulting option per
+// method/path/status combination lets hot paths reuse it for both the counter
+// and the histogram.
+//
+// Every request goes through the cache, so it is split into shards by method
+// and path, each with its own lock and LRU list, so concurrent requests to
+// different routes do not contend.
+type attrCache struct {
+	seed     maphash.Seed
+	perShard int
+	shards   [attrCacheShards]attrShard
+}
+
+// attrShard is one shard of an attrCache.
+type attrShard struct {
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[attrKey]*list.Element
+}
+
+// attrEntry is an element of an attrShard's LRU list.
+type attrEntry struct {
+	key attrKey
+	opt metric.MeasurementOption
+}
+
+// newAttrCache returns an attrCache holding at most about size entries.
+func newAttrCache(size int) *attrCache {
+	c := &attrCache{
+		seed:     maphash.MakeSeed(),
+		perShard: max(1, size/attrCacheShards),
+	}
+	for i := range c.shards {
+		c.shards[i].ll = list.New()
+		c.shards[i].entries = make(map[attrKey]*list.Element, c.perShard)
+	}
+	return c
+}
+
+// requestAttrs is the cache shared by the HTTP middleware and gRPC
+// interceptors.
+var requestAttrs = newAttrCache(maxCachedAttributeSets)
+
+// shard returns the shard of k, picked by its method and path: the path
+// varies most across HTTP requests and the method, the full method name,
+// across gRPC calls.
+func (c *attrCache) shard(k attrKey) *attrShard {
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+	h.WriteString(k.method)
+	h.WriteByte(0)
+	h.WriteString(k.path)
+	return &c.shards[h.Sum64()%attrCacheShards]
+}
+
+// get returns the measurement option for k, building and caching it on a miss.
+// When k's shard is full its least recently used entry is evicted.
+func (c *attrCache) get(k attrKey) metric.MeasurementOption {
+	k = k.guarded()
+	s := c.shard(k)
+
+	s.mu.Lock()
+	if el, ok := s.entries[k]; ok {
+		s.ll.MoveToFront(el)
+		s.mu.Unlock()
+		return el.Value.(*attrEntry).opt
+	}
+	s.mu.Unlock()
+
+	// Build the set outside the lock; it allocates.
+	opt := metric.WithAttributeSet(attribute.NewSet(k.attributes()...))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[k]; ok {
+		s.ll.MoveToFront(el)
+		return el.Value.(*attrEntry).opt
+	}
+	s.entries[k] = s.ll.PushFront(&attrEntry{key: k, opt: opt})
+	if s.ll.Len() > c.perShard {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.entries, oldest.Value.(*attrEntry).key)
+	}
+	return opt
+}
+
+// reset empties the cache. It is called when the options that shape the
+// cached attribute sets, such as WithMetricAttributes, change.
+func (c *attrCache) reset() {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		s.ll.Init()
+		clear(s.entries)
+		s.mu.Unlock()
+	}
+}
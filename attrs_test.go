@@ -0,0 +1,96 @@
+package otelx
+
+import (
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func TestAttrCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newAttrCache(2 * attrCacheShards)
+
+	// Three keys of the same shard, which holds two entries.
+	var keys []attrKey
+	for i := 0; len(keys) < 3; i++ {
+		k := attrKey{method: "GET", path: fmt.Sprintf("/items/%d", i), code: 200, http: true}
+		if len(keys) == 0 || c.shard(k) == c.shard(keys[0]) {
+			keys = append(keys, k)
+		}
+	}
+	a, b, d := keys[0], keys[1], keys[2]
+
+	first := c.get(a)
+	c.get(b)
+	if got := c.get(a); got != first {
+		t.Fatal("cached option for a was rebuilt")
+	}
+	c.get(d)
+
+	tests := []struct {
+		name   string
+		key    attrKey
+		cached bool
+	}{
+		{name: "recently used", key: a, cached: true},
+		{name: "least recently used", key: b, cached: false},
+		{name: "newest", key: d, cached: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := c.shard(tt.key)
+			if _, ok := s.entries[tt.key]; ok != tt.cached {
+				t.Errorf("cached = %v, want %v", ok, tt.cached)
+			}
+		})
+	}
+	if got := c.shard(a).ll.Len(); got != 2 {
+		t.Errorf("shard holds %d entries, want 2", got)
+	}
+}
+
+func TestAttrCacheSpreadsRPCMethods(t *testing.T) {
+	c := newAttrCache(maxCachedAttributeSets)
+
+	shards := make(map[*attrShard]bool)
+	for i := 0; i < 64; i++ {
+		shards[c.shard(attrKey{method: fmt.Sprintf("/shop.v1.Cart/Method%d", i)})] = true
+	}
+	if len(shards) < attrCacheShards/2 {
+		t.Errorf("64 gRPC methods use %d shards, want them spread", len(shards))
+	}
+}
+
+func TestAttrCacheResetOnConfigure(t *testing.T) {
+	t.Cleanup(func() {
+		shared.Store(nil)
+		requestAttrs.reset()
+	})
+
+	k := attrKey{method: "GET", path: "/orders", code: 200, http: true}
+	tests := []struct {
+		name string
+		opts []Option
+		want []attribute.Key
+	}{
+		{name: "defaults", want: []attribute.Key{"method", "path", "status_code"}},
+		{name: "allowlist", opts: []Option{WithMetricAttributes("method")}, want: []attribute.Key{"method"}},
+		{name: "defaults again", want: []attribute.Key{"method", "path", "status_code"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configure(tt.opts)
+			set := metric.NewAddConfig([]metric.AddOption{requestAttrs.get(k)}).Attributes()
+
+			var got []attribute.Key
+			for _, kv := range set.ToSlice() {
+				got = append(got, kv.Key)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("attribute keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package otelx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithCapturedHeaders records the listed incoming HTTP request headers on
+// SERVER spans as http.request.header.<name> attributes (lowercased, as in the
+// semantic conventions).
+//
+// Only allowlisted headers are recorded, and values still pass through the
+// scrub rules, so debugging client-specific behavior does not require logging
+// every header.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "edge", otelx.WithCapturedHeaders("X-Client-Name"))
+func WithCapturedHeaders(headers ...string) Option {
+	return func(c *config) {
+		for _, h := range headers {
+			c.capturedHeaders = append(c.capturedHeaders, strings.ToLower(h))
+		}
+	}
+}
+
+// WithCapturedMetadata records the listed incoming gRPC metadata keys on
+// SERVER spans created by the tracing interceptors, as
+// rpc.grpc.request.metadata.<key> attributes. It mirrors WithCapturedHeaders
+// for gRPC.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "billing",
+//	    otelx.WithCapturedMetadata("x-client-name", "x-client-build"),
+//	)
+func WithCapturedMetadata(keys ...string) Option {
+	return func(c *config) {
+		for _, k := range keys {
+			c.capturedMetadata = append(c.capturedMetadata, strings.ToLower(k))
+		}
+	}
+}
+
+// capturedHeaderAttributes returns the allowlisted headers of r as span
+// attributes.
+func capturedHeaderAttributes(r *http.Request) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, h := range settings().capturedHeaders {
+		if v := r.Header.Values(h); len(v) > 0 {
+			attrs = append(attrs, attribute.StringSlice("http.request.header."+h, v))
+		}
+	}
+	return attrs
+}
+
+// capturedMetadataAttributes returns the allowlisted incoming gRPC metadata of
+// ctx as span attributes.
+func capturedMetadataAttributes(ctx context.Context) []attribute.KeyValue {
+	if len(settings().capturedMetadata) == 0 {
+		return nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	for _, k := range settings().capturedMetadata {
+		if v := md.Get(k); len(v) > 0 {
+			attrs = append(attrs, attribute.StringSlice("rpc.grpc.request.metadata."+k, v))
+		}
+	}
+	return attrs
+}
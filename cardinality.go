@@ -0,0 +1,93 @@
+package otelx
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// WithMetricCardinalityLimit caps the number of distinct values each request
+// metric attribute (method, path, user_agent, client_version) may take. Once
+// an attribute has seen limit values, new values are recorded as "other", so a
+// single unbounded label cannot create millions of series. A limit of zero or
+// less disables the guard.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "auth-service", otelx.WithMetricCardinalityLimit(200))
+func WithMetricCardinalityLimit(limit int) Option {
+	return func(c *config) {
+		c.attributeValueLimit = limit
+	}
+}
+
+// WithMetricAttributes restricts metric attributes to keys: every other
+// attribute is dropped from the request metrics and from the instruments
+// created through the MeterProvider, merging the series it would have split.
+// otelx's own telemetry is not affected.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "auth-service",
+//	    otelx.WithMetricAttributes("method", "path", "status_code"),
+//	)
+func WithMetricAttributes(keys ...string) Option {
+	return func(c *config) {
+		c.metricAttributes = make(map[attribute.Key]bool, len(keys))
+		for _, k := range keys {
+			c.metricAttributes[attribute.Key(k)] = true
+		}
+	}
+}
+
+// metricAttributesView returns a view applying the WithMetricAttributes
+// allowlist to every instrument outside of otelx's own scope, or nil when no
+// allowlist is configured.
+func (c config) metricAttributesView() sdkmetric.View {
+	if c.metricAttributes == nil {
+		return nil
+	}
+
+	allowed := c.metricAttributes
+	view := sdkmetric.NewView(
+		sdkmetric.Instrument{Name: "*"},
+		sdkmetric.Stream{AttributeFilter: func(kv attribute.KeyValue) bool {
+			return allowed[kv.Key]
+		}},
+	)
+	return func(i sdkmetric.Instrument) (sdkmetric.Stream, bool) {
+		if i.Scope.Name == instrumentationName {
+			return sdkmetric.Stream{}, false
+		}
+		return view(i)
+	}
+}
+
+// cardinalityGuard caps the number of distinct values recorded per metric
+// attribute, replacing further values with "other".
+type cardinalityGuard struct {
+	mu       sync.Mutex
+	limiters map[string]*valueLimiter
+}
+
+// attributeValues is the guard shared by the request instruments.
+var attributeValues = &cardinalityGuard{limiters: make(map[string]*valueLimiter)}
+
+// admit returns v if attribute key has room for it, and "other" otherwise.
+// Empty values are left untouched.
+func (g *cardinalityGuard) admit(key, v string) string {
+	if v == "" {
+		return v
+	}
+
+	g.mu.Lock()
+	l, ok := g.limiters[key]
+	if !ok {
+		l = newValueLimiter(settings().attributeValueLimit)
+		g.limiters[key] = l
+	}
+	g.mu.Unlock()
+
+	return l.admit(v)
+}
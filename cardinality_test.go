@@ -0,0 +1,105 @@
+package otelx
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestCardinalityGuard(t *testing.T) {
+	useSettings(t, func(c *config) { c.attributeValueLimit = 2 })
+	g := &cardinalityGuard{limiters: make(map[string]*valueLimiter)}
+
+	tests := []struct {
+		key, value string
+		want       string
+	}{
+		{key: "path", value: "/orders/1", want: "/orders/1"},
+		{key: "path", value: "/orders/2", want: "/orders/2"},
+		{key: "path", value: "/orders/3", want: overflowValue},
+		{key: "path", value: "/orders/1", want: "/orders/1"},
+		{key: "path", value: "", want: ""},
+		{key: "method", value: "GET", want: "GET"},
+		{key: "method", value: "POST", want: "POST"},
+		{key: "method", value: "BREW", want: overflowValue},
+	}
+
+	for _, tt := range tests {
+		if got := g.admit(tt.key, tt.value); got != tt.want {
+			t.Errorf("admit(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAttrKeyGuarded(t *testing.T) {
+	k := attrKey{method: "GET", path: "/orders/42", code: 200, http: true}
+
+	t.Run("disabled", func(t *testing.T) {
+		useSettings(t, func(c *config) { c.attributeValueLimit = 0 })
+		if got := k.guarded(); got != k {
+			t.Errorf("guarded() = %+v, want %+v", got, k)
+		}
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		useSettings(t, func(c *config) { c.attributeValueLimit = 1 })
+		saved := attributeValues
+		attributeValues = &cardinalityGuard{limiters: make(map[string]*valueLimiter)}
+		t.Cleanup(func() { attributeValues = saved })
+
+		k.guarded()
+		got := attrKey{method: "GET", path: "/orders/43", code: 200, http: true}.guarded()
+		want := attrKey{method: "GET", path: overflowValue, code: 200, http: true}
+		if got != want {
+			t.Errorf("guarded() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestMetricAttributesView(t *testing.T) {
+	var cfg config
+	WithMetricAttributes("method")(&cfg)
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithView(cfg.metricAttributesView()),
+	)
+	attrs := metric.WithAttributes(attribute.String("method", "GET"), attribute.String("user.id", "42"))
+
+	app, _ := mp.Meter("shop").Int64Counter("orders")
+	app.Add(context.Background(), 1, attrs)
+	own, _ := mp.Meter(instrumentationName).Int64Counter("requests")
+	own.Add(context.Background(), 1, attrs)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]attribute.Key{
+		"orders":   {"method"},
+		"requests": {"method", "user.id"},
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			set := m.Data.(metricdata.Sum[int64]).DataPoints[0].Attributes
+			var got []attribute.Key
+			for _, kv := range set.ToSlice() {
+				got = append(got, kv.Key)
+			}
+			if !slices.Equal(got, want[m.Name]) {
+				t.Errorf("%s attributes = %v, want %v", m.Name, got, want[m.Name])
+			}
+			delete(want, m.Name)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("missing metrics %v", want)
+	}
+}
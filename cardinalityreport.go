@@ -0,0 +1,200 @@
+package otelx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// cardinalityReportTop is the number of instruments logged by the
+// cardinality report.
+const cardinalityReportTop = 10
+
+// WithCardinalityReport tracks the number of distinct attribute combinations
+// (series) of every instrument as metrics are exported, and logs the
+// instruments with the most series every interval, along with the
+// attributes taking the most distinct values. It finds the handler causing
+// a label explosion before the metrics backend does.
+//
+// The latest figures are also available from CardinalityReport and in
+// DebugHandler.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "auth-service", otelx.WithCardinalityReport(10*time.Minute))
+func WithCardinalityReport(interval time.Duration) Option {
+	return func(c *config) {
+		c.cardinalityReport = interval
+	}
+}
+
+// InstrumentCardinality describes the series of one instrument.
+type InstrumentCardinality struct {
+	// Name is the instrument name.
+	Name string `json:"name"`
+
+	// Series is the number of distinct attribute combinations.
+	Series int `json:"series"`
+
+	// Attributes is the number of distinct values of each attribute key.
+	Attributes map[string]int `json:"attributes"`
+}
+
+// cardinalityStats holds the figures of the latest export.
+var cardinalityStats struct {
+	mu         sync.Mutex
+	report     []InstrumentCardinality
+	lastLogged time.Time
+}
+
+// CardinalityReport returns the instruments seen in the latest metric export
+// by decreasing number of series. It is empty unless WithCardinalityReport is
+// set.
+func CardinalityReport() []InstrumentCardinality {
+	cardinalityStats.mu.Lock()
+	defer cardinalityStats.mu.Unlock()
+	return append([]InstrumentCardinality(nil), cardinalityStats.report...)
+}
+
+// cardinalityExporter computes the cardinality report from the metrics it
+// forwards.
+type cardinalityExporter struct {
+	sdkmetric.Exporter
+	interval time.Duration
+}
+
+// Export records the cardinality of rm, logs the report when due, and
+// forwards rm.
+func (e cardinalityExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	var report []InstrumentCardinality
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if entry, ok := instrumentCardinality(m); ok {
+				report = append(report, entry)
+			}
+		}
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Series > report[j].Series
+	})
+
+	now := time.Now()
+	cardinalityStats.mu.Lock()
+	cardinalityStats.report = report
+	due := now.Sub(cardinalityStats.lastLogged) >= e.interval
+	if due {
+		cardinalityStats.lastLogged = now
+	}
+	cardinalityStats.mu.Unlock()
+
+	if due && len(report) > 0 {
+		logf("metric cardinality report:\n%s", formatCardinality(report))
+	}
+	return e.Exporter.Export(ctx, rm)
+}
+
+// instrumentCardinality counts the series and attribute values of m.
+func instrumentCardinality(m metricdata.Metrics) (InstrumentCardinality, bool) {
+	sets := pointAttributes(m.Data)
+	if len(sets) == 0 {
+		return InstrumentCardinality{}, false
+	}
+
+	values := make(map[attribute.Key]map[attribute.Value]struct{})
+	for _, set := range sets {
+		for _, kv := range set.ToSlice() {
+			if values[kv.Key] == nil {
+				values[kv.Key] = make(map[attribute.Value]struct{})
+			}
+			values[kv.Key][kv.Value] = struct{}{}
+		}
+	}
+
+	entry := InstrumentCardinality{
+		Name:       m.Name,
+		Series:     len(sets),
+		Attributes: make(map[string]int, len(values)),
+	}
+	for key, vs := range values {
+		entry.Attributes[string(key)] = len(vs)
+	}
+	return entry, true
+}
+
+// pointAttributes returns the attribute set of each data point of data.
+func pointAttributes(data metricdata.Aggregation) []attribute.Set {
+	var sets []attribute.Set
+	collect := func(set attribute.Set) { sets = append(sets, set) }
+	switch d := data.(type) {
+	case metricdata.Sum[int64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.Sum[float64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.Gauge[int64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.Gauge[float64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.Histogram[int64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.Histogram[float64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.ExponentialHistogram[int64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.ExponentialHistogram[float64]:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	case metricdata.Summary:
+		for _, p := range d.DataPoints {
+			collect(p.Attributes)
+		}
+	}
+	return sets
+}
+
+// formatCardinality renders the top entries of report, one instrument per
+// line with its attributes by decreasing number of values.
+func formatCardinality(report []InstrumentCardinality) string {
+	var b strings.Builder
+	for i, entry := range report {
+		if i == cardinalityReportTop {
+			break
+		}
+
+		keys := make([]string, 0, len(entry.Attributes))
+		for k := range entry.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return entry.Attributes[keys[i]] > entry.Attributes[keys[j]]
+		})
+		attrs := make([]string, len(keys))
+		for j, k := range keys {
+			attrs[j] = fmt.Sprintf("%s=%d", k, entry.Attributes[k])
+		}
+
+		fmt.Fprintf(&b, "  %s: %d series (%s)\n", entry.Name, entry.Series, strings.Join(attrs, ", "))
+	}
+	return b.String()
+}
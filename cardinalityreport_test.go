@@ -0,0 +1,90 @@
+package otelx
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestCardinalityExporter(t *testing.T) {
+	t.Cleanup(func() {
+		cardinalityStats.mu.Lock()
+		cardinalityStats.report = nil
+		cardinalityStats.lastLogged = time.Time{}
+		cardinalityStats.mu.Unlock()
+	})
+
+	point := func(method, path string) metricdata.DataPoint[int64] {
+		return metricdata.DataPoint[int64]{
+			Attributes: attribute.NewSet(attribute.String("method", method), attribute.String("path", path)),
+			Value:      1,
+		}
+	}
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{
+			{Name: "small", Data: metricdata.Gauge[int64]{DataPoints: []metricdata.DataPoint[int64]{point("GET", "/")}}},
+			{Name: "empty", Data: metricdata.Sum[int64]{}},
+			{Name: "large", Data: metricdata.Sum[int64]{DataPoints: []metricdata.DataPoint[int64]{
+				point("GET", "/orders/1"),
+				point("GET", "/orders/2"),
+				point("POST", "/orders/3"),
+			}}},
+		},
+	}}}
+
+	next := &fakeMetricExporter{}
+	exp := cardinalityExporter{Exporter: next, interval: time.Hour}
+	if err := exp.Export(context.Background(), rm); err != nil {
+		t.Fatal(err)
+	}
+	if len(next.names) != 3 {
+		t.Errorf("forwarded %v, want every metric", next.names)
+	}
+
+	report := CardinalityReport()
+	tests := []struct {
+		name  string
+		want  string
+		count int
+		attrs map[string]int
+	}{
+		{name: "most series first", want: "large", count: 3, attrs: map[string]int{"method": 2, "path": 3}},
+		{name: "fewer series", want: "small", count: 1, attrs: map[string]int{"method": 1, "path": 1}},
+	}
+	if len(report) != len(tests) {
+		t.Fatalf("CardinalityReport() = %+v, want %d instruments", report, len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := report[i]
+			if got.Name != tt.want || got.Series != tt.count {
+				t.Errorf("entry %d = %s with %d series, want %s with %d", i, got.Name, got.Series, tt.want, tt.count)
+			}
+			for k, n := range tt.attrs {
+				if got.Attributes[k] != n {
+					t.Errorf("%s has %d values of %s, want %d", got.Name, got.Attributes[k], k, n)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatCardinality(t *testing.T) {
+	report := make([]InstrumentCardinality, cardinalityReportTop+1)
+	for i := range report {
+		report[i] = InstrumentCardinality{Name: "m", Series: 1, Attributes: map[string]int{"path": 1}}
+	}
+	report[0] = InstrumentCardinality{Name: "requests", Series: 40, Attributes: map[string]int{"method": 2, "path": 20}}
+
+	out := formatCardinality(report)
+	if got := strings.Count(out, "\n"); got != cardinalityReportTop {
+		t.Errorf("formatCardinality() logged %d instruments, want %d", got, cardinalityReportTop)
+	}
+	if want := "  requests: 40 series (path=20, method=2)\n"; !strings.HasPrefix(out, want) {
+		t.Errorf("formatCardinality() = %q, want it to start with %q", out, want)
+	}
+}
@@ -0,0 +1,45 @@
+package otelx
+
+import (
+	"google.golang.org/grpc"
+)
+
+// ChainUnaryInterceptors returns a grpc.ServerOption installing the otelx
+// unary interceptors in the correct order around extra:
+//
+//	tracing -> metrics -> recovery -> extra... -> handler
+//
+// Tracing comes first so every other interceptor runs inside the SERVER
+// span. Metrics wrap the user interceptors, so calls rejected by an auth or
+// rate-limit interceptor are still counted with their status code. Recovery
+// sits right after them so panics in the user interceptors and the handler
+// are recorded as Internal errors.
+//
+// Use it instead of grpc.ChainUnaryInterceptor, and not together with
+// grpc.UnaryInterceptor.
+//
+// Example:
+//
+//	server := grpc.NewServer(
+//	    otelx.ChainUnaryInterceptors(authInterceptor, validationInterceptor),
+//	    otelx.ChainStreamInterceptors(authStreamInterceptor),
+//	)
+func ChainUnaryInterceptors(extra ...grpc.UnaryServerInterceptor) grpc.ServerOption {
+	interceptors := append([]grpc.UnaryServerInterceptor{
+		UnaryServerTracingInterceptor(),
+		UnaryServerMetricsInterceptor(),
+		UnaryServerRecoveryInterceptor(),
+	}, extra...)
+	return grpc.ChainUnaryInterceptor(interceptors...)
+}
+
+// ChainStreamInterceptors is the streaming counterpart of
+// ChainUnaryInterceptors.
+func ChainStreamInterceptors(extra ...grpc.StreamServerInterceptor) grpc.ServerOption {
+	interceptors := append([]grpc.StreamServerInterceptor{
+		StreamServerTracingInterceptor(),
+		StreamServerMetricsInterceptor(),
+		StreamServerRecoveryInterceptor(),
+	}, extra...)
+	return grpc.ChainStreamInterceptor(interceptors...)
+}
@@ -0,0 +1,403 @@
+package otelx
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets calls through and counts consecutive failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects calls with ErrCircuitOpen until the open timeout
+	// elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe call through; its outcome closes
+	// or reopens the circuit.
+	CircuitHalfOpen
+)
+
+// String returns "closed", "open" or "half_open".
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	}
+	return "closed"
+}
+
+// ErrCircuitOpen is returned for calls short-circuited by a CircuitBreaker.
+// It converts to a gRPC Unavailable status.
+var ErrCircuitOpen error = circuitOpenError{}
+
+// circuitOpenError is the type of ErrCircuitOpen.
+type circuitOpenError struct{}
+
+func (circuitOpenError) Error() string { return "circuit breaker is open" }
+
+// GRPCStatus lets status.Code report short-circuited calls as Unavailable.
+func (circuitOpenError) GRPCStatus() *status.Status {
+	return status.New(grpccodes.Unavailable, "circuit breaker is open")
+}
+
+// BreakerOption configures a CircuitBreaker.
+type BreakerOption func(*CircuitBreaker)
+
+// WithBreakerThreshold sets the number of consecutive failures opening the
+// circuit. The default is 5.
+func WithBreakerThreshold(n int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if n > 0 {
+			b.threshold = n
+		}
+	}
+}
+
+// WithBreakerOpenTimeout sets how long the circuit stays open before a probe
+// call is let through. The default is 30 seconds.
+func WithBreakerOpenTimeout(d time.Duration) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if d > 0 {
+			b.openTimeout = d
+		}
+	}
+}
+
+// WithBreakerProbeTimeout sets how long a half-open probe call may take
+// before another probe is let through, so a probe whose outcome is never
+// reported does not keep the circuit half-open forever. The default is the
+// open timeout.
+func WithBreakerProbeTimeout(d time.Duration) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if d > 0 {
+			b.probeTimeout = d
+		}
+	}
+}
+
+// CircuitBreaker stops calling a failing dependency for a while, and makes it
+// visible in the telemetry of the calls it protects:
+//
+//   - circuit_breaker_state{name, state}: 1 for the current state and 0 for
+//     the others
+//   - a circuit_breaker.state_change span event, on the span of the call
+//     causing a transition
+//   - circuit_breaker.name and circuit_breaker.state attributes on the spans
+//     of protected calls, and circuit_breaker.short_circuited = true with an
+//     error status on the rejected ones
+//
+// A circuit opens after a number of consecutive failures (transport errors,
+// HTTP 5xx responses, gRPC server error codes), rejects calls with
+// ErrCircuitOpen while open, and lets a single probe through once the open
+// timeout elapsed. Failures of calls whose context was canceled or whose
+// deadline expired are the caller's doing and are not counted.
+//
+// Example:
+//
+//	payments := otelx.NewCircuitBreaker("payments", otelx.WithBreakerThreshold(10))
+//
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+//	resp, err := payments.HTTPClient(ctx, req).Do(req)
+//	if errors.Is(err, otelx.ErrCircuitOpen) {
+//	    // Serve a fallback.
+//	}
+type CircuitBreaker struct {
+	name         string
+	threshold    int
+	openTimeout  time.Duration
+	probeTimeout time.Duration
+	attrs        attribute.KeyValue
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+
+	// probing is set while a half-open probe is in flight; probe identifies
+	// it and probeStarted is when it was let through.
+	probing      bool
+	probe        uint64
+	probeStarted time.Time
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker named name. The name
+// identifies it in metrics and spans, so it should be unique per dependency.
+func NewCircuitBreaker(name string, opts ...BreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		name:        name,
+		threshold:   5,
+		openTimeout: 30 * time.Second,
+		attrs:       attribute.String("circuit_breaker.name", name),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.probeTimeout == 0 {
+		b.probeTimeout = b.openTimeout
+	}
+	trackBreaker(b)
+	return b
+}
+
+// Name returns the breaker name.
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// State returns the current state.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && settings().clock.Now().Sub(b.openedAt) >= b.openTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a call may proceed. When it may, done must be called
+// with the call's outcome; otherwise Allow returns ErrCircuitOpen. A failure
+// reported once ctx is done is ignored. The span in ctx receives the breaker
+// attributes and transition events.
+//
+// Allow is the building block of the HTTP and gRPC integrations, for
+// protecting other kinds of calls:
+//
+//	done, err := breaker.Allow(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	err = publish(ctx, msg)
+//	done(err == nil)
+func (b *CircuitBreaker) Allow(ctx context.Context) (done func(success bool), err error) {
+	span := trace.SpanFromContext(ctx)
+
+	b.mu.Lock()
+	now := settings().clock.Now()
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.openTimeout {
+		b.transition(span, CircuitHalfOpen)
+	}
+	if b.probing && now.Sub(b.probeStarted) >= b.probeTimeout {
+		b.probing = false
+	}
+	state := b.state
+	if state == CircuitOpen || (state == CircuitHalfOpen && b.probing) {
+		b.mu.Unlock()
+		span.SetAttributes(b.attrs,
+			attribute.String("circuit_breaker.state", state.String()),
+			attribute.Bool("circuit_breaker.short_circuited", true),
+		)
+		span.SetStatus(codes.Error, ErrCircuitOpen.Error())
+		return nil, ErrCircuitOpen
+	}
+	var probe uint64
+	if state == CircuitHalfOpen {
+		b.probe++
+		probe = b.probe
+		b.probing, b.probeStarted = true, now
+	}
+	b.mu.Unlock()
+
+	span.SetAttributes(b.attrs, attribute.String("circuit_breaker.state", state.String()))
+	return func(success bool) { b.record(ctx, span, probe, success) }, nil
+}
+
+// record updates the breaker with the outcome of a call. probe identifies
+// the half-open probe the call was, or is 0.
+func (b *CircuitBreaker) record(ctx context.Context, span trace.Span, probe uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe != 0 && probe == b.probe {
+		b.probing = false
+	}
+	if !success && ctx.Err() != nil {
+		return
+	}
+	switch {
+	case success:
+		b.failures = 0
+		if b.state == CircuitHalfOpen {
+			b.transition(span, CircuitClosed)
+		}
+	case b.state == CircuitHalfOpen:
+		b.transition(span, CircuitOpen)
+	case b.state == CircuitClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.transition(span, CircuitOpen)
+		}
+	}
+}
+
+// transition moves the breaker to state and records it on span. b.mu must be
+// held.
+func (b *CircuitBreaker) transition(span trace.Span, state CircuitState) {
+	span.AddEvent("circuit_breaker.state_change", trace.WithAttributes(
+		b.attrs,
+		attribute.String("circuit_breaker.previous_state", b.state.String()),
+		attribute.String("circuit_breaker.state", state.String()),
+	))
+	if state == CircuitOpen {
+		b.openedAt = settings().clock.Now()
+	}
+	if state != CircuitHalfOpen {
+		b.probing = false
+	}
+	b.state = state
+	b.failures = 0
+}
+
+// Transport returns a RoundTripper calling next through the breaker. Responses
+// with a 5xx status count as failures. Wrap it with the instrumented transport
+// so short-circuited requests still get a CLIENT span; HTTPClient does.
+func (b *CircuitBreaker) Transport(next http.RoundTripper) http.RoundTripper {
+	return breakerTransport{breaker: b, next: next}
+}
+
+// HTTPClient is like the package-level HTTPClient, with requests going
+// through the breaker.
+func (b *CircuitBreaker) HTTPClient(ctx context.Context, req *http.Request, opts ...ClientOption) *http.Client {
+	client := HTTPClient(ctx, req)
+	client.Transport = clientTransport(b.Transport(http.DefaultTransport), clientOptions(opts)...)
+	return client
+}
+
+// breakerTransport is the RoundTripper returned by CircuitBreaker.Transport.
+type breakerTransport struct {
+	breaker *CircuitBreaker
+	next    http.RoundTripper
+}
+
+// RoundTrip forwards r unless the circuit is open.
+func (t breakerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	done, err := t.breaker.Allow(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(r)
+	done(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	return resp, err
+}
+
+// UnaryClientInterceptor returns an interceptor calling through the breaker.
+// gRPC server error codes (Unavailable, Internal, DeadlineExceeded...) count
+// as failures. Place it after UnaryClientTracingInterceptor so the breaker
+// attributes land on the CLIENT span:
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithChainUnaryInterceptor(
+//	        otelx.UnaryClientTracingInterceptor(),
+//	        otelx.UnaryClientMetricsInterceptor(),
+//	        breaker.UnaryClientInterceptor(),
+//	    ),
+//	)
+func (b *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		done, err := b.Allow(ctx)
+		if err != nil {
+			return err
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(!isServerError(status.Code(err)))
+		return err
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of
+// UnaryClientInterceptor. Only failures to open a stream count.
+func (b *CircuitBreaker) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		done, err := b.Allow(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		done(!isServerError(status.Code(err)))
+		return cs, err
+	}
+}
+
+// breakers holds the breakers observed by the circuit_breaker_state gauge.
+var breakers struct {
+	once sync.Once
+	mu   sync.Mutex
+	all  []*CircuitBreaker
+}
+
+// trackBreaker adds b to the circuit_breaker_state gauge, registering the
+// gauge on first use.
+func trackBreaker(b *CircuitBreaker) {
+	breakers.once.Do(registerBreakerGauge)
+
+	breakers.mu.Lock()
+	defer breakers.mu.Unlock()
+	breakers.all = append(breakers.all, b)
+}
+
+// registerBreakerGauge creates the circuit_breaker_state gauge on the global
+// MeterProvider.
+func registerBreakerGauge() {
+	meter := otel.Meter(instrumentationName)
+	gauge, err := meter.Int64ObservableGauge(
+		instrumentName("circuit_breaker_state"),
+		instrumentDescription("circuit_breaker_state", "Circuit breaker state, 1 for the current state and 0 for the others"),
+		api.WithUnit("1"),
+	)
+	if err != nil {
+		logf("failed to create circuit breaker gauge: %v\n", err)
+		return
+	}
+
+	states := []CircuitState{CircuitClosed, CircuitOpen, CircuitHalfOpen}
+	_, err = meter.RegisterCallback(func(_ context.Context, o api.Observer) error {
+		breakers.mu.Lock()
+		defer breakers.mu.Unlock()
+
+		for _, b := range breakers.all {
+			current := b.State()
+			for _, s := range states {
+				var v int64
+				if s == current {
+					v = 1
+				}
+				o.ObserveInt64(gauge, v, api.WithAttributes(
+					attribute.String("name", b.name),
+					attribute.String("state", s.String()),
+				))
+			}
+		}
+		return nil
+	}, gauge)
+	if err != nil {
+		logf("failed to register circuit breaker gauge: %v\n", err)
+	}
+}
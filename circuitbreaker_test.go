@@ -0,0 +1,240 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	const openTimeout = 10 * time.Second
+
+	// Each step either makes a call reporting success (or rejected when the
+	// breaker must short-circuit it), or advances the clock.
+	type step struct {
+		advance   time.Duration
+		success   bool
+		rejected  bool
+		wantState CircuitState
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "successes keep the circuit closed",
+			steps: []step{
+				{success: true, wantState: CircuitClosed},
+				{success: true, wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "failures below the threshold keep it closed",
+			steps: []step{
+				{wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "a success resets the failure count",
+			steps: []step{
+				{wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+				{success: true, wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "consecutive failures open it",
+			steps: []step{
+				{wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+				{wantState: CircuitOpen},
+				{rejected: true, wantState: CircuitOpen},
+			},
+		},
+		{
+			name: "half-open after the timeout",
+			steps: []step{
+				{wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+				{wantState: CircuitOpen},
+				{advance: openTimeout - time.Second, wantState: CircuitOpen},
+				{advance: time.Second, wantState: CircuitHalfOpen},
+			},
+		},
+		{
+			name: "a successful probe closes it",
+			steps: []step{
+				{wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+				{wantState: CircuitOpen},
+				{advance: openTimeout, wantState: CircuitHalfOpen},
+				{success: true, wantState: CircuitClosed},
+				{success: true, wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "a failed probe reopens it",
+			steps: []step{
+				{wantState: CircuitClosed},
+				{wantState: CircuitClosed},
+				{wantState: CircuitOpen},
+				{advance: openTimeout, wantState: CircuitHalfOpen},
+				{wantState: CircuitOpen},
+				{rejected: true, wantState: CircuitOpen},
+				{advance: openTimeout, wantState: CircuitHalfOpen},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(time.Unix(0, 0))
+			useClock(t, clock)
+			b := NewCircuitBreaker("test", WithBreakerThreshold(3), WithBreakerOpenTimeout(openTimeout))
+
+			for i, s := range tt.steps {
+				if s.advance > 0 {
+					clock.Advance(s.advance)
+				} else {
+					done, err := b.Allow(context.Background())
+					if s.rejected {
+						if !errors.Is(err, ErrCircuitOpen) {
+							t.Fatalf("step %d: Allow() error = %v, want ErrCircuitOpen", i, err)
+						}
+					} else {
+						if err != nil {
+							t.Fatalf("step %d: Allow() error = %v", i, err)
+						}
+						done(s.success)
+					}
+				}
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d: State() = %v, want %v", i, got, s.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	useClock(t, clock)
+	b := NewCircuitBreaker("test", WithBreakerThreshold(1), WithBreakerOpenTimeout(time.Second))
+
+	done, err := b.Allow(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	done(false)
+	clock.Advance(time.Second)
+
+	probe, err := b.Allow(context.Background())
+	if err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if _, err := b.Allow(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second call during the probe: error = %v, want ErrCircuitOpen", err)
+	}
+	probe(true)
+	if _, err := b.Allow(context.Background()); err != nil {
+		t.Fatalf("call after a successful probe: %v", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresCallerCancellation(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	defer cancelExpired()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		success   bool
+		wantState CircuitState
+	}{
+		{name: "live context failures count", ctx: context.Background(), wantState: CircuitOpen},
+		{name: "canceled context failures are ignored", ctx: canceled, wantState: CircuitClosed},
+		{name: "expired deadline failures are ignored", ctx: expired, wantState: CircuitClosed},
+		{name: "canceled context successes count", ctx: canceled, success: true, wantState: CircuitClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewCircuitBreaker("test", WithBreakerThreshold(2))
+			for i := 0; i < 2; i++ {
+				done, err := b.Allow(tt.ctx)
+				if err != nil {
+					t.Fatalf("call %d: Allow() error = %v", i, err)
+				}
+				done(tt.success)
+			}
+			if got := b.State(); got != tt.wantState {
+				t.Errorf("State() = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerProbeTimeout(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	useClock(t, clock)
+	b := NewCircuitBreaker("test",
+		WithBreakerThreshold(1),
+		WithBreakerOpenTimeout(time.Second),
+		WithBreakerProbeTimeout(5*time.Second),
+	)
+
+	done, _ := b.Allow(context.Background())
+	done(false)
+	clock.Advance(time.Second)
+
+	staleCtx, cancel := context.WithCancel(context.Background())
+	stale, err := b.Allow(staleCtx)
+	if err != nil {
+		t.Fatalf("first probe rejected: %v", err)
+	}
+	clock.Advance(4 * time.Second)
+	if _, err := b.Allow(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("call before the probe timeout: error = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Advance(time.Second)
+	probe, err := b.Allow(context.Background())
+	if err != nil {
+		t.Fatalf("probe after the probe timeout rejected: %v", err)
+	}
+
+	// The stale probe ending, canceled by its caller, must not release the
+	// current one.
+	cancel()
+	stale(false)
+	if _, err := b.Allow(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("call during the current probe: error = %v, want ErrCircuitOpen", err)
+	}
+	probe(true)
+	if got := b.State(); got != CircuitClosed {
+		t.Errorf("State() = %v, want %v", got, CircuitClosed)
+	}
+}
+
+func TestCircuitStateString(t *testing.T) {
+	tests := []struct {
+		state CircuitState
+		want  string
+	}{
+		{CircuitClosed, "closed"},
+		{CircuitOpen, "open"},
+		{CircuitHalfOpen, "half_open"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", int(tt.state), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package otelx
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// WithTrustedProxies declares the proxies (IP addresses or CIDR ranges) whose
+// forwarding headers are trusted when resolving the client IP.
+//
+// By default no proxy is trusted and the client IP is the TCP peer address.
+// When the peer is a trusted proxy, the Forwarded, X-Forwarded-For and
+// X-Real-IP headers are consulted, walking the chain from the right and
+// skipping trusted hops, so clients cannot spoof their address by sending the
+// headers themselves.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "edge",
+//	    otelx.WithTrustedProxies("10.0.0.0/8", "192.168.1.10"),
+//	)
+func WithTrustedProxies(proxies ...string) Option {
+	return func(c *config) {
+		for _, p := range proxies {
+			prefix, err := parsePrefix(p)
+			if err != nil {
+				logf("invalid trusted proxy %q: %v\n", p, err)
+				continue
+			}
+			c.trustedProxies = append(c.trustedProxies, prefix)
+		}
+	}
+}
+
+// parsePrefix parses a CIDR range or a single IP address.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// isTrustedProxy reports whether addr belongs to a trusted proxy.
+func isTrustedProxy(addr netip.Addr) bool {
+	for _, p := range settings().trustedProxies {
+		if p.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP resolves the originating client IP of r.
+//
+// The TCP peer address is used unless it belongs to a proxy declared with
+// WithTrustedProxies, in which case the Forwarded, X-Forwarded-For and
+// X-Real-IP headers (in that order) are used to find the first untrusted hop.
+// It returns an empty string if no address can be determined.
+//
+// The HTTP instrumentation records the result as the client.address span
+// attribute.
+func ClientIP(r *http.Request) string {
+	peer := parseAddr(r.RemoteAddr)
+	if !peer.IsValid() {
+		return ""
+	}
+	if !isTrustedProxy(peer) {
+		return peer.String()
+	}
+
+	if hops := forwardedFor(r.Header.Values("Forwarded")); len(hops) > 0 {
+		if ip, ok := firstUntrusted(hops); ok {
+			return ip
+		}
+	}
+
+	if hops := splitList(r.Header.Values("X-Forwarded-For")); len(hops) > 0 {
+		if ip, ok := firstUntrusted(hops); ok {
+			return ip
+		}
+	}
+
+	if real := parseAddr(r.Header.Get("X-Real-IP")); real.IsValid() {
+		return real.String()
+	}
+
+	return peer.String()
+}
+
+// firstUntrusted walks hops from the right (closest proxy first) and returns
+// the first address that is not a trusted proxy. If every hop is trusted, the
+// leftmost valid one is returned.
+func firstUntrusted(hops []string) (string, bool) {
+	var leftmost string
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr := parseAddr(hops[i])
+		if !addr.IsValid() {
+			// A malformed hop means the chain cannot be trusted further.
+			break
+		}
+		leftmost = addr.String()
+		if !isTrustedProxy(addr) {
+			return leftmost, true
+		}
+	}
+	return leftmost, leftmost != ""
+}
+
+// forwardedFor extracts the for= parameters from RFC 7239 Forwarded headers.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, elem := range splitList(values) {
+		for _, pair := range strings.Split(elem, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				hops = append(hops, strings.Trim(value, `"`))
+			}
+		}
+	}
+	return hops
+}
+
+// splitList splits comma-separated header values into trimmed elements.
+func splitList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// parseAddr parses an IP address optionally followed by a port, including the
+// bracketed IPv6 forms used by RemoteAddr and the Forwarded header.
+func parseAddr(s string) netip.Addr {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Addr{}
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
@@ -0,0 +1,103 @@
+package otelx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// sizeTransport records the body sizes of outgoing requests and their
+// responses in http_client_request_size_bytes and
+// http_client_response_size_bytes, with method, status_code and the target
+// host as server.address.
+//
+// Sizes are the bytes actually sent and received: request bodies are counted
+// as the transport reads them, and response bodies when they are read to the
+// end or closed, so a response closed early only counts what was read.
+type sizeTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip counts the bodies of r and of its response.
+func (t sizeTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	m := instrumentsFor(ctx)
+	if m == nil || telemetryPaused.Load() {
+		return t.next.RoundTrip(r)
+	}
+
+	sent := &countingBody{}
+	if r.Body != nil && r.Body != http.NoBody {
+		sent.ReadCloser = r.Body
+		r = r.Clone(ctx)
+		r.Body = sent
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		recordClientSizes(ctx, m, r, 0, sent.n.Load(), -1)
+		return resp, err
+	}
+
+	// Upgraded connections (101 Switching Protocols) return a writable body
+	// that must keep its type.
+	if _, ok := resp.Body.(io.ReadWriteCloser); ok || resp.Body == nil || resp.Body == http.NoBody {
+		recordClientSizes(ctx, m, r, resp.StatusCode, sent.n.Load(), 0)
+		return resp, err
+	}
+
+	received := &countingBody{ReadCloser: resp.Body}
+	received.done = func() {
+		recordClientSizes(ctx, m, r, resp.StatusCode, sent.n.Load(), received.n.Load())
+	}
+	resp.Body = received
+	return resp, err
+}
+
+// recordClientSizes records the body sizes of an outgoing request. A
+// negative received size means no response was received.
+func recordClientSizes(ctx context.Context, m *Metrics, r *http.Request, status int, sent, received int64) {
+	attrs := requestAttrs.get(attrKey{
+		method:        r.Method,
+		code:          status,
+		serverAddress: r.URL.Hostname(),
+	})
+	m.ClientRequestSize.Record(ctx, sent, attrs)
+	if received >= 0 {
+		m.ClientResponseSize.Record(ctx, received, attrs)
+	}
+}
+
+// countingBody counts the bytes read from a body and calls done once, at EOF
+// or on Close, whichever comes first.
+type countingBody struct {
+	io.ReadCloser
+	n    atomic.Int64
+	once sync.Once
+	done func()
+}
+
+// Read reads from the body and counts the bytes read.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+// Close closes the body.
+func (b *countingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+// finish calls done the first time it is called.
+func (b *countingBody) finish() {
+	if b.done != nil {
+		b.once.Do(b.done)
+	}
+}
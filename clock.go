@@ -0,0 +1,118 @@
+package otelx
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock abstracts the time source used to measure request durations.
+//
+// The default implementation delegates to time.Now. Tests can provide their
+// own Clock through WithClock to make duration measurements deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the default Clock backed by time.Now.
+type systemClock struct{}
+
+// Now returns the current local time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// since returns the elapsed time in seconds between start and the current
+// time reported by the configured clock.
+func since(start time.Time) float64 {
+	return settings().clock.Now().Sub(start).Seconds()
+}
+
+// ManualClock is a Clock that only moves when told to. Tests use it to
+// fast-forward time deterministically:
+//
+//	clock := otelx.NewManualClock(time.Unix(0, 0))
+//	otelx.NewMeterProvider(ctx, "test", otelx.WithClock(clock))
+//
+//	handler := otelx.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    clock.Advance(250 * time.Millisecond) // recorded duration: 0.25s
+//	}))
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a ManualClock reporting start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// CoarseClock is a monotonic Clock refreshed by a background ticker. Reading
+// it is a single atomic load, which is cheaper than time.Now on extremely hot
+// endpoints at the cost of precision: durations are measured in multiples of
+// the resolution.
+type CoarseClock struct {
+	base    time.Time
+	elapsed atomic.Int64
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewCoarseClock returns a CoarseClock updated every resolution. Call Stop
+// when it is no longer used.
+//
+// Example:
+//
+//	clock := otelx.NewCoarseClock(time.Millisecond)
+//	defer clock.Stop()
+//	otelx.NewMeterProvider(ctx, "edge", otelx.WithClock(clock))
+func NewCoarseClock(resolution time.Duration) *CoarseClock {
+	c := &CoarseClock{base: time.Now(), stop: make(chan struct{})}
+
+	go func() {
+		ticker := time.NewTicker(resolution)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.elapsed.Store(int64(time.Since(c.base)))
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+// Now returns the time as of the last tick. It keeps the monotonic clock
+// reading of the base time, so durations are immune to wall clock changes.
+func (c *CoarseClock) Now() time.Time {
+	return c.base.Add(time.Duration(c.elapsed.Load()))
+}
+
+// Stop halts the background ticker. The clock stops advancing.
+func (c *CoarseClock) Stop() {
+	c.once.Do(func() { close(c.stop) })
+}
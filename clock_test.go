@@ -0,0 +1,75 @@
+package otelx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name string
+		move func(c *ManualClock)
+		want time.Time
+	}{
+		{
+			name: "unchanged",
+			move: func(*ManualClock) {},
+			want: start,
+		},
+		{
+			name: "advance",
+			move: func(c *ManualClock) { c.Advance(250 * time.Millisecond) },
+			want: start.Add(250 * time.Millisecond),
+		},
+		{
+			name: "advance twice",
+			move: func(c *ManualClock) {
+				c.Advance(time.Second)
+				c.Advance(2 * time.Second)
+			},
+			want: start.Add(3 * time.Second),
+		},
+		{
+			name: "set",
+			move: func(c *ManualClock) { c.Set(time.Unix(0, 0)) },
+			want: time.Unix(0, 0),
+		},
+		{
+			name: "set then advance",
+			move: func(c *ManualClock) {
+				c.Set(time.Unix(10, 0))
+				c.Advance(time.Minute)
+			},
+			want: time.Unix(70, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewManualClock(start)
+			tt.move(c)
+			if got := c.Now(); !got.Equal(tt.want) {
+				t.Errorf("Now() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSinceUsesConfiguredClock(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	useClock(t, clock)
+
+	start := clock.Now()
+	clock.Advance(1500 * time.Millisecond)
+	if got := since(start); got != 1.5 {
+		t.Errorf("since() = %v, want 1.5", got)
+	}
+}
+
+// useClock makes c the configured clock for the duration of the test.
+func useClock(t *testing.T, c Clock) {
+	t.Helper()
+	useSettings(t, func(cfg *config) { cfg.clock = c })
+}
@@ -0,0 +1,278 @@
+package otelx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the structured form of otelx's settings, loaded from a YAML or
+// JSON file with LoadConfig.
+//
+// Fields backed by environment variables (enabled, collector endpoint,
+// environment, version, sampling ratio) are exported to the process
+// environment by Setenv, so Reload and the rest of otelx see the same values;
+// the other fields, ignored paths included, are turned into Options by
+// Options.
+type Config struct {
+	Service           string   `json:"service" yaml:"service"`
+	Enabled           *bool    `json:"enabled" yaml:"enabled"`
+	CollectorEndpoint string   `json:"collector_endpoint" yaml:"collector_endpoint"`
+	Environment       string   `json:"environment" yaml:"environment"`
+	Version           string   `json:"version" yaml:"version"`
+	SamplerRatio      *float64 `json:"sampler_ratio" yaml:"sampler_ratio"`
+	IgnorePaths       []string `json:"ignore_paths" yaml:"ignore_paths"`
+
+	// SLOs and SlowThresholds map routes to durations such as "300ms".
+	SLOs           map[string]string `json:"slos" yaml:"slos"`
+	SlowThresholds map[string]string `json:"slow_thresholds" yaml:"slow_thresholds"`
+
+	TrustedProxies      []string `json:"trusted_proxies" yaml:"trusted_proxies"`
+	CapturedHeaders     []string `json:"captured_headers" yaml:"captured_headers"`
+	CapturedMetadata    []string `json:"captured_metadata" yaml:"captured_metadata"`
+	CorrelationKeys     []string `json:"correlation_keys" yaml:"correlation_keys"`
+	RequestIDHeader     string   `json:"request_id_header" yaml:"request_id_header"`
+	ClientVersionHeader string   `json:"client_version_header" yaml:"client_version_header"`
+
+	// UserAgent is "", "raw" or "normalized" (see WithUserAgent).
+	UserAgent string `json:"user_agent" yaml:"user_agent"`
+
+	// MetricAttributes is the metric attribute allowlist and
+	// MetricCardinalityLimit the distinct values allowed per attribute.
+	MetricAttributes       []string `json:"metric_attributes" yaml:"metric_attributes"`
+	MetricCardinalityLimit int      `json:"metric_cardinality_limit" yaml:"metric_cardinality_limit"`
+
+	Stdout      bool `json:"stdout" yaml:"stdout"`
+	SpanMetrics bool `json:"span_metrics" yaml:"span_metrics"`
+	DebugSpans  int  `json:"debug_spans" yaml:"debug_spans"`
+}
+
+// LoadConfig reads a Config from a YAML (.yaml, .yml) or JSON (.json) file.
+//
+// ${VAR} and ${VAR:-default} references are replaced with environment
+// variables before parsing; a "$" not followed by "{" is kept as is, so
+// regular expressions such as "^/health$" need no escaping. Unknown fields and
+// invalid values are rejected with an error naming the offending field.
+//
+// Example:
+//
+//	cfg, err := otelx.LoadConfig("otelx.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	cfg.Setenv()
+//
+//	tp, cleanup := otelx.NewTraceProvider(ctx, cfg.Service, cfg.Options()...)
+//	defer cleanup()
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = envRefPattern.ReplaceAllFunc(data, func(ref []byte) []byte {
+		return []byte(expandEnv(string(ref[2 : len(ref)-1])))
+	})
+
+	var cfg Config
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format %q", path, filepath.Ext(path))
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// envRefPattern matches the ${VAR} and ${VAR:-default} references expanded by
+// LoadConfig.
+var envRefPattern = regexp.MustCompile(`\$\{[^{}]*\}`)
+
+// expandEnv resolves the VAR or VAR:-default inside a ${...} reference for
+// LoadConfig.
+func expandEnv(ref string) string {
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if v, ok := os.LookupEnv(name); ok && (v != "" || !hasDefault) {
+		return v
+	}
+	return def
+}
+
+// validate checks every field and parses the durations.
+func (c *Config) validate() error {
+	if c.Service == "" {
+		return errors.New("service: required")
+	}
+	if c.Enabled != nil && *c.Enabled && c.CollectorEndpoint == "" && os.Getenv("OTEL_COLLECTOR_ENDPOINT") == "" {
+		return errors.New("collector_endpoint: required when enabled is true")
+	}
+	if c.SamplerRatio != nil && (*c.SamplerRatio < 0 || *c.SamplerRatio > 1) {
+		return fmt.Errorf("sampler_ratio: must be between 0 and 1, got %v", *c.SamplerRatio)
+	}
+	for i, p := range c.IgnorePaths {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("ignore_paths[%d]: %w", i, err)
+		}
+	}
+
+	if _, err := parseThresholds("slos", c.SLOs); err != nil {
+		return err
+	}
+	if _, err := parseThresholds("slow_thresholds", c.SlowThresholds); err != nil {
+		return err
+	}
+
+	for i, p := range c.TrustedProxies {
+		if _, err := parsePrefix(p); err != nil {
+			return fmt.Errorf("trusted_proxies[%d]: %w", i, err)
+		}
+	}
+	switch c.UserAgent {
+	case "", "raw", "normalized":
+	default:
+		return fmt.Errorf(`user_agent: must be "raw" or "normalized", got %q`, c.UserAgent)
+	}
+	if c.MetricCardinalityLimit < 0 {
+		return fmt.Errorf("metric_cardinality_limit: must not be negative, got %d", c.MetricCardinalityLimit)
+	}
+	if c.DebugSpans < 0 {
+		return fmt.Errorf("debug_spans: must not be negative, got %d", c.DebugSpans)
+	}
+	return nil
+}
+
+// parseThresholds parses the durations of m, naming field in errors.
+func parseThresholds(field string, m map[string]string) (routeThresholds, error) {
+	var t routeThresholds
+	for route, v := range m {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%q]: %w", field, route, err)
+		}
+		t.set(route, d)
+	}
+	return t, nil
+}
+
+// Setenv exports the environment-backed fields that are set to the process
+// environment (OTEL_ENABLE, OTEL_COLLECTOR_ENDPOINT, ENV, SERVICE_VERSION,
+// OTEL_TRACES_SAMPLER_ARG). Call it before NewTraceProvider and
+// NewMeterProvider.
+func (c *Config) Setenv() error {
+	vars := map[string]string{
+		"OTEL_COLLECTOR_ENDPOINT": c.CollectorEndpoint,
+		"ENV":                     c.Environment,
+		"SERVICE_VERSION":         c.Version,
+	}
+	if c.Enabled != nil {
+		vars["OTEL_ENABLE"] = strconv.FormatBool(*c.Enabled)
+	}
+	if c.SamplerRatio != nil {
+		vars["OTEL_TRACES_SAMPLER_ARG"] = strconv.FormatFloat(*c.SamplerRatio, 'g', -1, 64)
+	}
+
+	for k, v := range vars {
+		if v == "" {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return fmt.Errorf("setting %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// Options returns the Options corresponding to the fields of c that are not
+// backed by environment variables. Invalid durations are logged and skipped;
+// LoadConfig rejects them upfront.
+func (c *Config) Options() []Option {
+	var opts []Option
+
+	slos, err := parseThresholds("slos", c.SLOs)
+	if err != nil {
+		logf("invalid config: %v\n", err)
+	}
+	for route, d := range slos {
+		opts = append(opts, WithSLO(route, d))
+	}
+
+	slow, err := parseThresholds("slow_thresholds", c.SlowThresholds)
+	if err != nil {
+		logf("invalid config: %v\n", err)
+	}
+	for route, d := range slow {
+		opts = append(opts, WithSlowThreshold(route, d))
+	}
+
+	if len(c.IgnorePaths) > 0 {
+		opts = append(opts, withIgnoredPaths(c.IgnorePaths))
+	}
+	if len(c.TrustedProxies) > 0 {
+		opts = append(opts, WithTrustedProxies(c.TrustedProxies...))
+	}
+	if len(c.CapturedHeaders) > 0 {
+		opts = append(opts, WithCapturedHeaders(c.CapturedHeaders...))
+	}
+	if len(c.CapturedMetadata) > 0 {
+		opts = append(opts, WithCapturedMetadata(c.CapturedMetadata...))
+	}
+	if c.CorrelationKeys != nil {
+		opts = append(opts, WithCorrelationKeys(c.CorrelationKeys...))
+	}
+	if c.RequestIDHeader != "" {
+		opts = append(opts, WithRequestIDHeader(c.RequestIDHeader))
+	}
+	if c.ClientVersionHeader != "" {
+		opts = append(opts, WithClientVersionHeader(c.ClientVersionHeader))
+	}
+	if c.UserAgent != "" {
+		opts = append(opts, WithUserAgent(c.UserAgent == "normalized"))
+	}
+	if c.MetricAttributes != nil {
+		opts = append(opts, WithMetricAttributes(c.MetricAttributes...))
+	}
+	if c.MetricCardinalityLimit > 0 {
+		opts = append(opts, WithMetricCardinalityLimit(c.MetricCardinalityLimit))
+	}
+	if c.Stdout {
+		opts = append(opts, WithStdoutExporters())
+	}
+	if c.SpanMetrics {
+		opts = append(opts, WithSpanMetrics())
+	}
+	if c.DebugSpans > 0 {
+		opts = append(opts, WithDebugSpans(c.DebugSpans))
+	}
+	return opts
+}
+
+// withIgnoredPaths drops spans whose name or path matches one of patterns, in
+// addition to the dropped span names already configured.
+func withIgnoredPaths(patterns []string) Option {
+	return func(c *config) {
+		c.spanDropRules = append(c.spanDropRules, compileDropRules("", patterns)...)
+	}
+}
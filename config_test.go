@@ -0,0 +1,118 @@
+package otelx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("OTELX_TEST_SERVICE", "billing")
+	t.Setenv("OTELX_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		want    Config
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "otelx.yaml",
+			data: "service: billing\nversion: 1.2.3\nslos:\n  /checkout: 300ms\n",
+			want: Config{Service: "billing", Version: "1.2.3", SLOs: map[string]string{"/checkout": "300ms"}},
+		},
+		{
+			name: "json",
+			file: "otelx.json",
+			data: `{"service": "billing", "trusted_proxies": ["10.0.0.0/8"]}`,
+			want: Config{Service: "billing", TrustedProxies: []string{"10.0.0.0/8"}},
+		},
+		{
+			name: "env references",
+			file: "otelx.yaml",
+			data: "service: ${OTELX_TEST_SERVICE}\nenvironment: ${OTELX_TEST_UNSET:-staging}\nversion: ${OTELX_TEST_EMPTY:-dev}\n",
+			want: Config{Service: "billing", Environment: "staging", Version: "dev"},
+		},
+		{
+			name: "bare dollars kept",
+			file: "otelx.yaml",
+			data: "service: billing\nignore_paths:\n  - '^/health$'\n  - '^/v[0-9]{1,3}/ping$'\nrequest_id_header: X-$1\n",
+			want: Config{
+				Service:         "billing",
+				IgnorePaths:     []string{"^/health$", "^/v[0-9]{1,3}/ping$"},
+				RequestIDHeader: "X-$1",
+			},
+		},
+		{name: "missing service", file: "otelx.yaml", data: "version: 1\n", wantErr: "service: required"},
+		{name: "unknown field", file: "otelx.yaml", data: "service: billing\nsampler: always_on\n", wantErr: "field sampler not found"},
+		{name: "unknown json field", file: "otelx.json", data: `{"service": "billing", "extra": 1}`, wantErr: `unknown field "extra"`},
+		{name: "invalid ratio", file: "otelx.yaml", data: "service: billing\nsampler_ratio: 2\n", wantErr: "sampler_ratio"},
+		{name: "invalid pattern", file: "otelx.yaml", data: "service: billing\nignore_paths: ['(']\n", wantErr: "ignore_paths[0]"},
+		{name: "invalid duration", file: "otelx.yaml", data: "service: billing\nslow_thresholds:\n  /orders: fast\n", wantErr: `slow_thresholds["/orders"]`},
+		{name: "invalid proxy", file: "otelx.yaml", data: "service: billing\ntrusted_proxies: [nope]\n", wantErr: "trusted_proxies[0]"},
+		{name: "invalid user agent", file: "otelx.yaml", data: "service: billing\nuser_agent: full\n", wantErr: "user_agent"},
+		{name: "unsupported format", file: "otelx.toml", data: "service = 'billing'\n", wantErr: "unsupported config format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadConfig(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	t.Cleanup(func() { shared.Store(nil) })
+	t.Setenv("OTELX_IGNORE_PATHS", "")
+
+	c := Config{
+		Service:        "billing",
+		IgnorePaths:    []string{"^/v[0-9]{1,3}/ping$"},
+		SLOs:           map[string]string{"/checkout": "300ms"},
+		SlowThresholds: map[string]string{"/orders/*": "2s"},
+	}
+	if err := c.Setenv(); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("OTELX_IGNORE_PATHS"); got != "" {
+		t.Errorf("Setenv() exported OTELX_IGNORE_PATHS=%q", got)
+	}
+
+	cfg := configure(c.Options())
+
+	ignored := cfg.spanDropRules[len(cfg.spanDropRules)-1]
+	if !ignored.pattern.MatchString("/v12/ping") || ignored.pattern.MatchString("/v1234/ping") {
+		t.Errorf("ignore_paths rule %q does not keep its quantifier", ignored.pattern)
+	}
+	if got := len(cfg.spanDropRules); got != len(defaultDroppedSpanPatterns)+1 {
+		t.Errorf("%d drop rules, want the defaults and the ignored path", got)
+	}
+	if d, _ := cfg.slos.lookup("/checkout"); d != 300*time.Millisecond {
+		t.Errorf("SLO of /checkout = %v, want 300ms", d)
+	}
+	if d, _ := cfg.slowThresholds.lookup("/orders/42"); d != 2*time.Second {
+		t.Errorf("slow threshold of /orders/42 = %v, want 2s", d)
+	}
+}
@@ -0,0 +1,122 @@
+package otelx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// configPollInterval is how often the file named by OTELX_CONFIG_FILE is
+// checked for changes.
+const configPollInterval = 5 * time.Second
+
+// dynamicFile is the on-disk format of the file named by OTELX_CONFIG_FILE.
+// Omitted fields keep the value from the environment and Options.
+//
+//	{
+//	  "enabled": true,
+//	  "sampler": "parentbased_traceidratio",
+//	  "sampler_ratio": 0.25,
+//	  "ignore_paths": ["^GET /internal/"],
+//	  "slow_thresholds": {"*": "1s", "/search": "3s"}
+//	}
+type dynamicFile struct {
+	Enabled        *bool             `json:"enabled"`
+	Sampler        *string           `json:"sampler"`
+	SamplerRatio   *float64          `json:"sampler_ratio"`
+	IgnorePaths    []string          `json:"ignore_paths"`
+	SlowThresholds map[string]string `json:"slow_thresholds"`
+}
+
+// dynamicFromFile reads the dynamic settings stored in path and merges them
+// over d.
+func dynamicFromFile(path string, d dynamicConfig) (dynamicConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return d, err
+	}
+
+	var f dynamicFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return d, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if f.Enabled != nil {
+		d.enabled = f.Enabled
+	}
+	if f.Sampler != nil {
+		name, err := samplerName(*f.Sampler)
+		if err != nil {
+			return d, fmt.Errorf("%s: sampler: %w", path, err)
+		}
+		d.samplerName = name
+	}
+	if f.SamplerRatio != nil {
+		if *f.SamplerRatio < 0 || *f.SamplerRatio > 1 {
+			return d, fmt.Errorf("%s: sampler_ratio: must be between 0 and 1, got %v", path, *f.SamplerRatio)
+		}
+		d.samplerRatio = f.SamplerRatio
+	}
+	if f.IgnorePaths != nil {
+		d.ignorePaths = f.IgnorePaths
+	}
+	if f.SlowThresholds != nil {
+		d.slowThresholds = make(routeThresholds, len(f.SlowThresholds))
+		for route, v := range f.SlowThresholds {
+			threshold, err := time.ParseDuration(v)
+			if err != nil {
+				return d, fmt.Errorf("%s: slow_thresholds[%q]: %w", path, route, err)
+			}
+			d.slowThresholds[route] = threshold
+		}
+	}
+
+	return d, nil
+}
+
+// watchConfigFile polls path and calls Reload whenever it changes, until the
+// returned function is called.
+func watchConfigFile(path string) func() {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+
+		last := fileVersion(path)
+		for {
+			select {
+			case <-ticker.C:
+				current := fileVersion(path)
+				if current == last {
+					continue
+				}
+				last = current
+				if err := Reload(); err != nil {
+					logf("error applying %s: %v\n", path, err)
+				} else {
+					logf("applied telemetry configuration from %s\n", path)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// fileVersion identifies the current contents of path by modification time
+// and size. It returns an empty string when the file cannot be read.
+func fileVersion(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+}
@@ -0,0 +1,79 @@
+package otelx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// connStates holds the last state of each open server connection, so its
+// previous state can be decremented on transition.
+var connStates sync.Map // net.Conn -> http.ConnState
+
+// connStateAttrs caches the attribute option of each connection state.
+var connStateAttrs sync.Map // http.ConnState -> api.MeasurementOption
+
+// ConnStateHook returns an http.Server ConnState callback recording
+// connection-level metrics, then calling next if it is not nil:
+//
+//   - http_server_open_connections{state}: open connections by state (new,
+//     active, idle), showing idle keep-alive connections piling up
+//   - http_server_connection_events_total{state}: transitions into each
+//     state, including hijacked and closed, showing connection churn
+//     (new vs. active rates reveal how well keep-alive is reused)
+//
+// Serve installs it automatically.
+//
+// Example:
+//
+//	srv := &http.Server{Addr: ":8080", Handler: handler}
+//	srv.ConnState = otelx.ConnStateHook(srv.ConnState)
+func ConnStateHook(next func(net.Conn, http.ConnState)) func(net.Conn, http.ConnState) {
+	return func(c net.Conn, state http.ConnState) {
+		recordConnState(c, state)
+		if next != nil {
+			next(c, state)
+		}
+	}
+}
+
+// recordConnState records the transition of c into state.
+func recordConnState(c net.Conn, state http.ConnState) {
+	ctx := context.Background()
+	m := metrics
+	closed := state == http.StateClosed || state == http.StateHijacked
+
+	var prev any
+	var hadPrev bool
+	if closed {
+		prev, hadPrev = connStates.LoadAndDelete(c)
+	} else {
+		prev, hadPrev = connStates.Swap(c, state)
+	}
+
+	if !metricsEnabled.Load() || telemetryPaused.Load() {
+		return
+	}
+
+	if hadPrev {
+		m.OpenConnections.Add(ctx, -1, connStateAttr(prev.(http.ConnState)))
+	}
+	if !closed {
+		m.OpenConnections.Add(ctx, 1, connStateAttr(state))
+	}
+	m.ConnectionEvents.Add(ctx, 1, connStateAttr(state))
+}
+
+// connStateAttr returns the state attribute option for state.
+func connStateAttr(state http.ConnState) api.MeasurementOption {
+	if opt, ok := connStateAttrs.Load(state); ok {
+		return opt.(api.MeasurementOption)
+	}
+	opt := api.WithAttributeSet(attribute.NewSet(attribute.String("state", state.String())))
+	connStateAttrs.Store(state, opt)
+	return opt
+}
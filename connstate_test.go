@@ -0,0 +1,139 @@
+package otelx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// useTestMetrics replaces the global instruments with instruments recording
+// into the returned reader for the duration of the test.
+func useTestMetrics(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	m, err := newMetrics(mp.Meter("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved, savedEnabled := metrics, metricsEnabled.Load()
+	metrics = m
+	metricsEnabled.Store(true)
+	t.Cleanup(func() {
+		metrics = saved
+		metricsEnabled.Store(savedEnabled)
+	})
+	return reader
+}
+
+// collectStates returns the value of the int64 sum name in reader by
+// connection state.
+func collectStates(t *testing.T, reader sdkmetric.Reader, name string) map[string]int64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != instrumentName(name) {
+				continue
+			}
+			for _, p := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				v, _ := p.Attributes.Value("state")
+				values[v.AsString()] = p.Value
+			}
+		}
+	}
+	return values
+}
+
+func TestConnStateHook(t *testing.T) {
+	reader := useTestMetrics(t)
+
+	var chained []http.ConnState
+	hook := ConnStateHook(func(_ net.Conn, state http.ConnState) {
+		chained = append(chained, state)
+	})
+
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	steps := []struct {
+		name   string
+		conn   net.Conn
+		state  http.ConnState
+		open   map[string]int64
+		events map[string]int64
+	}{
+		{
+			name: "first connection", conn: a, state: http.StateNew,
+			open:   map[string]int64{"new": 1},
+			events: map[string]int64{"new": 1},
+		},
+		{
+			name: "first request", conn: a, state: http.StateActive,
+			open:   map[string]int64{"new": 0, "active": 1},
+			events: map[string]int64{"new": 1, "active": 1},
+		},
+		{
+			name: "keep-alive", conn: a, state: http.StateIdle,
+			open:   map[string]int64{"new": 0, "active": 0, "idle": 1},
+			events: map[string]int64{"new": 1, "active": 1, "idle": 1},
+		},
+		{
+			name: "second connection", conn: b, state: http.StateNew,
+			open:   map[string]int64{"new": 1, "active": 0, "idle": 1},
+			events: map[string]int64{"new": 2, "active": 1, "idle": 1},
+		},
+		{
+			name: "hijacked", conn: b, state: http.StateHijacked,
+			open:   map[string]int64{"new": 0, "active": 0, "idle": 1},
+			events: map[string]int64{"new": 2, "active": 1, "idle": 1, "hijacked": 1},
+		},
+		{
+			name: "closed", conn: a, state: http.StateClosed,
+			open:   map[string]int64{"new": 0, "active": 0, "idle": 0},
+			events: map[string]int64{"new": 2, "active": 1, "idle": 1, "hijacked": 1, "closed": 1},
+		},
+	}
+
+	for i, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			hook(step.conn, step.state)
+			if len(chained) != i+1 || chained[i] != step.state {
+				t.Errorf("next saw %v, want it called with %v", chained, step.state)
+			}
+			assertStates(t, "open connections", collectStates(t, reader, "http_server_open_connections"), step.open)
+			assertStates(t, "connection events", collectStates(t, reader, "http_server_connection_events_total"), step.events)
+		})
+	}
+
+	if _, ok := connStates.Load(a); ok {
+		t.Error("closed connection is still tracked")
+	}
+}
+
+// assertStates compares the per-state values of a metric.
+func assertStates(t *testing.T, what string, got, want map[string]int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+		return
+	}
+	for state, n := range want {
+		if got[state] != n {
+			t.Errorf("%s = %v, want %v", what, got, want)
+			return
+		}
+	}
+}
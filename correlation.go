@@ -0,0 +1,114 @@
+package otelx
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultCorrelationKeys are the legacy correlation headers carried alongside
+// W3C baggage unless WithCorrelationKeys is used.
+var DefaultCorrelationKeys = []string{"x-correlation-id"}
+
+// WithCorrelationKeys sets the correlation headers (and gRPC metadata keys)
+// that are carried as plain headers in addition to W3C baggage, replacing
+// DefaultCorrelationKeys.
+//
+// Each key is mapped to the baggage member of the same (lowercased) name: an
+// incoming "x-correlation-id" header or metadata entry becomes the
+// x-correlation-id baggage member, and that member is written back as a plain
+// header on outgoing HTTP requests and gRPC calls. This keeps correlation
+// intact between services that only understand the legacy headers and those
+// using baggage.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "billing",
+//	    otelx.WithCorrelationKeys("x-correlation-id", "x-tenant-id"),
+//	)
+func WithCorrelationKeys(keys ...string) Option {
+	return func(c *config) {
+		c.correlationKeys = c.correlationKeys[:0:0]
+		for _, k := range keys {
+			c.correlationKeys = append(c.correlationKeys, strings.ToLower(k))
+		}
+	}
+}
+
+// correlationPropagator maps the configured correlation headers to baggage
+// members. It is registered after propagation.Baggage in the global propagator
+// so plain headers are merged into the extracted baggage.
+type correlationPropagator struct{}
+
+// Inject writes each correlation baggage member in ctx as a plain header.
+func (correlationPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	bag := baggage.FromContext(ctx)
+	for _, key := range settings().correlationKeys {
+		if v := bag.Member(key).Value(); v != "" {
+			carrier.Set(key, v)
+		}
+	}
+}
+
+// Extract adds the correlation headers found in carrier to the baggage in ctx.
+// Members already present in the W3C baggage take precedence.
+func (correlationPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	bag := baggage.FromContext(ctx)
+	changed := false
+	for _, key := range settings().correlationKeys {
+		v := carrier.Get(key)
+		if v == "" || bag.Member(key).Value() != "" {
+			continue
+		}
+		member, err := baggage.NewMemberRaw(key, v)
+		if err != nil {
+			logf("invalid correlation header %q: %v\n", key, err)
+			continue
+		}
+		if bag, err = bag.SetMember(member); err != nil {
+			logf("error adding correlation header %q to baggage: %v\n", key, err)
+			continue
+		}
+		changed = true
+	}
+	if !changed {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// Fields returns the correlation headers handled by the propagator.
+func (correlationPropagator) Fields() []string {
+	return settings().correlationKeys
+}
+
+// IncomingCorrelationContext copies the correlation keys found in the incoming
+// gRPC metadata of ctx into its baggage.
+//
+// The otelx tracing interceptors do this automatically through the global
+// propagator; use it in services that install their own interceptors.
+func IncomingCorrelationContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return correlationPropagator{}.Extract(ctx, metadataCarrier(md))
+}
+
+// OutgoingCorrelationContext returns a copy of ctx whose outgoing gRPC
+// metadata carries the correlation baggage members as plain metadata entries.
+//
+// Example:
+//
+//	ctx = otelx.OutgoingCorrelationContext(ctx)
+//	resp, err := client.GetInvoice(ctx, req)
+func OutgoingCorrelationContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	correlationPropagator{}.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
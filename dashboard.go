@@ -0,0 +1,227 @@
+package otelx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// GenerateDashboard returns a Grafana dashboard JSON model for service built
+// from the instruments otelx emits, as they appear in Prometheus-compatible
+// backends (Mimir, Prometheus with the OTLP receiver):
+//
+//   - RED panels: request rate, error ratio and latency percentiles for HTTP
+//     and gRPC serving, and for outgoing gRPC calls
+//   - a request latency heatmap
+//   - SLO breaches, slow requests and recovered panics
+//   - otelx's own export health
+//
+// Series are selected with job="$service", the label Prometheus derives from
+// service.name; the service variable defaults to service. Metric names follow
+// WithMetricPrefix and WithInstrumentName. The dashboard can be imported as is
+// or provisioned from a file.
+//
+// Example:
+//
+//	data, err := otelx.GenerateDashboard("auth-service")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	os.WriteFile("auth-service.json", data, 0o644)
+func GenerateDashboard(service string) ([]byte, error) {
+	if service == "" {
+		return nil, errors.New("service name is required")
+	}
+
+	var (
+		requests       = series("http_requests_total", "{request}", true)
+		duration       = durationSeries("http_request_duration_seconds") + "_bucket"
+		httpErrors     = series("http_errors_total", "{request}", true)
+		rpcErrors      = series("rpc_errors_total", "{call}", true)
+		sloBreaches    = series("slo_breaches_total", "{request}", true)
+		slowRequests   = series("slow_requests_total", "{request}", true)
+		panics         = series("panics_total", "{panic}", true)
+		activeStreams  = series("rpc_server_active_streams", "{stream}", false)
+		openConns      = series("http_server_open_connections", "{connection}", false)
+		clientRequests = series("rpc_client_requests_total", "{call}", true)
+		clientDuration = durationSeries("rpc_client_duration_seconds") + "_bucket"
+		exports        = series("otelx_exports_total", "{export}", true)
+		droppedSpans   = series("otelx_spans_dropped_total", "{span}", true)
+	)
+	rate := func(fn, series string) string {
+		return fmt.Sprintf(`%s(%s{job="$service"}[$__rate_interval])`, fn, series)
+	}
+
+	var b dashboardBuilder
+	b.row("Requests")
+	b.timeseries("Request rate", "reqps",
+		"sum by (method, path) ("+rate("rate", requests)+")",
+		"{{method}} {{path}}")
+	b.timeseries("Error ratio", "percentunit",
+		"((sum("+rate("rate", httpErrors)+") or vector(0)) + (sum("+rate("rate", rpcErrors)+") or vector(0)))"+
+			" / sum("+rate("rate", requests)+")",
+		"errors")
+	b.timeseries("Latency", durationUnit(), quantiles(rate("rate", duration))...)
+	b.heatmap("Latency distribution", "sum by (le) ("+rate("increase", duration)+")")
+	b.timeseries("Active streams", "short",
+		`sum by (method) (`+activeStreams+`{job="$service"})`,
+		"{{method}}")
+	b.timeseries("Open connections", "short",
+		`sum by (state) (`+openConns+`{job="$service"})`,
+		"{{state}}")
+
+	b.row("Objectives")
+	b.timeseries("SLO breaches", "short",
+		"sum by (method, path) ("+rate("increase", sloBreaches)+")",
+		"{{method}} {{path}}")
+	b.timeseries("Slow requests", "short",
+		"sum by (method, path) ("+rate("increase", slowRequests)+")",
+		"{{method}} {{path}}")
+	b.timeseries("Recovered panics", "short",
+		"sum by (method, path) ("+rate("increase", panics)+")",
+		"{{method}} {{path}}")
+
+	b.row("Dependencies")
+	b.timeseries("Outgoing gRPC rate", "reqps",
+		"sum by (rpc_service, status_code) ("+rate("rate", clientRequests)+")",
+		"{{rpc_service}} {{status_code}}")
+	b.timeseries("Outgoing gRPC latency", durationUnit(), quantiles(rate("rate", clientDuration))...)
+
+	b.row("Telemetry pipeline")
+	b.timeseries("Exports", "ops",
+		"sum by (signal, result) ("+rate("rate", exports)+")",
+		"{{signal}} {{result}}")
+	b.timeseries("Dropped spans", "short",
+		"sum("+rate("increase", droppedSpans)+")",
+		"dropped")
+
+	dashboard := map[string]any{
+		"title":         fmt.Sprintf("%s (otelx)", service),
+		"uid":           "otelx-" + service,
+		"tags":          []string{"otelx"},
+		"schemaVersion": 39,
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"refresh":       "30s",
+		"panels":        b.panels,
+		"templating": map[string]any{
+			"list": []map[string]any{
+				{
+					"name":  "datasource",
+					"type":  "datasource",
+					"query": "prometheus",
+				},
+				{
+					"name":    "service",
+					"type":    "textbox",
+					"query":   service,
+					"current": map[string]string{"text": service, "value": service},
+				},
+			},
+		},
+	}
+	return json.MarshalIndent(dashboard, "", "  ")
+}
+
+// dashboardBuilder lays panels out on Grafana's 24 column grid, two per row.
+type dashboardBuilder struct {
+	panels []map[string]any
+	x, y   int
+}
+
+// datasource references the dashboard's datasource variable.
+var datasource = map[string]string{"type": "prometheus", "uid": "${datasource}"}
+
+// row starts a new section titled title.
+func (b *dashboardBuilder) row(title string) {
+	if b.x > 0 {
+		b.x, b.y = 0, b.y+8
+	}
+	b.add(map[string]any{"type": "row", "title": title, "collapsed": false}, 24, 1)
+}
+
+// timeseries adds a time series panel; exprAndLegends alternates PromQL
+// expressions and their legend formats.
+func (b *dashboardBuilder) timeseries(title, unit string, exprAndLegends ...string) {
+	var targets []map[string]any
+	for i := 0; i+1 < len(exprAndLegends); i += 2 {
+		targets = append(targets, map[string]any{
+			"datasource":   datasource,
+			"expr":         exprAndLegends[i],
+			"legendFormat": exprAndLegends[i+1],
+			"refId":        string(rune('A' + i/2)),
+		})
+	}
+	b.add(map[string]any{
+		"type":        "timeseries",
+		"title":       title,
+		"datasource":  datasource,
+		"targets":     targets,
+		"fieldConfig": map[string]any{"defaults": map[string]string{"unit": unit}},
+	}, 12, 8)
+}
+
+// heatmap adds a heatmap panel of a histogram's buckets.
+func (b *dashboardBuilder) heatmap(title, expr string) {
+	b.add(map[string]any{
+		"type":       "heatmap",
+		"title":      title,
+		"datasource": datasource,
+		"targets": []map[string]any{{
+			"datasource":   datasource,
+			"expr":         expr,
+			"format":       "heatmap",
+			"legendFormat": "{{le}}",
+			"refId":        "A",
+		}},
+		"options": map[string]any{
+			"calculate": false,
+			"yAxis":     map[string]string{"unit": durationUnit()},
+		},
+	}, 12, 8)
+}
+
+// add places panel at the next free position.
+func (b *dashboardBuilder) add(panel map[string]any, w, h int) {
+	if b.x+w > 24 {
+		b.x, b.y = 0, b.y+8
+	}
+	panel["id"] = len(b.panels) + 1
+	panel["gridPos"] = map[string]int{"x": b.x, "y": b.y, "w": w, "h": h}
+	b.panels = append(b.panels, panel)
+
+	b.x += w
+	if b.x >= 24 {
+		b.x, b.y = 0, b.y+h
+	}
+}
+
+// quantiles returns p50, p95 and p99 expressions and legends for the bucket
+// rate expression buckets.
+func quantiles(buckets string) []string {
+	var out []string
+	for _, p := range []int{50, 95, 99} {
+		out = append(out,
+			fmt.Sprintf("histogram_quantile(%g, sum by (le) (%s))", float64(p)/100, buckets),
+			fmt.Sprintf("p%d", p),
+		)
+	}
+	return out
+}
+
+// series returns the Prometheus series name of the otelx instrument name with
+// unit, following WithMetricPrefix and WithInstrumentName.
+func series(name, unit string, monotonic bool) string {
+	m := metricdata.Metrics{Name: instrumentName(name), Unit: unit}
+	if monotonic {
+		m.Data = metricdata.Sum[int64]{IsMonotonic: true}
+	}
+	return prometheusMetricName(m)
+}
+
+// durationSeries is series for a request duration histogram, following
+// WithMillisecondDurations.
+func durationSeries(name string) string {
+	return prometheusMetricName(metricdata.Metrics{Name: durationInstrumentName(name), Unit: durationUnit()})
+}
@@ -0,0 +1,236 @@
+package otelx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pipelineInfo describes the running telemetry pipeline. It is populated by
+// NewTraceProvider and NewMeterProvider and reported by DebugHandler.
+var pipelineInfo struct {
+	mu       sync.RWMutex
+	service  string
+	resource *resource.Resource
+	sampler  sdktrace.Sampler
+	endpoint string
+	tracing  bool
+	metrics  bool
+}
+
+// recordPipeline updates pipelineInfo with the given provider details.
+func recordPipeline(service string, res *resource.Resource, sampler sdktrace.Sampler) {
+	pipelineInfo.mu.Lock()
+	defer pipelineInfo.mu.Unlock()
+
+	pipelineInfo.service = service
+	pipelineInfo.endpoint = os.Getenv("OTEL_COLLECTOR_ENDPOINT")
+	if res != nil {
+		pipelineInfo.resource = res
+	}
+	if sampler != nil {
+		pipelineInfo.sampler = sampler
+		pipelineInfo.tracing = true
+	} else {
+		pipelineInfo.metrics = true
+	}
+}
+
+// WithDebugSpans records in-flight spans and the last n errored spans so they
+// can be inspected through DebugHandler.
+//
+// Tracking in-flight spans adds a small cost to every span, so it is disabled
+// by default.
+func WithDebugSpans(n int) Option {
+	return func(c *config) {
+		c.debugSpans = n
+	}
+}
+
+// debugSpan is the summary of a span shown by DebugHandler.
+type debugSpan struct {
+	Name      string        `json:"name"`
+	TraceID   string        `json:"trace_id"`
+	SpanID    string        `json:"span_id"`
+	Kind      string        `json:"kind"`
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration"`
+	Status    string        `json:"status,omitempty"`
+}
+
+// spanRecorder is an sdktrace.SpanProcessor keeping track of in-flight spans
+// and a ring buffer of recently errored spans, similar to zPages' tracez.
+type spanRecorder struct {
+	max int
+
+	mu       sync.Mutex
+	inflight map[trace.SpanID]sdktrace.ReadOnlySpan
+	errored  []debugSpan
+	next     int
+}
+
+var _ sdktrace.SpanProcessor = (*spanRecorder)(nil)
+
+// debugRecorder is the recorder registered by WithDebugSpans, if any.
+var debugRecorder *spanRecorder
+
+// newSpanRecorder returns a spanRecorder keeping the last max errored spans.
+func newSpanRecorder(max int) *spanRecorder {
+	return &spanRecorder{
+		max:      max,
+		inflight: make(map[trace.SpanID]sdktrace.ReadOnlySpan),
+	}
+}
+
+// OnStart records s as in flight.
+func (r *spanRecorder) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	r.mu.Lock()
+	r.inflight[s.SpanContext().SpanID()] = s
+	r.mu.Unlock()
+}
+
+// OnEnd removes s from the in-flight set and keeps it if it ended in error.
+func (r *spanRecorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.inflight, s.SpanContext().SpanID())
+
+	if s.Status().Code != codes.Error || r.max <= 0 {
+		return
+	}
+
+	ds := newDebugSpan(s, s.EndTime())
+	if len(r.errored) < r.max {
+		r.errored = append(r.errored, ds)
+		return
+	}
+	r.errored[r.next] = ds
+	r.next = (r.next + 1) % r.max
+}
+
+// Shutdown is a no-op.
+func (r *spanRecorder) Shutdown(context.Context) error { return nil }
+
+// ForceFlush is a no-op.
+func (r *spanRecorder) ForceFlush(context.Context) error { return nil }
+
+// snapshot returns the in-flight spans, longest running first, and the
+// errored spans, most recent first.
+func (r *spanRecorder) snapshot() (inflight, errored []debugSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, s := range r.inflight {
+		inflight = append(inflight, newDebugSpan(s, now))
+	}
+	sort.Slice(inflight, func(i, j int) bool { return inflight[i].Duration > inflight[j].Duration })
+
+	for i := range r.errored {
+		errored = append(errored, r.errored[(r.next+len(r.errored)-1-i)%len(r.errored)])
+	}
+	return inflight, errored
+}
+
+// newDebugSpan summarizes s, measuring its duration up to end.
+func newDebugSpan(s sdktrace.ReadOnlySpan, end time.Time) debugSpan {
+	return debugSpan{
+		Name:      s.Name(),
+		TraceID:   s.SpanContext().TraceID().String(),
+		SpanID:    s.SpanContext().SpanID().String(),
+		Kind:      s.SpanKind().String(),
+		StartTime: s.StartTime(),
+		Duration:  end.Sub(s.StartTime()),
+		Status:    s.Status().Description,
+	}
+}
+
+// debugReport is the document served by DebugHandler.
+type debugReport struct {
+	Service    string            `json:"service"`
+	Enabled    bool              `json:"enabled"`
+	Tracing    bool              `json:"tracing"`
+	Metrics    bool              `json:"metrics"`
+	Resource   map[string]string `json:"resource,omitempty"`
+	Sampler    string            `json:"sampler,omitempty"`
+	Exporter   exporterReport    `json:"exporter"`
+	Inflight   []debugSpan       `json:"inflight_spans,omitempty"`
+	Errored    []debugSpan       `json:"errored_spans,omitempty"`
+	SpansDebug bool              `json:"span_recording"`
+
+	Cardinality []InstrumentCardinality `json:"cardinality,omitempty"`
+}
+
+// exporterReport describes the collector connection.
+type exporterReport struct {
+	Endpoint string `json:"endpoint"`
+	State    string `json:"state"`
+}
+
+// DebugHandler returns an http.Handler that reports the current telemetry
+// configuration as JSON: service name, resource attributes, sampler, exporter
+// endpoint and connection state, plus the WithCardinalityReport figures.
+// When WithDebugSpans is enabled it also lists in-flight spans and recently
+// errored spans, similar to zPages' tracez.
+//
+// It is meant for live troubleshooting and should only be exposed on an
+// internal port:
+//
+//	mux.Handle("/debug/otelx", otelx.DebugHandler())
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := newDebugReport()
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	})
+}
+
+// newDebugReport collects the current pipeline state.
+func newDebugReport() debugReport {
+	pipelineInfo.mu.RLock()
+	report := debugReport{
+		Service: pipelineInfo.service,
+		Enabled: IsEnabled(),
+		Tracing: pipelineInfo.tracing,
+		Metrics: pipelineInfo.metrics,
+		Exporter: exporterReport{
+			Endpoint: pipelineInfo.endpoint,
+			State:    "not connected",
+		},
+	}
+	if pipelineInfo.sampler != nil {
+		report.Sampler = pipelineInfo.sampler.Description()
+	}
+	if res := pipelineInfo.resource; res != nil {
+		report.Resource = make(map[string]string, res.Len())
+		for _, kv := range res.Attributes() {
+			report.Resource[string(kv.Key)] = kv.Value.Emit()
+		}
+	}
+	pipelineInfo.mu.RUnlock()
+
+	if grpcConnection != nil {
+		report.Exporter.State = grpcConnection.GetState().String()
+	}
+
+	if debugRecorder != nil {
+		report.SpansDebug = true
+		report.Inflight, report.Errored = debugRecorder.snapshot()
+	}
+	report.Cardinality = CardinalityReport()
+
+	return report
+}
@@ -0,0 +1,106 @@
+package otelx
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// WithoutHostDetection leaves host.id and host.name out of the resource. Use
+// it where they are meaningless or slow to obtain, such as on AWS Fargate
+// where every task reports a throwaway host.
+func WithoutHostDetection() Option {
+	return func(c *config) {
+		c.noHostDetection = true
+	}
+}
+
+// WithResourceDetectors replaces host detection with detectors, for example
+// cloud platform detectors that identify the task or container better than
+// the host does.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithResourceDetectors(ecs.NewResourceDetector()),
+//	)
+func WithResourceDetectors(detectors ...resource.Detector) Option {
+	return func(c *config) {
+		c.resourceDetectors = detectors
+	}
+}
+
+// WithResourceDetectionTimeout bounds resource detection to d. Detection
+// still running after d is abandoned and the resource is built without its
+// attributes, so a slow hostname lookup or metadata endpoint cannot delay
+// startup. The default is no limit.
+func WithResourceDetectionTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.detectionTimeout = d
+	}
+}
+
+// detected caches the detected attributes, which every resource of the
+// process shares. Detection runs once, with the options in effect when the
+// first provider is created.
+var detected struct {
+	once  sync.Once
+	attrs []attribute.KeyValue
+}
+
+// detectedAttributes returns the attributes from host detection or the
+// WithResourceDetectors detectors, inherited from the parent process when
+// started with ChildProcessEnv and detected once otherwise.
+func detectedAttributes(ctx context.Context) []attribute.KeyValue {
+	detected.once.Do(func() {
+		if v, ok := os.LookupEnv(hostAttributesEnv); ok {
+			detected.attrs = parseHostAttributes(v)
+			return
+		}
+		detected.attrs = detectResource(ctx, *settings())
+	})
+	return detected.attrs
+}
+
+// detectResource runs the detectors configured in cfg within its detection
+// timeout.
+func detectResource(ctx context.Context, cfg config) []attribute.KeyValue {
+	var opts []resource.Option
+	switch {
+	case cfg.resourceDetectors != nil:
+		opts = append(opts, resource.WithDetectors(cfg.resourceDetectors...))
+	case !cfg.noHostDetection:
+		opts = append(opts, resource.WithHost())
+	}
+	if len(opts) == 0 {
+		return nil
+	}
+
+	if cfg.detectionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.detectionTimeout)
+		defer cancel()
+	}
+
+	// Not every detector honors ctx; the result is dropped on timeout.
+	result := make(chan []attribute.KeyValue, 1)
+	go func() {
+		res, err := resource.New(ctx, opts...)
+		if err != nil {
+			logf("failed to detect resource attributes: %v\n", err)
+		}
+		result <- res.Attributes()
+	}()
+
+	select {
+	case attrs := <-result:
+		return attrs
+	case <-ctx.Done():
+		logf("resource detection abandoned: %v\n", ctx.Err())
+		return nil
+	}
+}
@@ -0,0 +1,622 @@
+package otelx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DiskBuffer configures the write-ahead buffer used when the collector is
+// unreachable. See WithDiskBuffer.
+type DiskBuffer struct {
+	// Dir is the directory holding buffered batches, in a spans and a metrics
+	// subdirectory. It is created if needed.
+	Dir string
+
+	// MaxBytes bounds the total size of the buffered batches of each signal.
+	// The oldest batches are discarded first when the limit is exceeded. Zero
+	// means 64 MiB.
+	MaxBytes int64
+
+	// MaxAge discards buffered batches older than this duration. Zero means
+	// one hour.
+	MaxAge time.Duration
+}
+
+// WithDiskBuffer enables a disk-backed buffer for spans and metrics that
+// could not be exported.
+//
+// When an export to the collector fails, the batch is written to Dir instead
+// of being dropped. After the next successful export the buffered batches are
+// replayed oldest first, in the background so the backlog does not delay live
+// exports. The buffer is bounded by size and age so a long outage cannot fill
+// the disk. Batches left by a previous process using the same directory are
+// replayed too.
+//
+// Metric points are replayed with their original timestamps, so the outage
+// does not leave a gap in the series, and delta temporality exporters do not
+// lose the increments recorded during it. Cumulative points buffered by this
+// process are dropped instead: newer points of the same streams, carrying
+// the totals, are exported first and backends reject out-of-order samples.
+// Those of a previous process with another resource (such as another
+// service.instance.id) are replayed.
+//
+// Pass it to NewTraceProvider to buffer spans and to NewMeterProvider to
+// buffer metrics; both can share the directory.
+//
+// Example:
+//
+//	buffer := otelx.WithDiskBuffer(otelx.DiskBuffer{
+//	    Dir:      "/var/lib/otelx",
+//	    MaxBytes: 128 << 20,
+//	    MaxAge:   30 * time.Minute,
+//	})
+//	otelx.NewTraceProvider(ctx, "auth-service", buffer)
+//	otelx.NewMeterProvider(ctx, "auth-service", buffer)
+func WithDiskBuffer(buf DiskBuffer) Option {
+	return func(c *config) {
+		if buf.MaxBytes <= 0 {
+			buf.MaxBytes = 64 << 20
+		}
+		if buf.MaxAge <= 0 {
+			buf.MaxAge = time.Hour
+		}
+		c.diskBuffer = &buf
+	}
+}
+
+// errCorruptBatch is returned by replay functions for batches that cannot be
+// decoded; they are discarded instead of blocking the queue.
+var errCorruptBatch = errors.New("corrupt batch")
+
+// diskQueue is a directory of batch files, written when exports fail and
+// replayed oldest first by a background goroutine.
+type diskQueue struct {
+	dir    string
+	cfg    DiskBuffer
+	signal string
+	// send exports a stored batch.
+	send func(ctx context.Context, data []byte) error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// pending reports whether the directory may hold batches. It starts true
+	// so batches left by a previous process are replayed.
+	pending bool
+	// replaying reports whether the replay goroutine is running.
+	replaying bool
+}
+
+// newDiskQueue creates the queue of signal's batches in cfg.Dir, replayed
+// with send.
+func newDiskQueue(cfg DiskBuffer, signal string, send func(ctx context.Context, data []byte) error) (*diskQueue, error) {
+	dir := filepath.Join(cfg.Dir, signal)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &diskQueue{
+		dir:     dir,
+		cfg:     cfg,
+		signal:  signal,
+		send:    send,
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: true,
+	}
+
+	// Batches left by a previous process may have outlived MaxAge.
+	q.mu.Lock()
+	q.prune()
+	q.mu.Unlock()
+	return q, nil
+}
+
+// store writes batch to a new file and enforces the buffer bounds.
+func (q *diskQueue) store(batch any) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	name := filepath.Join(q.dir, fmt.Sprintf("%020d.json", time.Now().UnixNano()))
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		return err
+	}
+
+	q.pending = true
+	q.prune()
+	return nil
+}
+
+// resume starts replaying the buffered batches in the background, unless the
+// queue is empty or already replaying. It is called after each successful
+// export, when the collector is known to be reachable.
+func (q *diskQueue) resume() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.pending || q.replaying || q.ctx.Err() != nil {
+		return
+	}
+	q.replaying = true
+	q.wg.Add(1)
+	go q.replay()
+}
+
+// replay re-exports buffered batches oldest first, stopping at the first
+// failure so that ordering is preserved. Batches stored meanwhile are
+// replayed too. Batches older than MaxAge are discarded, not replayed.
+func (q *diskQueue) replay() {
+	defer q.wg.Done()
+
+	for {
+		q.mu.Lock()
+		q.prune()
+		files := q.files()
+		if len(files) == 0 {
+			q.pending, q.replaying = false, false
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+
+		for _, f := range files {
+			if !q.replayFile(f.path) {
+				q.mu.Lock()
+				q.replaying = false
+				q.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+// replayFile exports the batch in path and removes it. It reports whether
+// replay can continue with the next batch.
+func (q *diskQueue) replayFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Pruned meanwhile.
+		return true
+	}
+	if err != nil {
+		logf("failed to read buffered %s: %v\n", q.signal, err)
+		return false
+	}
+
+	err = q.send(q.ctx, data)
+	if errors.Is(err, errCorruptBatch) {
+		logf("discarding corrupt %s buffer %s: %v\n", q.signal, path, err)
+	} else if err != nil {
+		return false
+	}
+
+	q.mu.Lock()
+	_ = os.Remove(path)
+	q.mu.Unlock()
+	return true
+}
+
+// close stops the replay and waits for it to return. Buffered batches are
+// kept on disk and replayed by the next process using the same directory.
+func (q *diskQueue) close() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+// bufferFile describes a batch file in the buffer directory.
+type bufferFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists buffered batches, oldest first. The caller must hold q.mu.
+func (q *diskQueue) files() []bufferFile {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil
+	}
+
+	files := make([]bufferFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, bufferFile{
+			path:    filepath.Join(q.dir, entry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files
+}
+
+// prune removes batches older than MaxAge and then the oldest batches until
+// the buffer fits within MaxBytes. The caller must hold q.mu.
+func (q *diskQueue) prune() {
+	files := q.files()
+	cutoff := time.Now().Add(-q.cfg.MaxAge)
+
+	var total int64
+	kept := files[:0]
+	for _, f := range files {
+		if f.modTime.Before(cutoff) {
+			_ = os.Remove(f.path)
+			continue
+		}
+		total += f.size
+		kept = append(kept, f)
+	}
+
+	for len(kept) > 0 && total > q.cfg.MaxBytes {
+		_ = os.Remove(kept[0].path)
+		total -= kept[0].size
+		kept = kept[1:]
+	}
+}
+
+// diskBufferExporter wraps a SpanExporter and spills failed batches to disk,
+// replaying them once the wrapped exporter succeeds again.
+type diskBufferExporter struct {
+	next  sdktrace.SpanExporter
+	queue *diskQueue
+}
+
+var _ sdktrace.SpanExporter = (*diskBufferExporter)(nil)
+
+// newDiskBufferExporter wraps next with the disk buffer described by cfg.
+// If the buffer directory cannot be created, next is returned unchanged.
+func newDiskBufferExporter(next sdktrace.SpanExporter, cfg DiskBuffer) sdktrace.SpanExporter {
+	e := &diskBufferExporter{next: next}
+	queue, err := newDiskQueue(cfg, "spans", e.replay)
+	if err != nil {
+		logf("failed to create disk buffer directory: %v\n", err)
+		return next
+	}
+	e.queue = queue
+	return e
+}
+
+// ExportSpans exports spans through the wrapped exporter. On failure the batch
+// is written to disk and nil is returned, since the data is not lost. On
+// success the replay of buffered batches is resumed.
+func (e *diskBufferExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if err := e.next.ExportSpans(ctx, spans); err != nil {
+		batch := make([]storedSpan, 0, len(spans))
+		for _, s := range spans {
+			batch = append(batch, newStoredSpan(s))
+		}
+		if werr := e.queue.store(batch); werr != nil {
+			return fmt.Errorf("%w (disk buffer: %v)", err, werr)
+		}
+		return nil
+	}
+
+	e.queue.resume()
+	return nil
+}
+
+// Shutdown stops the replay and shuts down the wrapped exporter.
+func (e *diskBufferExporter) Shutdown(ctx context.Context) error {
+	e.queue.close()
+	return e.next.Shutdown(ctx)
+}
+
+// replay exports a stored batch of spans.
+func (e *diskBufferExporter) replay(ctx context.Context, data []byte) error {
+	var batch []storedSpan
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return fmt.Errorf("%w: %v", errCorruptBatch, err)
+	}
+
+	spans := make([]sdktrace.ReadOnlySpan, 0, len(batch))
+	for _, s := range batch {
+		spans = append(spans, s.snapshot())
+	}
+	return e.next.ExportSpans(ctx, spans)
+}
+
+// storedSpan is the on-disk representation of a finished span.
+type storedSpan struct {
+	Name              string        `json:"name"`
+	SpanContext       storedSpanCtx `json:"span_context"`
+	Parent            storedSpanCtx `json:"parent"`
+	Kind              int           `json:"kind"`
+	StartTime         time.Time     `json:"start_time"`
+	EndTime           time.Time     `json:"end_time"`
+	Attributes        []storedAttr  `json:"attributes,omitempty"`
+	Events            []storedEvent `json:"events,omitempty"`
+	Links             []storedLink  `json:"links,omitempty"`
+	StatusCode        uint32        `json:"status_code"`
+	StatusDescription string        `json:"status_description,omitempty"`
+	DroppedAttributes int           `json:"dropped_attributes,omitempty"`
+	DroppedEvents     int           `json:"dropped_events,omitempty"`
+	DroppedLinks      int           `json:"dropped_links,omitempty"`
+	ChildSpanCount    int           `json:"child_span_count,omitempty"`
+	Resource          []storedAttr  `json:"resource,omitempty"`
+	ResourceSchemaURL string        `json:"resource_schema_url,omitempty"`
+	Scope             storedScope   `json:"scope"`
+}
+
+// storedScope is the on-disk representation of an instrumentation.Scope.
+type storedScope struct {
+	Name       string       `json:"name"`
+	Version    string       `json:"version,omitempty"`
+	SchemaURL  string       `json:"schema_url,omitempty"`
+	Attributes []storedAttr `json:"attributes,omitempty"`
+}
+
+// storedSpanCtx is the on-disk representation of a trace.SpanContext.
+type storedSpanCtx struct {
+	TraceID    string `json:"trace_id,omitempty"`
+	SpanID     string `json:"span_id,omitempty"`
+	TraceFlags byte   `json:"trace_flags,omitempty"`
+	TraceState string `json:"trace_state,omitempty"`
+	Remote     bool   `json:"remote,omitempty"`
+}
+
+// storedEvent is the on-disk representation of a span event.
+type storedEvent struct {
+	Name       string       `json:"name"`
+	Time       time.Time    `json:"time"`
+	Attributes []storedAttr `json:"attributes,omitempty"`
+}
+
+// storedLink is the on-disk representation of a span link.
+type storedLink struct {
+	SpanContext storedSpanCtx `json:"span_context"`
+	Attributes  []storedAttr  `json:"attributes,omitempty"`
+}
+
+// storedAttr is the on-disk representation of an attribute, keeping the
+// value type so it can be restored exactly.
+type storedAttr struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// newStoredSpan converts a finished span into its on-disk representation.
+func newStoredSpan(s sdktrace.ReadOnlySpan) storedSpan {
+	out := storedSpan{
+		Name:              s.Name(),
+		SpanContext:       newStoredSpanCtx(s.SpanContext()),
+		Parent:            newStoredSpanCtx(s.Parent()),
+		Kind:              int(s.SpanKind()),
+		StartTime:         s.StartTime(),
+		EndTime:           s.EndTime(),
+		Attributes:        newStoredAttrs(s.Attributes()),
+		StatusCode:        uint32(s.Status().Code),
+		StatusDescription: s.Status().Description,
+		DroppedAttributes: s.DroppedAttributes(),
+		DroppedEvents:     s.DroppedEvents(),
+		DroppedLinks:      s.DroppedLinks(),
+		ChildSpanCount:    s.ChildSpanCount(),
+		Scope:             newStoredScope(s.InstrumentationScope()),
+	}
+	for _, ev := range s.Events() {
+		out.Events = append(out.Events, storedEvent{
+			Name:       ev.Name,
+			Time:       ev.Time,
+			Attributes: newStoredAttrs(ev.Attributes),
+		})
+	}
+	for _, l := range s.Links() {
+		out.Links = append(out.Links, storedLink{
+			SpanContext: newStoredSpanCtx(l.SpanContext),
+			Attributes:  newStoredAttrs(l.Attributes),
+		})
+	}
+	if res := s.Resource(); res != nil {
+		out.Resource = newStoredAttrs(res.Attributes())
+		out.ResourceSchemaURL = res.SchemaURL()
+	}
+	return out
+}
+
+// snapshot restores the span as a ReadOnlySpan suitable for export.
+func (s storedSpan) snapshot() sdktrace.ReadOnlySpan {
+	out := &spanSnapshot{
+		name:        s.Name,
+		spanContext: s.SpanContext.spanContext(),
+		parent:      s.Parent.spanContext(),
+		kind:        trace.SpanKind(s.Kind),
+		start:       s.StartTime,
+		end:         s.EndTime,
+		attributes:  restoreAttrs(s.Attributes),
+		status: sdktrace.Status{
+			Code:        codes.Code(s.StatusCode),
+			Description: s.StatusDescription,
+		},
+		droppedAttributes: s.DroppedAttributes,
+		droppedEvents:     s.DroppedEvents,
+		droppedLinks:      s.DroppedLinks,
+		childSpanCount:    s.ChildSpanCount,
+		resource:          resource.NewWithAttributes(s.ResourceSchemaURL, restoreAttrs(s.Resource)...),
+		scope:             s.Scope.scope(),
+	}
+	for _, ev := range s.Events {
+		out.events = append(out.events, sdktrace.Event{
+			Name:       ev.Name,
+			Time:       ev.Time,
+			Attributes: restoreAttrs(ev.Attributes),
+		})
+	}
+	for _, l := range s.Links {
+		out.links = append(out.links, sdktrace.Link{
+			SpanContext: l.SpanContext.spanContext(),
+			Attributes:  restoreAttrs(l.Attributes),
+		})
+	}
+	return out
+}
+
+// newStoredScope converts scope into its on-disk representation.
+func newStoredScope(scope instrumentation.Scope) storedScope {
+	return storedScope{
+		Name:       scope.Name,
+		Version:    scope.Version,
+		SchemaURL:  scope.SchemaURL,
+		Attributes: newStoredAttrs(scope.Attributes.ToSlice()),
+	}
+}
+
+// scope restores the instrumentation.Scope.
+func (s storedScope) scope() instrumentation.Scope {
+	return instrumentation.Scope{
+		Name:       s.Name,
+		Version:    s.Version,
+		SchemaURL:  s.SchemaURL,
+		Attributes: attribute.NewSet(restoreAttrs(s.Attributes)...),
+	}
+}
+
+// newStoredSpanCtx converts sc into its on-disk representation.
+func newStoredSpanCtx(sc trace.SpanContext) storedSpanCtx {
+	if !sc.IsValid() {
+		return storedSpanCtx{}
+	}
+	return storedSpanCtx{
+		TraceID:    sc.TraceID().String(),
+		SpanID:     sc.SpanID().String(),
+		TraceFlags: byte(sc.TraceFlags()),
+		TraceState: sc.TraceState().String(),
+		Remote:     sc.IsRemote(),
+	}
+}
+
+// spanContext restores the trace.SpanContext. Invalid values yield an empty
+// SpanContext.
+func (s storedSpanCtx) spanContext() trace.SpanContext {
+	if s.TraceID == "" {
+		return trace.SpanContext{}
+	}
+	tid, err := trace.TraceIDFromHex(s.TraceID)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	sid, err := trace.SpanIDFromHex(s.SpanID)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	ts, _ := trace.ParseTraceState(s.TraceState)
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: trace.TraceFlags(s.TraceFlags),
+		TraceState: ts,
+		Remote:     s.Remote,
+	})
+}
+
+// newStoredAttrs converts attrs into their on-disk representation.
+func newStoredAttrs(attrs []attribute.KeyValue) []storedAttr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]storedAttr, 0, len(attrs))
+	for _, kv := range attrs {
+		v, err := json.Marshal(kv.Value.AsInterface())
+		if err != nil {
+			continue
+		}
+		out = append(out, storedAttr{
+			Key:   string(kv.Key),
+			Type:  kv.Value.Type().String(),
+			Value: v,
+		})
+	}
+	return out
+}
+
+// restoreAttrs converts stored attributes back into attribute.KeyValues,
+// skipping entries that cannot be decoded.
+func restoreAttrs(stored []storedAttr) []attribute.KeyValue {
+	if len(stored) == 0 {
+		return nil
+	}
+	out := make([]attribute.KeyValue, 0, len(stored))
+	for _, a := range stored {
+		kv, err := a.keyValue()
+		if err != nil {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
+
+// keyValue decodes the stored attribute according to its type.
+func (a storedAttr) keyValue() (attribute.KeyValue, error) {
+	var err error
+	var kv attribute.KeyValue
+	switch a.Type {
+	case attribute.BOOL.String():
+		var v bool
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.Bool(a.Key, v)
+	case attribute.INT64.String():
+		var v int64
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.Int64(a.Key, v)
+	case attribute.FLOAT64.String():
+		var v float64
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.Float64(a.Key, v)
+	case attribute.STRING.String():
+		var v string
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.String(a.Key, v)
+	case attribute.BOOLSLICE.String():
+		var v []bool
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.BoolSlice(a.Key, v)
+	case attribute.INT64SLICE.String():
+		var v []int64
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.Int64Slice(a.Key, v)
+	case attribute.FLOAT64SLICE.String():
+		var v []float64
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.Float64Slice(a.Key, v)
+	case attribute.STRINGSLICE.String():
+		var v []string
+		err = json.Unmarshal(a.Value, &v)
+		kv = attribute.StringSlice(a.Key, v)
+	default:
+		err = fmt.Errorf("unsupported attribute type %q", a.Type)
+	}
+	return kv, err
+}
@@ -0,0 +1,250 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errUnreachable = errors.New("collector unreachable")
+
+// fakeSpanExporter records exported spans, or fails while fail is set.
+type fakeSpanExporter struct {
+	mu    sync.Mutex
+	fail  bool
+	names []string
+}
+
+func (e *fakeSpanExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.fail {
+		return errUnreachable
+	}
+	for _, s := range spans {
+		e.names = append(e.names, s.Name())
+	}
+	return nil
+}
+
+func (e *fakeSpanExporter) Shutdown(context.Context) error { return nil }
+
+// fakeMetricExporter records the names of exported metrics, or fails while
+// fail is set.
+type fakeMetricExporter struct {
+	sdkmetric.Exporter
+	fail  bool
+	names []string
+}
+
+func (e *fakeMetricExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	if e.fail {
+		return errUnreachable
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			e.names = append(e.names, m.Name)
+		}
+	}
+	return nil
+}
+
+func (e *fakeMetricExporter) Shutdown(context.Context) error { return nil }
+
+// testDiskBuffer returns the DiskBuffer of WithDiskBuffer(buf), with its
+// defaults applied, in a temporary directory.
+func testDiskBuffer(t *testing.T, buf DiskBuffer) DiskBuffer {
+	t.Helper()
+	buf.Dir = t.TempDir()
+	var cfg config
+	WithDiskBuffer(buf)(&cfg)
+	return *cfg.diskBuffer
+}
+
+func TestDiskBufferReplaysSpansAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	buf := testDiskBuffer(t, DiskBuffer{})
+
+	failing := &fakeSpanExporter{fail: true}
+	first := newDiskBufferExporter(failing, buf)
+	for _, name := range []string{"a", "b"} {
+		span := tracetest.SpanStub{Name: name}.Snapshot()
+		if err := first.ExportSpans(ctx, []sdktrace.ReadOnlySpan{span}); err != nil {
+			t.Fatalf("ExportSpans(%s) = %v, want the batch buffered", name, err)
+		}
+	}
+	if err := first.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	next := &fakeSpanExporter{}
+	second := newDiskBufferExporter(next, buf).(*diskBufferExporter)
+	live := tracetest.SpanStub{Name: "live"}.Snapshot()
+	if err := second.ExportSpans(ctx, []sdktrace.ReadOnlySpan{live}); err != nil {
+		t.Fatal(err)
+	}
+	second.queue.wg.Wait()
+
+	if want := []string{"live", "a", "b"}; !slices.Equal(next.names, want) {
+		t.Errorf("exported %v, want %v", next.names, want)
+	}
+	if files := bufferedFiles(t, second.queue); len(files) != 0 {
+		t.Errorf("%d batches left after replay", len(files))
+	}
+}
+
+func TestDiskBufferMetricReplay(t *testing.T) {
+	ctx := context.Background()
+	stored := resource.NewSchemaless(attribute.String("service.instance.id", "a"))
+
+	tests := []struct {
+		name string
+		live *resource.Resource
+		want []string
+	}{
+		{
+			name: "same resource drops cumulative points",
+			live: stored,
+			want: []string{"live", "deltas", "gauge"},
+		},
+		{
+			name: "previous instance keeps cumulative points",
+			live: resource.NewSchemaless(attribute.String("service.instance.id", "b")),
+			want: []string{"live", "totals", "deltas", "gauge"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := testDiskBuffer(t, DiskBuffer{})
+
+			first := newDiskBufferMetricExporter(&fakeMetricExporter{fail: true}, buf)
+			if err := first.Export(ctx, testResourceMetrics(stored, "totals", "deltas", "gauge")); err != nil {
+				t.Fatalf("Export() = %v, want the batch buffered", err)
+			}
+			if err := first.Shutdown(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			next := &fakeMetricExporter{}
+			second := newDiskBufferMetricExporter(next, buf).(*diskBufferMetricExporter)
+			if err := second.Export(ctx, testResourceMetrics(tt.live, "live")); err != nil {
+				t.Fatal(err)
+			}
+			second.queue.wg.Wait()
+
+			if !slices.Equal(next.names, tt.want) {
+				t.Errorf("exported %v, want %v", next.names, tt.want)
+			}
+		})
+	}
+}
+
+// testResourceMetrics returns metrics of res with one point per name: a
+// cumulative sum for "totals" and "live", a delta sum for "deltas" and a
+// gauge for "gauge".
+func testResourceMetrics(res *resource.Resource, names ...string) *metricdata.ResourceMetrics {
+	points := []metricdata.DataPoint[int64]{{
+		Attributes: attribute.NewSet(attribute.String("path", "/orders")),
+		StartTime:  time.Unix(100, 0),
+		Time:       time.Unix(160, 0),
+		Value:      3,
+	}}
+
+	var metrics []metricdata.Metrics
+	for _, name := range names {
+		var data metricdata.Aggregation
+		switch name {
+		case "deltas":
+			data = metricdata.Sum[int64]{DataPoints: points, Temporality: metricdata.DeltaTemporality, IsMonotonic: true}
+		case "gauge":
+			data = metricdata.Gauge[int64]{DataPoints: points}
+		default:
+			data = metricdata.Sum[int64]{DataPoints: points, Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+		}
+		metrics = append(metrics, metricdata.Metrics{Name: name, Data: data})
+	}
+	return &metricdata.ResourceMetrics{
+		Resource:     res,
+		ScopeMetrics: []metricdata.ScopeMetrics{{Metrics: metrics}},
+	}
+}
+
+func TestDiskBufferPrune(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		maxAge   time.Duration
+		ages     []time.Duration
+		want     []int
+	}{
+		{name: "within bounds", maxBytes: 100, maxAge: time.Hour, ages: []time.Duration{3 * time.Minute, 2 * time.Minute, time.Minute}, want: []int{0, 1, 2}},
+		{name: "older than max age", maxBytes: 100, maxAge: time.Hour, ages: []time.Duration{3 * time.Hour, 2 * time.Hour, time.Minute}, want: []int{2}},
+		{name: "over max bytes", maxBytes: 25, maxAge: time.Hour, ages: []time.Duration{3 * time.Minute, 2 * time.Minute, time.Minute}, want: []int{1, 2}},
+		{name: "both", maxBytes: 15, maxAge: time.Hour, ages: []time.Duration{2 * time.Hour, 2 * time.Minute, time.Minute}, want: []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := testDiskBuffer(t, DiskBuffer{MaxBytes: tt.maxBytes, MaxAge: tt.maxAge})
+			dir := filepath.Join(buf.Dir, "spans")
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				t.Fatal(err)
+			}
+
+			// Batches left by a previous process, 10 bytes each.
+			var names []string
+			for i, age := range tt.ages {
+				name := filepath.Join(dir, fmt.Sprintf("%020d.json", i))
+				if err := os.WriteFile(name, []byte("0123456789"), 0o640); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(name, now.Add(-age), now.Add(-age)); err != nil {
+					t.Fatal(err)
+				}
+				names = append(names, name)
+			}
+
+			q, err := newDiskQueue(buf, "spans", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []string
+			for _, i := range tt.want {
+				want = append(want, names[i])
+			}
+			if got := bufferedFiles(t, q); !slices.Equal(got, want) {
+				t.Errorf("kept %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// bufferedFiles returns the paths of the batches in q, oldest first.
+func bufferedFiles(t *testing.T, q *diskQueue) []string {
+	t.Helper()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var paths []string
+	for _, f := range q.files() {
+		paths = append(paths, f.path)
+	}
+	return paths
+}
@@ -0,0 +1,525 @@
+package otelx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// diskBufferMetricExporter is the metrics counterpart of diskBufferExporter.
+type diskBufferMetricExporter struct {
+	sdkmetric.Exporter
+	queue *diskQueue
+
+	// live is the resource of the metrics exported successfully, whose
+	// cumulative streams supersede the buffered points.
+	live atomic.Pointer[resource.Resource]
+}
+
+// newDiskBufferMetricExporter wraps next with the disk buffer described by
+// cfg. If the buffer directory cannot be created, next is returned unchanged.
+func newDiskBufferMetricExporter(next sdkmetric.Exporter, cfg DiskBuffer) sdkmetric.Exporter {
+	e := &diskBufferMetricExporter{Exporter: next}
+	queue, err := newDiskQueue(cfg, "metrics", e.replay)
+	if err != nil {
+		logf("failed to create disk buffer directory: %v\n", err)
+		return next
+	}
+	e.queue = queue
+	return e
+}
+
+// Export exports rm through the wrapped exporter. On failure rm is written to
+// disk and nil is returned, since the data is not lost. On success the replay
+// of buffered batches is resumed.
+func (e *diskBufferMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if err := e.Exporter.Export(ctx, rm); err != nil {
+		// rm is reused by the reader once Export returns, so it is encoded
+		// right away.
+		if werr := e.queue.store(newStoredMetrics(rm)); werr != nil {
+			return fmt.Errorf("%w (disk buffer: %v)", err, werr)
+		}
+		return nil
+	}
+
+	if rm.Resource != nil {
+		e.live.Store(rm.Resource)
+	}
+	e.queue.resume()
+	return nil
+}
+
+// Shutdown stops the replay and shuts down the wrapped exporter.
+func (e *diskBufferMetricExporter) Shutdown(ctx context.Context) error {
+	e.queue.close()
+	return e.Exporter.Shutdown(ctx)
+}
+
+// replay exports a stored batch of metrics. Cumulative points of the
+// resource exported live are dropped, since newer points of their streams
+// were exported already.
+func (e *diskBufferMetricExporter) replay(ctx context.Context, data []byte) error {
+	var stored storedMetrics
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("%w: %v", errCorruptBatch, err)
+	}
+	if live := e.live.Load(); live != nil && stored.resource().Equal(live) {
+		if !stored.dropCumulative() {
+			return nil
+		}
+	}
+	rm, err := stored.resourceMetrics()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errCorruptBatch, err)
+	}
+	return e.Exporter.Export(ctx, rm)
+}
+
+// Kinds of stored metric aggregations.
+const (
+	storedGauge                = "gauge"
+	storedSum                  = "sum"
+	storedHistogram            = "histogram"
+	storedExponentialHistogram = "exponential_histogram"
+	storedSummary              = "summary"
+)
+
+// storedMetrics is the on-disk representation of a metricdata.ResourceMetrics.
+type storedMetrics struct {
+	Resource          []storedAttr         `json:"resource,omitempty"`
+	ResourceSchemaURL string               `json:"resource_schema_url,omitempty"`
+	Scopes            []storedScopeMetrics `json:"scopes"`
+}
+
+// storedScopeMetrics is the on-disk representation of a
+// metricdata.ScopeMetrics.
+type storedScopeMetrics struct {
+	Scope   storedScope    `json:"scope"`
+	Metrics []storedMetric `json:"metrics"`
+}
+
+// storedMetric is the on-disk representation of a metricdata.Metrics. Points
+// holds the data points of the aggregation named by Kind, with values of the
+// Number type.
+type storedMetric struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Unit        string          `json:"unit,omitempty"`
+	Kind        string          `json:"kind"`
+	Number      string          `json:"number,omitempty"`
+	Temporality uint8           `json:"temporality,omitempty"`
+	Monotonic   bool            `json:"monotonic,omitempty"`
+	Points      json.RawMessage `json:"points"`
+}
+
+// storedPoint is the on-disk representation of the data points of all
+// aggregations; the fields unused by an aggregation are left empty.
+type storedPoint[N int64 | float64] struct {
+	Attributes []storedAttr `json:"attributes,omitempty"`
+	StartTime  time.Time    `json:"start_time"`
+	Time       time.Time    `json:"time"`
+
+	// Gauge and sum.
+	Value N `json:"value,omitempty"`
+
+	// Histograms.
+	Count        uint64    `json:"count,omitempty"`
+	Sum          N         `json:"sum,omitempty"`
+	Min          *N        `json:"min,omitempty"`
+	Max          *N        `json:"max,omitempty"`
+	Bounds       []float64 `json:"bounds,omitempty"`
+	BucketCounts []uint64  `json:"bucket_counts,omitempty"`
+
+	// Exponential histograms.
+	Scale          int32                        `json:"scale,omitempty"`
+	ZeroCount      uint64                       `json:"zero_count,omitempty"`
+	ZeroThreshold  float64                      `json:"zero_threshold,omitempty"`
+	PositiveBucket metricdata.ExponentialBucket `json:"positive_bucket"`
+	NegativeBucket metricdata.ExponentialBucket `json:"negative_bucket"`
+
+	// Summaries.
+	Quantiles []metricdata.QuantileValue `json:"quantiles,omitempty"`
+
+	Exemplars []storedExemplar[N] `json:"exemplars,omitempty"`
+}
+
+// storedExemplar is the on-disk representation of a metricdata.Exemplar.
+type storedExemplar[N int64 | float64] struct {
+	FilteredAttributes []storedAttr `json:"filtered_attributes,omitempty"`
+	Time               time.Time    `json:"time"`
+	Value              N            `json:"value"`
+	SpanID             []byte       `json:"span_id,omitempty"`
+	TraceID            []byte       `json:"trace_id,omitempty"`
+}
+
+// newStoredMetrics converts rm into its on-disk representation. Aggregations
+// of unknown types are skipped.
+func newStoredMetrics(rm *metricdata.ResourceMetrics) storedMetrics {
+	var out storedMetrics
+	if rm.Resource != nil {
+		out.Resource = newStoredAttrs(rm.Resource.Attributes())
+		out.ResourceSchemaURL = rm.Resource.SchemaURL()
+	}
+	for _, sm := range rm.ScopeMetrics {
+		scope := storedScopeMetrics{Scope: newStoredScope(sm.Scope)}
+		for _, m := range sm.Metrics {
+			if stored, ok := newStoredMetric(m); ok {
+				scope.Metrics = append(scope.Metrics, stored)
+			}
+		}
+		out.Scopes = append(out.Scopes, scope)
+	}
+	return out
+}
+
+// newStoredMetric converts m into its on-disk representation, reporting
+// false for aggregations of unknown types.
+func newStoredMetric(m metricdata.Metrics) (storedMetric, bool) {
+	out := storedMetric{Name: m.Name, Description: m.Description, Unit: m.Unit}
+
+	var points any
+	switch data := m.Data.(type) {
+	case metricdata.Gauge[int64]:
+		out.Kind, out.Number, points = storedGauge, "int64", storeNumberPoints(data.DataPoints)
+	case metricdata.Gauge[float64]:
+		out.Kind, out.Number, points = storedGauge, "float64", storeNumberPoints(data.DataPoints)
+	case metricdata.Sum[int64]:
+		out.Kind, out.Number, points = storedSum, "int64", storeNumberPoints(data.DataPoints)
+		out.Temporality, out.Monotonic = uint8(data.Temporality), data.IsMonotonic
+	case metricdata.Sum[float64]:
+		out.Kind, out.Number, points = storedSum, "float64", storeNumberPoints(data.DataPoints)
+		out.Temporality, out.Monotonic = uint8(data.Temporality), data.IsMonotonic
+	case metricdata.Histogram[int64]:
+		out.Kind, out.Number, points = storedHistogram, "int64", storeHistogramPoints(data.DataPoints)
+		out.Temporality = uint8(data.Temporality)
+	case metricdata.Histogram[float64]:
+		out.Kind, out.Number, points = storedHistogram, "float64", storeHistogramPoints(data.DataPoints)
+		out.Temporality = uint8(data.Temporality)
+	case metricdata.ExponentialHistogram[int64]:
+		out.Kind, out.Number, points = storedExponentialHistogram, "int64", storeExponentialPoints(data.DataPoints)
+		out.Temporality = uint8(data.Temporality)
+	case metricdata.ExponentialHistogram[float64]:
+		out.Kind, out.Number, points = storedExponentialHistogram, "float64", storeExponentialPoints(data.DataPoints)
+		out.Temporality = uint8(data.Temporality)
+	case metricdata.Summary:
+		out.Kind, points = storedSummary, storeSummaryPoints(data.DataPoints)
+	default:
+		return out, false
+	}
+
+	raw, err := json.Marshal(points)
+	if err != nil {
+		return out, false
+	}
+	out.Points = raw
+	return out, true
+}
+
+// resource restores the resource of the metrics.
+func (s storedMetrics) resource() *resource.Resource {
+	return resource.NewWithAttributes(s.ResourceSchemaURL, restoreAttrs(s.Resource)...)
+}
+
+// dropCumulative removes the metrics of cumulative aggregations and reports
+// whether any metric remains.
+func (s *storedMetrics) dropCumulative() bool {
+	remaining := false
+	for i := range s.Scopes {
+		kept := s.Scopes[i].Metrics[:0]
+		for _, m := range s.Scopes[i].Metrics {
+			if !m.cumulative() {
+				kept = append(kept, m)
+			}
+		}
+		s.Scopes[i].Metrics = kept
+		remaining = remaining || len(kept) > 0
+	}
+	return remaining
+}
+
+// resourceMetrics restores the metricdata.ResourceMetrics.
+func (s storedMetrics) resourceMetrics() (*metricdata.ResourceMetrics, error) {
+	rm := &metricdata.ResourceMetrics{
+		Resource: s.resource(),
+	}
+	for _, scope := range s.Scopes {
+		sm := metricdata.ScopeMetrics{Scope: scope.Scope.scope()}
+		for _, m := range scope.Metrics {
+			data, err := m.aggregation()
+			if err != nil {
+				return nil, fmt.Errorf("metric %s: %w", m.Name, err)
+			}
+			sm.Metrics = append(sm.Metrics, metricdata.Metrics{
+				Name:        m.Name,
+				Description: m.Description,
+				Unit:        m.Unit,
+				Data:        data,
+			})
+		}
+		rm.ScopeMetrics = append(rm.ScopeMetrics, sm)
+	}
+	return rm, nil
+}
+
+// cumulative reports whether the metric's points are cumulative.
+func (m storedMetric) cumulative() bool {
+	return m.Kind == storedSummary ||
+		metricdata.Temporality(m.Temporality) == metricdata.CumulativeTemporality
+}
+
+// aggregation restores the metric's aggregation.
+func (m storedMetric) aggregation() (metricdata.Aggregation, error) {
+	if m.Kind == storedSummary {
+		var points []storedPoint[float64]
+		if err := json.Unmarshal(m.Points, &points); err != nil {
+			return nil, err
+		}
+		return metricdata.Summary{DataPoints: restoreSummaryPoints(points)}, nil
+	}
+
+	switch m.Number {
+	case "int64":
+		return restoreAggregation[int64](m)
+	case "float64":
+		return restoreAggregation[float64](m)
+	}
+	return nil, fmt.Errorf("unsupported number type %q", m.Number)
+}
+
+// restoreAggregation restores an aggregation of N values.
+func restoreAggregation[N int64 | float64](m storedMetric) (metricdata.Aggregation, error) {
+	var points []storedPoint[N]
+	if err := json.Unmarshal(m.Points, &points); err != nil {
+		return nil, err
+	}
+	temporality := metricdata.Temporality(m.Temporality)
+
+	switch m.Kind {
+	case storedGauge:
+		return metricdata.Gauge[N]{DataPoints: restoreNumberPoints(points)}, nil
+	case storedSum:
+		return metricdata.Sum[N]{
+			DataPoints:  restoreNumberPoints(points),
+			Temporality: temporality,
+			IsMonotonic: m.Monotonic,
+		}, nil
+	case storedHistogram:
+		return metricdata.Histogram[N]{
+			DataPoints:  restoreHistogramPoints(points),
+			Temporality: temporality,
+		}, nil
+	case storedExponentialHistogram:
+		return metricdata.ExponentialHistogram[N]{
+			DataPoints:  restoreExponentialPoints(points),
+			Temporality: temporality,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported aggregation %q", m.Kind)
+}
+
+// storeNumberPoints converts gauge and sum data points.
+func storeNumberPoints[N int64 | float64](points []metricdata.DataPoint[N]) []storedPoint[N] {
+	out := make([]storedPoint[N], 0, len(points))
+	for _, p := range points {
+		out = append(out, storedPoint[N]{
+			Attributes: newStoredAttrs(p.Attributes.ToSlice()),
+			StartTime:  p.StartTime,
+			Time:       p.Time,
+			Value:      p.Value,
+			Exemplars:  storeExemplars(p.Exemplars),
+		})
+	}
+	return out
+}
+
+// restoreNumberPoints restores gauge and sum data points.
+func restoreNumberPoints[N int64 | float64](points []storedPoint[N]) []metricdata.DataPoint[N] {
+	out := make([]metricdata.DataPoint[N], 0, len(points))
+	for _, p := range points {
+		out = append(out, metricdata.DataPoint[N]{
+			Attributes: attribute.NewSet(restoreAttrs(p.Attributes)...),
+			StartTime:  p.StartTime,
+			Time:       p.Time,
+			Value:      p.Value,
+			Exemplars:  restoreExemplars(p.Exemplars),
+		})
+	}
+	return out
+}
+
+// storeHistogramPoints converts histogram data points.
+func storeHistogramPoints[N int64 | float64](points []metricdata.HistogramDataPoint[N]) []storedPoint[N] {
+	out := make([]storedPoint[N], 0, len(points))
+	for _, p := range points {
+		out = append(out, storedPoint[N]{
+			Attributes:   newStoredAttrs(p.Attributes.ToSlice()),
+			StartTime:    p.StartTime,
+			Time:         p.Time,
+			Count:        p.Count,
+			Sum:          p.Sum,
+			Min:          storeExtrema(p.Min),
+			Max:          storeExtrema(p.Max),
+			Bounds:       p.Bounds,
+			BucketCounts: p.BucketCounts,
+			Exemplars:    storeExemplars(p.Exemplars),
+		})
+	}
+	return out
+}
+
+// restoreHistogramPoints restores histogram data points.
+func restoreHistogramPoints[N int64 | float64](points []storedPoint[N]) []metricdata.HistogramDataPoint[N] {
+	out := make([]metricdata.HistogramDataPoint[N], 0, len(points))
+	for _, p := range points {
+		out = append(out, metricdata.HistogramDataPoint[N]{
+			Attributes:   attribute.NewSet(restoreAttrs(p.Attributes)...),
+			StartTime:    p.StartTime,
+			Time:         p.Time,
+			Count:        p.Count,
+			Sum:          p.Sum,
+			Min:          restoreExtrema(p.Min),
+			Max:          restoreExtrema(p.Max),
+			Bounds:       p.Bounds,
+			BucketCounts: p.BucketCounts,
+			Exemplars:    restoreExemplars(p.Exemplars),
+		})
+	}
+	return out
+}
+
+// storeExponentialPoints converts exponential histogram data points.
+func storeExponentialPoints[N int64 | float64](points []metricdata.ExponentialHistogramDataPoint[N]) []storedPoint[N] {
+	out := make([]storedPoint[N], 0, len(points))
+	for _, p := range points {
+		out = append(out, storedPoint[N]{
+			Attributes:     newStoredAttrs(p.Attributes.ToSlice()),
+			StartTime:      p.StartTime,
+			Time:           p.Time,
+			Count:          p.Count,
+			Sum:            p.Sum,
+			Min:            storeExtrema(p.Min),
+			Max:            storeExtrema(p.Max),
+			Scale:          p.Scale,
+			ZeroCount:      p.ZeroCount,
+			ZeroThreshold:  p.ZeroThreshold,
+			PositiveBucket: p.PositiveBucket,
+			NegativeBucket: p.NegativeBucket,
+			Exemplars:      storeExemplars(p.Exemplars),
+		})
+	}
+	return out
+}
+
+// restoreExponentialPoints restores exponential histogram data points.
+func restoreExponentialPoints[N int64 | float64](points []storedPoint[N]) []metricdata.ExponentialHistogramDataPoint[N] {
+	out := make([]metricdata.ExponentialHistogramDataPoint[N], 0, len(points))
+	for _, p := range points {
+		out = append(out, metricdata.ExponentialHistogramDataPoint[N]{
+			Attributes:     attribute.NewSet(restoreAttrs(p.Attributes)...),
+			StartTime:      p.StartTime,
+			Time:           p.Time,
+			Count:          p.Count,
+			Sum:            p.Sum,
+			Min:            restoreExtrema(p.Min),
+			Max:            restoreExtrema(p.Max),
+			Scale:          p.Scale,
+			ZeroCount:      p.ZeroCount,
+			ZeroThreshold:  p.ZeroThreshold,
+			PositiveBucket: p.PositiveBucket,
+			NegativeBucket: p.NegativeBucket,
+			Exemplars:      restoreExemplars(p.Exemplars),
+		})
+	}
+	return out
+}
+
+// storeSummaryPoints converts summary data points.
+func storeSummaryPoints(points []metricdata.SummaryDataPoint) []storedPoint[float64] {
+	out := make([]storedPoint[float64], 0, len(points))
+	for _, p := range points {
+		out = append(out, storedPoint[float64]{
+			Attributes: newStoredAttrs(p.Attributes.ToSlice()),
+			StartTime:  p.StartTime,
+			Time:       p.Time,
+			Count:      p.Count,
+			Sum:        p.Sum,
+			Quantiles:  p.QuantileValues,
+		})
+	}
+	return out
+}
+
+// restoreSummaryPoints restores summary data points.
+func restoreSummaryPoints(points []storedPoint[float64]) []metricdata.SummaryDataPoint {
+	out := make([]metricdata.SummaryDataPoint, 0, len(points))
+	for _, p := range points {
+		out = append(out, metricdata.SummaryDataPoint{
+			Attributes:     attribute.NewSet(restoreAttrs(p.Attributes)...),
+			StartTime:      p.StartTime,
+			Time:           p.Time,
+			Count:          p.Count,
+			Sum:            p.Sum,
+			QuantileValues: p.Quantiles,
+		})
+	}
+	return out
+}
+
+// storeExemplars converts exemplars.
+func storeExemplars[N int64 | float64](exemplars []metricdata.Exemplar[N]) []storedExemplar[N] {
+	if len(exemplars) == 0 {
+		return nil
+	}
+	out := make([]storedExemplar[N], 0, len(exemplars))
+	for _, e := range exemplars {
+		out = append(out, storedExemplar[N]{
+			FilteredAttributes: newStoredAttrs(e.FilteredAttributes),
+			Time:               e.Time,
+			Value:              e.Value,
+			SpanID:             e.SpanID,
+			TraceID:            e.TraceID,
+		})
+	}
+	return out
+}
+
+// restoreExemplars restores exemplars.
+func restoreExemplars[N int64 | float64](exemplars []storedExemplar[N]) []metricdata.Exemplar[N] {
+	if len(exemplars) == 0 {
+		return nil
+	}
+	out := make([]metricdata.Exemplar[N], 0, len(exemplars))
+	for _, e := range exemplars {
+		out = append(out, metricdata.Exemplar[N]{
+			FilteredAttributes: restoreAttrs(e.FilteredAttributes),
+			Time:               e.Time,
+			Value:              e.Value,
+			SpanID:             e.SpanID,
+			TraceID:            e.TraceID,
+		})
+	}
+	return out
+}
+
+// storeExtrema returns the value of e, or nil if it is undefined.
+func storeExtrema[N int64 | float64](e metricdata.Extrema[N]) *N {
+	if v, ok := e.Value(); ok {
+		return &v
+	}
+	return nil
+}
+
+// restoreExtrema restores an extrema stored by storeExtrema.
+func restoreExtrema[N int64 | float64](v *N) metricdata.Extrema[N] {
+	if v == nil {
+		return metricdata.Extrema[N]{}
+	}
+	return metricdata.NewExtrema(*v)
+}
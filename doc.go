@@ -28,14 +28,29 @@
 //	    Enables or disables tracing/metrics globally.
 //	    When disabled, otelx falls back to no-op providers.
 //
-//	OTEL_COLLECTOR_ENDPOINT=host:port
-//	    The OTLP gRPC endpoint for the OpenTelemetry Collector.
+//	OTEL_COLLECTOR_ENDPOINT=host:port|https://host:port|unix:///path/to/collector.sock
+//	    The OTLP gRPC endpoint for the OpenTelemetry Collector. Unix domain
+//	    sockets are supported for sidecar/agent deployments. https://,
+//	    grpcs:// and grpc+tls:// endpoints use TLS, others plaintext;
+//	    WithCollectorTLS and WithInsecureCollector override the choice.
 //
 //	SERVICE_VERSION=string
 //	    The semantic version of the service (set as a Resource attribute).
 //
 //	ENV=local|dev|prod
-//	    Deployment environment.
+//	    Deployment environment. It also selects a defaults profile (see
+//	    WithProfile): local adds stdout exporters, or only writes to stdout
+//	    without OTEL_ENABLE when no collector endpoint is set, dev samples
+//	    everything and prod uses parent-based 10% sampling with stricter span
+//	    limits.
+//
+//	OTEL_PROPAGATORS=tracecontext,baggage,b3,b3multi,jaeger,xray,none
+//	    Selects the global propagators, in order. The default is
+//	    tracecontext,baggage; none disables propagation.
+//
+//	OTELX_METRIC_NAMING=prometheus
+//	    Rewrites exported metric and attribute names to be Prometheus-safe
+//	    (see WithPrometheusNaming).
 //
 // # Tracing
 //
@@ -47,8 +62,12 @@
 //
 // This sets the global tracer provider and configures:
 //
-//   - AlwaysSample sampler
-//   - BatchSpanProcessor
+//   - AlwaysSample sampler (or the one named by OTEL_TRACES_SAMPLER with the
+//     ratio from OTEL_TRACES_SAMPLER_ARG, or a custom sampler given with
+//     WithSampler/WithSamplerFunc, such as the per-endpoint
+//     WithThroughputSampling); with WithSamplingPriority,
+//     requests carrying sampling.priority=1 baggage are always sampled
+//   - BatchSpanProcessor (whose queue memory WithSpanMemoryLimit bounds)
 //   - OTLP gRPC exporter
 //   - Composite propagator (W3C TraceContext + Baggage)
 //
@@ -60,6 +79,17 @@
 // StartSpan() automatically names spans using the caller function and line
 // number, which is useful for debugging without manually naming each span.
 //
+// RecordErrorChain records an error and each error it wraps (including
+// errors.Join branches) as exception events, so the root cause is visible:
+//
+//	otelx.RecordErrorChain(span, err)
+//
+// DBAttrs, MessagingAttrs and HTTPAttrs build semantic convention attributes
+// for hand-written spans, so teams use the same keys:
+//
+//	ctx, span := otelx.StartSpan(ctx,
+//	    trace.WithAttributes(otelx.DBAttrs("postgresql", "orders", "SELECT")...))
+//
 // # Metrics
 //
 // Call NewMeterProvider() once during startup:
@@ -72,6 +102,11 @@
 //	http_requests_total (Int64Counter)
 //	http_request_duration_seconds (Float64Histogram)
 //
+// plus direct error series: http_errors_total (5xx responses) and
+// rpc_errors_total (non-OK gRPC codes). Panics recovered by RecoveryMiddleware
+// and the gRPC recovery interceptors are counted in panics_total, and the
+// stream interceptor maintains rpc_server_active_streams{method}.
+//
 // These metrics are used across:
 //
 //   - HTTP middleware
@@ -81,9 +116,74 @@
 // Each metric includes consistent attributes:
 //
 //	method: HTTP or gRPC method
-//	path: HTTP path (HTTP only)
+//	path: HTTP path (HTTP only), "<unmatched>" for 404s no route matched
 //	status_code: integer response code
 //
+// WithMetricPrefix, WithInstrumentName and WithInstrumentDescription adapt
+// these names to an established naming scheme, for example myorg_ prefixes.
+// WithMillisecondDurations records request durations in milliseconds
+// (http_request_duration_milliseconds, with scaled buckets) for alert rules
+// and dashboards written in ms.
+//
+// WithMetricCardinalityLimit caps the distinct values per attribute, folding
+// the overflow into "other", and WithMetricAttributes restricts metric
+// attributes to an allowlist, so one unbounded label cannot overwhelm the
+// metrics backend. WithCardinalityReport periodically logs the instruments
+// with the most series to find such labels.
+//
+// otelx also reports on its own pipeline so silent telemetry loss can be
+// alerted on:
+//
+//	otelx_exports_total{signal, exporter, result}
+//	otelx_export_duration_seconds{signal, exporter}
+//	otelx_spans_dropped_total{reason}
+//	otelx_span_queue_utilization{exporter}
+//	otelx_metric_export_lag_seconds{exporter}
+//	otelx_export_payload_bytes{signal}
+//
+// The two gauges let capacity alerts fire before spans are dropped: the batch
+// queue filling up towards 1, or the metric reader falling behind its export
+// interval. otelx_export_payload_bytes measures the serialized OTLP requests
+// sent to the collector: its sum attributes collector ingress bandwidth to the
+// service and its count gives the export frequency, to tune batch settings.
+//
+// HealthHandler serves the same information as JSON (collector connectivity,
+// last successful export per signal, dropped spans) for platform dashboards:
+//
+//	mux.Handle("/health/telemetry", otelx.HealthHandler())
+//
+// GenerateDashboard emits a Grafana dashboard matching these instrument names
+// and attributes, so every service gets a correct dashboard without writing
+// PromQL:
+//
+//	data, err := otelx.GenerateDashboard("auth-service")
+//
+// # Events
+//
+// EmitEvent records structured, trace-correlated events (usage analytics,
+// audit trails) as OpenTelemetry log-based events once NewLoggerProvider has
+// been called:
+//
+//	shutdownEvents := otelx.NewLoggerProvider(ctx, "auth-service")
+//	defer shutdownEvents()
+//
+//	otelx.EmitEvent(ctx, "user.login", map[string]any{"method": "sso"})
+//
+// # Feature Flags
+//
+// Feature flag and experiment assignments recorded with ContextWithFeatureFlag
+// travel in baggage and are stamped on SERVER spans as feature_flag.<flag>
+// attributes; WithFlagEvaluator adds assignments from a flag SDK. Flags listed
+// in WithFeatureFlagDimensions also become request metric dimensions, so A/B
+// latency comparisons can be made directly in the backends:
+//
+//	ctx = otelx.ContextWithFeatureFlag(ctx, "new-pricing", "treatment")
+//
+// With OpenFeature, register FlagEvaluationHook to record every evaluation as
+// a span event and in feature_flag_evaluations_total:
+//
+//	openfeature.AddHooks(otelx.NewFlagEvaluationHook())
+//
 // # HTTP Instrumentation
 //
 // otelx includes:
@@ -96,11 +196,61 @@
 //	r := mux.NewRouter()
 //	r.Use(otelx.MetricsMiddleware)
 //
+// RequestIDMiddleware reuses or generates a request ID, propagates it as the
+// request.id baggage member and records it on spans; RequestID(ctx) returns it.
+//
+// Serve wires TracingMiddleware, RequestIDMiddleware, MetricsMiddleware and
+// RecoveryMiddleware in the right order and handles graceful shutdown with a
+// telemetry flush:
+//
+//	if err := otelx.Serve(":8080", mux); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Serve also installs ConnStateHook, which records open connections by state
+// and connection state transitions, revealing connection churn and keep-alive
+// misconfiguration. Set it as http.Server.ConnState for servers started
+// without Serve.
+//
+// WithTLSMetrics records the handshake duration, protocol version and cipher
+// suite of inbound TLS connections and adds them to HTTPS request spans, to
+// find clients on deprecated TLS versions. Serve instruments its TLS
+// configuration; other servers wrap theirs with InstrumentTLSConfig.
+//
+// HandlerFunc adapts handlers returning an error: the error is mapped to a
+// status code (WithErrorStatusMapper), recorded on the span, counted in
+// http_handler_errors_total and answered with the status text:
+//
+//	mux.Handle("GET /orders/{id}", otelx.HandlerFunc(getOrder))
+//
+// The middlewares share one ResponseWriter per request: NewResponseWriter
+// reuses writers that already implement it, including ones from other
+// middleware, so status codes and byte counts agree.
+//
+// WithSyntheticTraffic detects uptime checkers and bots by User-Agent and
+// marker headers, and either leaves them out of request metrics and traces
+// (SyntheticExclude) or labels them synthetic="true" (SyntheticLabel), so
+// they do not skew real-user latency.
+//
 // Outgoing example:
 //
 //	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 //	resp, err := otelx.DoRequest(ctx, req)
 //
+// DoRequestWithTimeout overrides the default 20 second timeout for a single
+// call and records it on the client span.
+//
+// Requests rejected by a rate limiter in front of the middleware are counted
+// with a rejected="rate_limit" attribute and a minimal span through
+// RecordRejectedRequest or RejectionMiddleware (RecordRejectedRPC and
+// RejectionTapHandle for gRPC tap handlers), so traffic numbers include them.
+//
+// NewCircuitBreaker protects an outgoing dependency over HTTP
+// (CircuitBreaker.HTTPClient, CircuitBreaker.Transport) or gRPC (client
+// interceptors). Its state is exported as the circuit_breaker_state gauge,
+// transitions are span events, and short-circuited calls are tagged on their
+// spans, so resilience behavior shows up in the traces it affects.
+//
 // # gRPC Instrumentation
 //
 // Unary interceptor:
@@ -111,7 +261,29 @@
 //
 //	grpc.StreamInterceptor(otelx.StreamServerMetricsInterceptor())
 //
-// Both interceptors record request count and latency for:
+// Tracing interceptors create SERVER spans (and CLIENT spans on the client
+// side) and propagate trace context through gRPC metadata:
+//
+//	grpc.ChainUnaryInterceptor(
+//	    otelx.UnaryServerTracingInterceptor(),
+//	    otelx.UnaryServerMetricsInterceptor(),
+//	)
+//
+// Details attached to a returned status (ErrorInfo, BadRequest field
+// violations, RetryInfo...) are recorded as rpc.grpc.status_detail span
+// events, so validation failures can be debugged from the trace.
+//
+// ChainUnaryInterceptors and ChainStreamInterceptors install tracing, metrics
+// and recovery in that order ahead of the application's own interceptors, so
+// calls rejected by auth interceptors are still traced and counted:
+//
+//	grpc.NewServer(otelx.ChainUnaryInterceptors(authInterceptor))
+//
+// Legacy correlation headers (x-correlation-id by default, see
+// WithCorrelationKeys) are mapped onto baggage by the global propagator, so
+// they flow symmetrically through HTTP headers and gRPC metadata.
+//
+// Both metrics interceptors record request count and latency for:
 //
 //   - Unary RPCs
 //   - Client-streaming RPCs
@@ -123,6 +295,18 @@
 //	method: full gRPC method (/package.Service/Method)
 //	status_code: gRPC status as int
 //
+// Outgoing calls have their own instruments, rpc_client_requests_total and
+// rpc_client_duration_seconds, with rpc.service and server.address
+// identifying the target, so dependency dashboards are distinguishable from
+// serving ones:
+//
+//	grpc.NewClient(target,
+//	    grpc.WithChainUnaryInterceptor(
+//	        otelx.UnaryClientTracingInterceptor(),
+//	        otelx.UnaryClientMetricsInterceptor(),
+//	    ),
+//	)
+//
 // # Outgoing HTTP Tracing
 //
 // otelx.HTTPClient() wraps the default transport using otelhttp.NewTransport,
@@ -131,6 +315,117 @@
 //	client := otelx.HTTPClient(ctx, req)
 //	client.Do(req)
 //
+// Outgoing request and response body sizes are recorded in
+// http_client_request_size_bytes and http_client_response_size_bytes with
+// server.address set to the target host, to find the integrations driving
+// payload growth and egress costs.
+//
+// ClientOptions customize the client spans: WithSpanNameFormatter names them
+// (MethodHostPathSpanName gives "GET payments-api /v1/charges") and
+// WithClientFilter skips tracing some requests:
+//
+//	resp, err := otelx.DoRequest(ctx, req, otelx.WithSpanNameFormatter(otelx.MethodHostPathSpanName))
+//
+// AWS SDK v2 clients are traced and measured once their configuration is
+// passed to Instrument from the github.com/edr3x/otelx/otelaws module, kept
+// separate so the AWS SDK is only pulled in by services using it, with calls
+// appearing as children of the request spans:
+//
+//	otelaws.Instrument(&cfg)
+//	client := s3.NewFromConfig(cfg)
+//
+// # Multiple Services
+//
+// A modular monolith can host several logical services in one binary with
+// NewService. Each Service has its own service.name resource and providers on
+// the shared collector connection; requests entering through its middleware
+// or interceptors are recorded into it:
+//
+//	billing, _ := otelx.NewService(ctx, "billing")
+//	mux.Handle("/billing/", billing.Middleware(billingHandler))
+//
+// # Worker Processes
+//
+// Services that pre-fork workers or run plugin subprocesses start them with
+// ChildProcessEnv and initialize them with InitChildProcess. Children inherit
+// the configuration and the parent's host detection through the environment,
+// and get their own service.instance.id so workers do not overwrite each
+// other's series:
+//
+//	cmd.Env = otelx.ChildProcessEnv(ctx)
+//
+//	// In the worker:
+//	cleanup := otelx.InitChildProcess(ctx, "image-worker")
+//	defer cleanup()
+//
+// Shell-out steps stay in the trace with InjectTraceEnv, which passes the
+// trace context to an exec.Cmd as TRACEPARENT and TRACESTATE; the subprocess
+// continues it with ContextFromEnv:
+//
+//	cmd := exec.CommandContext(ctx, "./migrate.sh")
+//	otelx.InjectTraceEnv(ctx, cmd)
+//
+// # Temporal Workflows
+//
+// The github.com/edr3x/otelx/oteltemporal module, kept separate so the
+// Temporal SDK is only pulled in by services using it, provides an
+// interceptor that carries the trace context from the client starting a
+// workflow into the workflow and its activities, emits spans for them, and
+// records activity schedule-to-start and execution latencies and workflow
+// execution time:
+//
+//	c, err := client.Dial(client.Options{
+//	    Interceptors: []interceptor.ClientInterceptor{oteltemporal.NewInterceptor()},
+//	})
+//
+// # Configuration Files
+//
+// LoadConfig reads the same settings from a YAML or JSON file, with ${VAR}
+// interpolation and validation errors naming the offending field:
+//
+//	cfg, err := otelx.LoadConfig("otelx.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	cfg.Setenv()
+//	tp, cleanup := otelx.NewTraceProvider(ctx, cfg.Service, cfg.Options()...)
+//
+// # Options
+//
+// NewTraceProvider() and NewMeterProvider() accept optional Options that
+// customize the providers and the instrumentation sharing them:
+//
+//	tp, cleanup := otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithIDGenerator(otelx.NewDeterministicIDGenerator(42)),
+//	    otelx.WithClock(fakeClock),
+//	)
+//
+// Deterministic IDs and an injected Clock make exported telemetry and recorded
+// durations reproducible in tests.
+//
+// WithSchemaURL pins the semantic conventions schema of the Resource and of
+// otelx's tracers and meters, so mixed-version fleets agree during a semconv
+// migration.
+//
+// WithDialOptions passes extra grpc.DialOptions (keepalive, service config,
+// resolvers, interceptors) to the shared collector connection, and
+// WithCollectorConn replaces it with a connection the application manages.
+//
+// Host detection runs once per process. WithoutHostDetection turns it off,
+// WithResourceDetectors replaces it with other detectors, and
+// WithResourceDetectionTimeout keeps a slow lookup from delaying startup.
+//
+// # Reloading
+//
+// The enabled flag (OTEL_ENABLE), sampler (OTEL_TRACES_SAMPLER and
+// OTEL_TRACES_SAMPLER_ARG) and ignored paths (OTELX_IGNORE_PATHS) are re-read
+// by Reload(). Since the environment of a running process cannot be changed
+// from outside, they are changed without a restart through OTELX_CONFIG_FILE,
+// a JSON file that is watched and applied live, or right away on SIGHUP with
+// WithReloadOnSIGHUP():
+//
+//	{"sampler": "parentbased_traceidratio", "sampler_ratio": 0.1, "ignore_paths": ["^GET /internal/"], "slow_thresholds": {"*": "1s"}}
+//
 // # Graceful Shutdown
 //
 // Both the TracerProvider and MeterProvider support graceful shutdown:
@@ -140,15 +435,30 @@
 //
 // This ensures all pending spans and metrics are flushed before process exit.
 //
+// Shutdown does the same for every provider otelx created, in the right
+// order, and closes the collector connection, which short-lived jobs should
+// do before exiting:
+//
+//	defer otelx.Shutdown(ctx)
+//
+// Flushing waits for export retries. WithTraceExportRetry,
+// WithMetricExportRetry and the export timeout options bound that wait when
+// the collector is degraded.
+//
 // # No-op Mode
 //
 // If OTEL_ENABLE != "true" or the collector connection fails:
 //
 //   - Tracing falls back to noop.NewTracerProvider()
-//   - Metrics middleware still runs but uses no-op instruments
+//   - Metrics middleware and interceptors become pass-throughs with no
+//     per-request overhead
 //
 // This guarantees the service never crashes due to telemetry failure.
 //
+// WithSuppressedTelemetryAccounting counts the spans and measurements that
+// would have been emitted, reported by HealthHandler, to size the collector
+// before enabling telemetry.
+//
 // # Best Practices
 //
 //   - Initialize tracing & metrics once at startup
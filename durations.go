@@ -0,0 +1,73 @@
+package otelx
+
+import (
+	"math"
+	"strings"
+)
+
+// WithMillisecondDurations records request durations in milliseconds instead
+// of seconds, for organizations whose alert rules and dashboards are all in
+// ms:
+//
+//   - http_request_duration_seconds becomes http_request_duration_milliseconds
+//   - rpc_client_duration_seconds becomes rpc_client_duration_milliseconds
+//
+// Their units and bucket boundaries are scaled accordingly, so percentiles are
+// unchanged. WithInstrumentName and WithInstrumentDescription keep using the
+// seconds names as keys. Other durations (stream message intervals, TLS
+// handshakes, span metrics, otelx's own telemetry) stay in seconds.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "auth-service", otelx.WithMillisecondDurations())
+func WithMillisecondDurations() Option {
+	return func(c *config) {
+		c.millisecondDurations = true
+	}
+}
+
+// durationInstrumentName is instrumentName for the request duration
+// instruments, whose default names follow the configured unit.
+func durationInstrumentName(name string) string {
+	if _, ok := settings().instrumentNames[name]; ok || !settings().millisecondDurations {
+		return instrumentName(name)
+	}
+	return settings().metricPrefix + strings.TrimSuffix(name, "_seconds") + "_milliseconds"
+}
+
+// durationUnit returns the UCUM unit of request durations.
+func durationUnit() string {
+	if settings().millisecondDurations {
+		return "ms"
+	}
+	return "s"
+}
+
+// durationDescription returns def, a description ending in "in seconds", in
+// the configured unit.
+func durationDescription(def string) string {
+	if settings().millisecondDurations {
+		return strings.TrimSuffix(def, "seconds") + "milliseconds"
+	}
+	return def
+}
+
+// durationBuckets returns bounds, given in seconds, in the configured unit.
+func durationBuckets(bounds ...float64) []float64 {
+	if settings().millisecondDurations {
+		for i := range bounds {
+			// Rounded so 0.3 becomes 300 rather than 300.00000000000006.
+			bounds[i] = math.Round(bounds[i]*1e6) / 1e3
+		}
+	}
+	return bounds
+}
+
+// durationValue converts a request duration in seconds to the configured
+// unit.
+func durationValue(seconds float64) float64 {
+	if settings().millisecondDurations {
+		return seconds * 1000
+	}
+	return seconds
+}
@@ -0,0 +1,73 @@
+package otelx
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDurationBuckets(t *testing.T) {
+	tests := []struct {
+		name         string
+		milliseconds bool
+		bounds       []float64
+		want         []float64
+	}{
+		{
+			name:   "seconds",
+			bounds: []float64{0.005, 0.1, 0.3, 1, 10},
+			want:   []float64{0.005, 0.1, 0.3, 1, 10},
+		},
+		{
+			name:         "milliseconds",
+			milliseconds: true,
+			bounds:       []float64{0.005, 0.1, 0.3, 1, 10},
+			want:         []float64{5, 100, 300, 1000, 10000},
+		},
+		{
+			name:         "milliseconds rounding",
+			milliseconds: true,
+			bounds:       []float64{0.0025, 0.075, 2.5},
+			want:         []float64{2.5, 75, 2500},
+		},
+		{
+			name:         "empty",
+			milliseconds: true,
+			bounds:       nil,
+			want:         nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSettings(t, func(cfg *config) { cfg.millisecondDurations = tt.milliseconds })
+
+			if got := durationBuckets(tt.bounds...); !slices.Equal(got, tt.want) {
+				t.Errorf("durationBuckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationInstrumentName(t *testing.T) {
+	tests := []struct {
+		name         string
+		instrument   string
+		milliseconds bool
+		want         string
+	}{
+		{name: "http seconds", instrument: "http_request_duration_seconds", want: "http_request_duration_seconds"},
+		{name: "http milliseconds", instrument: "http_request_duration_seconds", milliseconds: true, want: "http_request_duration_milliseconds"},
+		{name: "rpc client seconds", instrument: "rpc_client_duration_seconds", want: "rpc_client_duration_seconds"},
+		{name: "rpc client milliseconds", instrument: "rpc_client_duration_seconds", milliseconds: true, want: "rpc_client_duration_milliseconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSettings(t, func(cfg *config) { cfg.millisecondDurations = tt.milliseconds })
+
+			if got := durationInstrumentName(tt.instrument); got != tt.want {
+				t.Errorf("durationInstrumentName(%q) = %q, want %q", tt.instrument, got, tt.want)
+			}
+		})
+	}
+}
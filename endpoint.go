@@ -0,0 +1,120 @@
+package otelx
+
+import (
+	"crypto/tls"
+	"strings"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// collectorTarget converts the OTEL_COLLECTOR_ENDPOINT value into a gRPC
+// dial target.
+//
+// Supported forms:
+//
+//	host:port                          TCP (default)
+//	unix:///var/run/otel/collector.sock Unix domain socket (absolute path)
+//	unix:relative/collector.sock       Unix domain socket (relative path)
+//	/var/run/otel/collector.sock       Unix domain socket (bare absolute path)
+//
+// Unix domain sockets avoid TCP overhead and local port allocation in
+// sidecar/agent deployments.
+func collectorTarget(endpoint string) string {
+	endpoint = strings.TrimSpace(endpoint)
+
+	switch {
+	case strings.HasPrefix(endpoint, "unix:"), strings.HasPrefix(endpoint, "unix-abstract:"):
+		return endpoint
+	case strings.HasPrefix(endpoint, "/"):
+		return "unix://" + endpoint
+	default:
+		return endpoint
+	}
+}
+
+// isUnixTarget reports whether target dials a Unix domain socket.
+func isUnixTarget(target string) bool {
+	return strings.HasPrefix(target, "unix:") || strings.HasPrefix(target, "unix-abstract:")
+}
+
+// collectorSecurity selects the transport security of the collector
+// connection.
+type collectorSecurity int
+
+const (
+	// securityAuto picks TLS or plaintext from the endpoint's scheme.
+	securityAuto collectorSecurity = iota
+	securityTLS
+	securityInsecure
+)
+
+// endpointSchemes maps the endpoint schemes to whether they select TLS.
+var endpointSchemes = map[string]bool{
+	"https://":    true,
+	"grpcs://":    true,
+	"grpc+tls://": true,
+	"http://":     false,
+	"grpc://":     false,
+}
+
+// collectorEndpoint splits the OTEL_COLLECTOR_ENDPOINT value into a gRPC dial
+// target and whether its scheme asks for TLS:
+//
+//	https://collector:4317, grpcs://..., grpc+tls://...  TLS
+//	http://collector:4317, grpc://...                    plaintext
+//	collector:4317, unix:///...                          plaintext (see collectorTarget)
+//
+// Trailing paths such as "/" are ignored.
+func collectorEndpoint(endpoint string) (target string, secure bool) {
+	endpoint = strings.TrimSpace(endpoint)
+
+	for scheme, secure := range endpointSchemes {
+		if rest, ok := strings.CutPrefix(endpoint, scheme); ok {
+			host, _, _ := strings.Cut(rest, "/")
+			return host, secure
+		}
+	}
+	return collectorTarget(endpoint), false
+}
+
+// WithCollectorTLS makes the collector connection use TLS with cfg (the
+// system roots when cfg is nil), whatever the scheme of
+// OTEL_COLLECTOR_ENDPOINT.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithCollectorTLS(&tls.Config{RootCAs: pool}),
+//	)
+func WithCollectorTLS(cfg *tls.Config) Option {
+	return func(c *config) {
+		c.collectorSecurity = securityTLS
+		c.collectorTLS = cfg
+	}
+}
+
+// WithInsecureCollector makes the collector connection use plaintext, even
+// when OTEL_COLLECTOR_ENDPOINT has an https:// or grpc+tls:// scheme, for
+// example behind a TLS-terminating sidecar.
+func WithInsecureCollector() Option {
+	return func(c *config) {
+		c.collectorSecurity = securityInsecure
+	}
+}
+
+// collectorCredentials returns the transport credentials for a collector
+// endpoint whose scheme asked for TLS when secure is true, honoring
+// WithCollectorTLS and WithInsecureCollector.
+func collectorCredentials(cfg config, secure bool) credentials.TransportCredentials {
+	switch cfg.collectorSecurity {
+	case securityTLS:
+		secure = true
+	case securityInsecure:
+		secure = false
+	}
+	if secure {
+		return credentials.NewTLS(cfg.collectorTLS)
+	}
+	return insecure.NewCredentials()
+}
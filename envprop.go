@@ -0,0 +1,117 @@
+package otelx
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+)
+
+// envCarrier adapts environment variables to propagation.TextMapCarrier:
+// header keys map to their upper-case form (traceparent to TRACEPARENT), the
+// convention for carrying trace context into subprocesses.
+type envCarrier map[string]string
+
+// Get returns the value of the variable for key.
+func (c envCarrier) Get(key string) string {
+	return c[envKey(key)]
+}
+
+// Set stores value in the variable for key.
+func (c envCarrier) Set(key, value string) {
+	c[envKey(key)] = value
+}
+
+// Keys lists the variables in the carrier.
+func (c envCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// envKey returns the environment variable name of a propagation header.
+func envKey(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// InjectTraceEnv adds the trace context of ctx to the environment of cmd as
+// TRACEPARENT and TRACESTATE (and BAGGAGE, with the other fields of the
+// global propagator), so a subprocess continues the trace: otelx programs
+// with ContextFromEnv, and other tools following the same convention. A nil
+// cmd.Env is first set to the current environment, as exec.Cmd would use it.
+//
+// Example:
+//
+//	ctx, span := otelx.StartSpan(ctx)
+//	defer span.End()
+//
+//	cmd := exec.CommandContext(ctx, "./migrate.sh")
+//	otelx.InjectTraceEnv(ctx, cmd)
+//	err := cmd.Run()
+func InjectTraceEnv(ctx context.Context, cmd *exec.Cmd) {
+	carrier := envCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	// Drop inherited values, which describe the parent's own parent.
+	kept := make([]string, 0, len(env)+len(carrier))
+	for _, e := range env {
+		name, _, _ := strings.Cut(e, "=")
+		if !isPropagationEnv(name) {
+			kept = append(kept, e)
+		}
+	}
+	for k, v := range carrier {
+		kept = append(kept, k+"="+v)
+	}
+	cmd.Env = kept
+}
+
+// isPropagationEnv reports whether name is the variable of a field of the
+// global propagator.
+func isPropagationEnv(name string) bool {
+	for _, f := range otel.GetTextMapPropagator().Fields() {
+		if envKey(f) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextFromEnv returns a copy of ctx carrying the trace context passed in
+// the environment by InjectTraceEnv (TRACEPARENT, TRACESTATE, BAGGAGE), so
+// spans started from it join the parent process's trace. Without those
+// variables ctx is returned unchanged. It must be called after the
+// propagator is installed by NewTraceProvider.
+//
+// Example:
+//
+//	func main() {
+//	    ctx := context.Background()
+//	    _, cleanup := otelx.NewTraceProvider(ctx, "migrate")
+//	    defer cleanup()
+//
+//	    ctx = otelx.ContextFromEnv(ctx)
+//	    ctx, span := otelx.StartSpan(ctx)
+//	    defer span.End()
+//	}
+func ContextFromEnv(ctx context.Context) context.Context {
+	carrier := envCarrier{}
+	for _, f := range otel.GetTextMapPropagator().Fields() {
+		if v, ok := os.LookupEnv(envKey(f)); ok {
+			carrier[envKey(f)] = v
+		}
+	}
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
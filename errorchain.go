@@ -0,0 +1,73 @@
+package otelx
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxErrorChain bounds the number of errors RecordErrorChain records, as
+// protection against very deep or cyclic chains.
+const maxErrorChain = 32
+
+// RecordErrorChain records err and every error it wraps on span, so the
+// backend shows the real root cause rather than only the outermost wrapper
+// message, and marks the span as failed with err's message.
+//
+// The chain is walked depth first through errors.Unwrap and the
+// Unwrap() []error form used by errors.Join and fmt.Errorf with several %w
+// verbs. Each error becomes an "exception" event with exception.type and
+// exception.message, plus:
+//
+//   - exception.chain.depth: 0 for err, 1 for the errors it wraps, and so on
+//   - exception.chain.root: true for errors wrapping nothing, the root causes
+//
+// Example:
+//
+//	if err := repo.Save(ctx, order); err != nil {
+//	    otelx.RecordErrorChain(span, err)
+//	    return err
+//	}
+func RecordErrorChain(span trace.Span, err error) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	recorded := 0
+	var walk func(err error, depth int)
+	walk = func(err error, depth int) {
+		if recorded >= maxErrorChain {
+			return
+		}
+		recorded++
+
+		var causes []error
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			causes = u.Unwrap()
+		default:
+			if cause := errors.Unwrap(err); cause != nil {
+				causes = []error{cause}
+			}
+		}
+
+		span.AddEvent("exception", trace.WithAttributes(
+			attribute.String("exception.type", fmt.Sprintf("%T", err)),
+			attribute.String("exception.message", err.Error()),
+			attribute.Int("exception.chain.depth", depth),
+			attribute.Bool("exception.chain.root", len(causes) == 0),
+		))
+
+		for _, cause := range causes {
+			if cause != nil {
+				walk(cause, depth+1)
+			}
+		}
+	}
+	walk(err, 0)
+
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,147 @@
+package otelx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// NewLoggerProvider initializes the global OpenTelemetry LoggerProvider used
+// by EmitEvent.
+//
+// It follows the same rules as NewMeterProvider: it reuses the shared
+// collector connection, builds the standard service Resource, and falls back
+// to doing nothing when telemetry is disabled.
+//
+// Returns a cleanup function that flushes and shuts down the provider.
+//
+// Example:
+//
+//	shutdownEvents := otelx.NewLoggerProvider(ctx, "auth-service")
+//	defer shutdownEvents()
+func NewLoggerProvider(ctx context.Context, service string, opts ...Option) func() {
+	cfg := configure(opts)
+
+	emptyCleanup := func() {}
+	conn, err := initCollector(cfg)
+	if err != nil {
+		logf("failed to grpc connection: %v\n", err)
+		return emptyCleanup
+	}
+
+	logExporter, err := otlploggrpc.New(ctx, otlploggrpc.WithGRPCConn(conn))
+	if err != nil {
+		logf("failed to create log exporter: %v\n", err)
+		return emptyCleanup
+	}
+
+	res, err := newResource(ctx, service)
+	if err != nil {
+		logf("failed to create resource: %v\n", err)
+		return emptyCleanup
+	}
+
+	lp := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
+		sdklog.WithResource(res),
+	)
+	global.SetLoggerProvider(lp)
+
+	shutdown := registerShutdown("logs", lp.Shutdown)
+	return func() {
+		if err := shutdown(ctx); err != nil {
+			logf("error shutting down logger provider: %v", err)
+		}
+	}
+}
+
+// EmitEvent emits a structured event as an OpenTelemetry log-based event.
+//
+// The event carries the trace context of ctx, so backends can correlate it
+// with the active span, and payload is recorded as the event body. It lets
+// usage analytics and audit events flow through the same pipeline as traces.
+//
+// Payload values may be strings, booleans, integers, floats, byte slices,
+// slices and nested map[string]any; other types are recorded using their
+// fmt representation.
+//
+// Events are only exported once NewLoggerProvider has been called. The OTEL
+// events API is still incubating, so the emitted shape may change.
+//
+// Example:
+//
+//	otelx.EmitEvent(ctx, "user.signup", map[string]any{
+//	    "plan":    "pro",
+//	    "referrer": "newsletter",
+//	})
+func EmitEvent(ctx context.Context, name string, payload map[string]any) {
+	var rec otellog.Record
+	rec.SetEventName(name)
+	rec.SetTimestamp(time.Now())
+	rec.SetSeverity(otellog.SeverityInfo)
+	if payload != nil {
+		rec.SetBody(logValue(payload))
+	}
+
+	global.Logger(instrumentationName).Emit(ctx, rec)
+}
+
+// logValue converts v into an OTEL log Value.
+func logValue(v any) otellog.Value {
+	switch v := v.(type) {
+	case nil:
+		return otellog.Value{}
+	case string:
+		return otellog.StringValue(v)
+	case bool:
+		return otellog.BoolValue(v)
+	case int:
+		return otellog.IntValue(v)
+	case int32:
+		return otellog.Int64Value(int64(v))
+	case int64:
+		return otellog.Int64Value(v)
+	case uint32:
+		return otellog.Int64Value(int64(v))
+	case float32:
+		return otellog.Float64Value(float64(v))
+	case float64:
+		return otellog.Float64Value(v)
+	case []byte:
+		return otellog.BytesValue(v)
+	case []string:
+		vals := make([]otellog.Value, len(v))
+		for i, s := range v {
+			vals[i] = otellog.StringValue(s)
+		}
+		return otellog.SliceValue(vals...)
+	case []any:
+		vals := make([]otellog.Value, len(v))
+		for i, e := range v {
+			vals[i] = logValue(e)
+		}
+		return otellog.SliceValue(vals...)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		kvs := make([]otellog.KeyValue, 0, len(v))
+		for _, k := range keys {
+			kvs = append(kvs, otellog.KeyValue{Key: k, Value: logValue(v[k])})
+		}
+		return otellog.MapValue(kvs...)
+	case fmt.Stringer:
+		return otellog.StringValue(v.String())
+	default:
+		return otellog.StringValue(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,134 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// WithSpanExporters adds span exporters that receive the same spans as the
+// OTLP collector exporter.
+//
+// Each exporter gets its own BatchSpanProcessor, so a slow or failing
+// destination does not hold back the others. Spans are filtered and scrubbed
+// once before being fanned out. This makes it possible to export to two
+// collectors during a backend migration, or to the collector and stdout in
+// staging, purely through configuration.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithSpanExporters(secondCollectorExporter),
+//	)
+func WithSpanExporters(exporters ...sdktrace.SpanExporter) Option {
+	return func(c *config) {
+		c.spanExporters = append(c.spanExporters, exporters...)
+	}
+}
+
+// WithMetricExporters adds metric exporters that receive the same metrics as
+// the OTLP collector exporter. Each exporter is driven by its own
+// PeriodicReader.
+func WithMetricExporters(exporters ...sdkmetric.Exporter) Option {
+	return func(c *config) {
+		c.metricExporters = append(c.metricExporters, exporters...)
+	}
+}
+
+// WithStdoutExporters additionally writes spans and metrics as pretty-printed
+// JSON to stdout. It is intended for local development and staging, where
+// seeing telemetry next to application logs is convenient. The "local"
+// profile enables it by default (see WithProfile).
+func WithStdoutExporters() Option {
+	return func(c *config) {
+		c.stdout = true
+	}
+}
+
+// extraSpanExporters returns the exporters configured in addition to the OTLP
+// collector exporter.
+func (c config) extraSpanExporters() []sdktrace.SpanExporter {
+	exporters := append([]sdktrace.SpanExporter(nil), c.spanExporters...)
+	if c.stdout || activeProfile().stdout {
+		exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			logf("failed to create stdout span exporter: %v\n", err)
+		} else {
+			exporters = append(exporters, exp)
+		}
+	}
+	return exporters
+}
+
+// extraMetricExporters returns the exporters configured in addition to the
+// OTLP collector exporter.
+func (c config) extraMetricExporters() []sdkmetric.Exporter {
+	exporters := append([]sdkmetric.Exporter(nil), c.metricExporters...)
+	if c.stdout || activeProfile().stdout {
+		exp, err := stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+		if err != nil {
+			logf("failed to create stdout metric exporter: %v\n", err)
+		} else {
+			exporters = append(exporters, exp)
+		}
+	}
+	return exporters
+}
+
+// newSpanPipeline builds the span processor used by NewTraceProvider: spans
+// are filtered and scrubbed once, then fanned out to a BatchSpanProcessor per
+// exporter whose queue is tracked by the otelx self-telemetry.
+func newSpanPipeline(cfg config, exporters []sdktrace.SpanExporter) sdktrace.SpanProcessor {
+	batchers := make(multiSpanProcessor, 0, len(exporters))
+	for _, exp := range exporters {
+		batchers = append(batchers, newBatchSpanProcessor(exp, cfg.spanMemoryLimit))
+	}
+
+	var next sdktrace.SpanProcessor = batchers
+	if len(batchers) == 1 {
+		next = batchers[0]
+	}
+
+	return newFilterSpanProcessor(newScrubSpanProcessor(next, cfg.scrubRules))
+}
+
+// multiSpanProcessor forwards every call to each of its processors.
+type multiSpanProcessor []sdktrace.SpanProcessor
+
+var _ sdktrace.SpanProcessor = multiSpanProcessor(nil)
+
+// OnStart forwards s to every processor.
+func (m multiSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	for _, p := range m {
+		p.OnStart(parent, s)
+	}
+}
+
+// OnEnd forwards s to every processor.
+func (m multiSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	for _, p := range m {
+		p.OnEnd(s)
+	}
+}
+
+// Shutdown shuts down every processor and returns the joined errors.
+func (m multiSpanProcessor) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, p := range m {
+		errs = append(errs, p.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// ForceFlush flushes every processor and returns the joined errors.
+func (m multiSpanProcessor) ForceFlush(ctx context.Context) error {
+	var errs []error
+	for _, p := range m {
+		errs = append(errs, p.ForceFlush(ctx))
+	}
+	return errors.Join(errs...)
+}
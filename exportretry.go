@@ -0,0 +1,121 @@
+package otelx
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+)
+
+// Default OTLP exporter retry intervals, matching the exporters' own
+// defaults.
+const (
+	defaultRetryInitialInterval = 5 * time.Second
+	defaultRetryMaxInterval     = 30 * time.Second
+	defaultRetryMaxElapsedTime  = time.Minute
+)
+
+// ExportRetry is the retry policy of an OTLP exporter. Failed exports are
+// retried with exponential backoff starting at InitialInterval, capped at
+// MaxInterval, until MaxElapsedTime has passed. Zero durations keep the
+// defaults (5s, 30s and 1m).
+//
+// Shutdown waits for in-flight retries, so a short MaxElapsedTime bounds how
+// long shutdown can hang while the collector is degraded.
+type ExportRetry struct {
+	// Disabled turns retries off: failed exports are dropped immediately.
+	Disabled bool
+
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// WithTraceExportRetry sets the retry policy of the OTLP span exporter.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithTraceExportRetry(otelx.ExportRetry{MaxElapsedTime: 10 * time.Second}),
+//	    otelx.WithTraceExportTimeout(5*time.Second),
+//	)
+func WithTraceExportRetry(r ExportRetry) Option {
+	return func(c *config) {
+		c.traceRetry = &r
+	}
+}
+
+// WithMetricExportRetry sets the retry policy of the OTLP metric exporter.
+func WithMetricExportRetry(r ExportRetry) Option {
+	return func(c *config) {
+		c.metricRetry = &r
+	}
+}
+
+// WithTraceExportTimeout bounds each export of the OTLP span exporter,
+// retries included. It takes precedence over OTEL_EXPORTER_OTLP_TIMEOUT.
+func WithTraceExportTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.traceTimeout = d
+	}
+}
+
+// WithMetricExportTimeout bounds each export of the OTLP metric exporter,
+// retries included. It takes precedence over OTEL_EXPORTER_OTLP_TIMEOUT.
+func WithMetricExportTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.metricTimeout = d
+	}
+}
+
+// withDefaults returns r with zero durations replaced by the defaults.
+func (r ExportRetry) withDefaults() ExportRetry {
+	if r.InitialInterval <= 0 {
+		r.InitialInterval = defaultRetryInitialInterval
+	}
+	if r.MaxInterval <= 0 {
+		r.MaxInterval = defaultRetryMaxInterval
+	}
+	if r.MaxElapsedTime <= 0 {
+		r.MaxElapsedTime = defaultRetryMaxElapsedTime
+	}
+	return r
+}
+
+// traceExporterOptions returns the retry and timeout options of the OTLP span
+// exporter.
+func (c config) traceExporterOptions() []otlptracegrpc.Option {
+	var opts []otlptracegrpc.Option
+	if c.traceRetry != nil {
+		r := c.traceRetry.withDefaults()
+		opts = append(opts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
+			Enabled:         !r.Disabled,
+			InitialInterval: r.InitialInterval,
+			MaxInterval:     r.MaxInterval,
+			MaxElapsedTime:  r.MaxElapsedTime,
+		}))
+	}
+	if c.traceTimeout > 0 {
+		opts = append(opts, otlptracegrpc.WithTimeout(c.traceTimeout))
+	}
+	return opts
+}
+
+// metricExporterOptions returns the retry and timeout options of the OTLP
+// metric exporter.
+func (c config) metricExporterOptions() []otlpmetricgrpc.Option {
+	var opts []otlpmetricgrpc.Option
+	if c.metricRetry != nil {
+		r := c.metricRetry.withDefaults()
+		opts = append(opts, otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig{
+			Enabled:         !r.Disabled,
+			InitialInterval: r.InitialInterval,
+			MaxInterval:     r.MaxInterval,
+			MaxElapsedTime:  r.MaxElapsedTime,
+		}))
+	}
+	if c.metricTimeout > 0 {
+		opts = append(opts, otlpmetricgrpc.WithTimeout(c.metricTimeout))
+	}
+	return opts
+}
@@ -0,0 +1,59 @@
+package otelx
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextExtractor returns span attributes derived from values stored in ctx,
+// such as a tenant, request ID or shard.
+type ContextExtractor func(ctx context.Context) []attribute.KeyValue
+
+// WithContextExtractors registers extractors that StartSpan applies to every
+// span it starts, so cross-cutting values stored in the context are stamped on
+// every span without copying them by hand. Extractors run in order on the
+// parent context and must be cheap and safe for concurrent use.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithContextExtractors(func(ctx context.Context) []attribute.KeyValue {
+//	        if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+//	            return []attribute.KeyValue{attribute.String("tenant.id", tenant)}
+//	        }
+//	        return nil
+//	    }),
+//	)
+func WithContextExtractors(extractors ...ContextExtractor) Option {
+	return func(c *config) {
+		c.contextExtractors = append(c.contextExtractors, extractors...)
+	}
+}
+
+// RequestIDExtractor is a ContextExtractor recording the request ID set by
+// RequestIDMiddleware as the request.id attribute.
+func RequestIDExtractor(ctx context.Context) []attribute.KeyValue {
+	if id := RequestID(ctx); id != "" {
+		return []attribute.KeyValue{attribute.String("request.id", id)}
+	}
+	return nil
+}
+
+// extractedAttributes appends the span start option carrying the attributes
+// of the registered extractors for ctx to opts.
+func extractedAttributes(ctx context.Context, opts []trace.SpanStartOption) []trace.SpanStartOption {
+	if len(settings().contextExtractors) == 0 {
+		return opts
+	}
+
+	var attrs []attribute.KeyValue
+	for _, extract := range settings().contextExtractors {
+		attrs = append(attrs, extract(ctx)...)
+	}
+	if len(attrs) == 0 {
+		return opts
+	}
+	return append(opts, trace.WithAttributes(attrs...))
+}
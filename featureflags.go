@@ -0,0 +1,144 @@
+package otelx
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// featureFlagPrefix prefixes feature flag assignments in baggage and in
+// span and metric attribute keys.
+const featureFlagPrefix = "feature_flag."
+
+// FlagEvaluator returns the feature flag or experiment assignments (flag key
+// to variant) active for ctx, typically by querying a flag SDK.
+type FlagEvaluator func(ctx context.Context) map[string]string
+
+// WithFlagEvaluator registers fn as a source of feature flag assignments, in
+// addition to the ones carried in baggage (see ContextWithFeatureFlag).
+func WithFlagEvaluator(fn FlagEvaluator) Option {
+	return func(c *config) {
+		c.flagEvaluator = fn
+	}
+}
+
+// WithFeatureFlagDimensions records the variants of flags as request metric
+// dimensions (feature_flag.<flag>), so A/B latency comparisons can be made
+// directly on the request metrics. Only list flags with a few variants: each
+// one multiplies the number of series.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "checkout",
+//	    otelx.WithFeatureFlagDimensions("new-pricing"),
+//	)
+func WithFeatureFlagDimensions(flags ...string) Option {
+	return func(c *config) {
+		c.flagDimensions = flags
+	}
+}
+
+// ContextWithFeatureFlag returns a copy of ctx recording that flag resolved to
+// variant. The assignment is stored in baggage, so it propagates to
+// downstream services, and is recorded by the tracing middleware and
+// interceptors as the feature_flag.<flag> span attribute.
+//
+// Example:
+//
+//	ctx = otelx.ContextWithFeatureFlag(ctx, "new-pricing", "treatment")
+func ContextWithFeatureFlag(ctx context.Context, flag, variant string) context.Context {
+	member, err := baggage.NewMemberRaw(featureFlagPrefix+flag, variant)
+	if err != nil {
+		logf("invalid feature flag %q: %v\n", flag, err)
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		logf("error adding feature flag %q to baggage: %v\n", flag, err)
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// FeatureFlags returns the feature flag assignments active for ctx, from
+// baggage and from the evaluator registered with WithFlagEvaluator, which
+// takes precedence.
+func FeatureFlags(ctx context.Context) map[string]string {
+	var flags map[string]string
+	for _, m := range baggage.FromContext(ctx).Members() {
+		if flag, ok := strings.CutPrefix(m.Key(), featureFlagPrefix); ok {
+			if flags == nil {
+				flags = make(map[string]string)
+			}
+			flags[flag] = m.Value()
+		}
+	}
+
+	if settings().flagEvaluator != nil {
+		for flag, variant := range settings().flagEvaluator(ctx) {
+			if flags == nil {
+				flags = make(map[string]string)
+			}
+			flags[flag] = variant
+		}
+	}
+	return flags
+}
+
+// RecordFeatureFlags records the assignments active for ctx as
+// feature_flag.<flag> attributes on the span in ctx, for spans not created by
+// the otelx middleware and interceptors.
+func RecordFeatureFlags(ctx context.Context) {
+	if attrs := featureFlagAttributes(ctx); len(attrs) > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	}
+}
+
+// featureFlagAttributes returns the feature_flag.<flag> span attributes for
+// ctx.
+func featureFlagAttributes(ctx context.Context) []attribute.KeyValue {
+	flags := FeatureFlags(ctx)
+	attrs := make([]attribute.KeyValue, 0, len(flags))
+	for flag, variant := range flags {
+		attrs = append(attrs, attribute.String(featureFlagPrefix+flag, variant))
+	}
+	return attrs
+}
+
+// featureFlagDimensions encodes the variants of the WithFeatureFlagDimensions
+// flags for ctx as "flag=variant" pairs joined by newlines, the comparable
+// form stored in attrKey. Flags without an assignment are omitted.
+func featureFlagDimensions(ctx context.Context) string {
+	if len(settings().flagDimensions) == 0 {
+		return ""
+	}
+
+	flags := FeatureFlags(ctx)
+	pairs := make([]string, 0, len(settings().flagDimensions))
+	for _, flag := range settings().flagDimensions {
+		if variant, ok := flags[flag]; ok {
+			pairs = append(pairs, flag+"="+variant)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\n")
+}
+
+// flagDimensionAttributes decodes featureFlagDimensions output into metric
+// attributes.
+func flagDimensionAttributes(encoded string) []attribute.KeyValue {
+	if encoded == "" {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	for pair := range strings.SplitSeq(encoded, "\n") {
+		flag, variant, _ := strings.Cut(pair, "=")
+		attrs = append(attrs, attribute.String(featureFlagPrefix+flag, variant))
+	}
+	return attrs
+}
@@ -0,0 +1,173 @@
+package otelx
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultDroppedSpanPatterns match span names and request paths of health
+// checks, readiness/liveness probes and metrics scrapes. Spans matching them
+// are dropped by default because they carry no useful information and can make
+// up a large share of a service's traffic.
+//
+// The probe path must be the whole path, optionally preceded by the method as
+// in "GET /healthz" span names, so business routes ending in the same word
+// (/api/v1/orders/metrics) are kept.
+var defaultDroppedSpanPatterns = []string{
+	`(?i)^(?:[A-Z]+ )?/?(healthz?|healthcheck|readyz?|readiness|livez?|liveness|metrics)/?$`,
+	`(?i)^/?grpc\.health\.v1\.Health/`,
+}
+
+// pathAttributeKeys are span attributes whose values are matched against the
+// span name patterns in addition to the span name itself.
+var pathAttributeKeys = []attribute.Key{
+	"url.path",
+	"http.route",
+	"http.target",
+	"rpc.method",
+}
+
+// spanDropRule matches spans that should not be exported.
+//
+// When key is empty, pattern is matched against the span name and the path-like
+// attributes listed in pathAttributeKeys. Otherwise it is matched against the
+// value of attribute key only.
+type spanDropRule struct {
+	key     attribute.Key
+	pattern *regexp.Regexp
+}
+
+// matches reports whether span is selected by the rule.
+func (r spanDropRule) matches(span sdktrace.ReadOnlySpan) bool {
+	if r.key == "" {
+		if r.pattern.MatchString(span.Name()) {
+			return true
+		}
+		for _, kv := range span.Attributes() {
+			for _, key := range pathAttributeKeys {
+				if kv.Key == key && r.pattern.MatchString(kv.Value.Emit()) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, kv := range span.Attributes() {
+		if kv.Key == r.key && r.pattern.MatchString(kv.Value.Emit()) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileDropRules compiles patterns into rules for key, logging and skipping
+// invalid expressions so that a bad pattern never prevents startup.
+func compileDropRules(key attribute.Key, patterns []string) []spanDropRule {
+	rules := make([]spanDropRule, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			logf("invalid span drop pattern %q: %v\n", p, err)
+			continue
+		}
+		rules = append(rules, spanDropRule{key: key, pattern: re})
+	}
+	return rules
+}
+
+// activeDropRules holds the drop rules applied by every filterSpanProcessor.
+// It is set by NewTraceProvider and swapped atomically by Reload.
+var activeDropRules atomic.Pointer[[]spanDropRule]
+
+// setDropRules replaces the active drop rules.
+func setDropRules(rules []spanDropRule) {
+	activeDropRules.Store(&rules)
+}
+
+// filterSpanProcessor is an sdktrace.SpanProcessor that forwards finished spans
+// to next unless they match one of the active drop rules.
+//
+// Filtering happens in-process before spans reach the batch processor, which
+// reduces exporter and collector load without requiring collector-side
+// filtering for every service.
+type filterSpanProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+var _ sdktrace.SpanProcessor = (*filterSpanProcessor)(nil)
+
+// newFilterSpanProcessor wraps next with the active drop rules. The rules are
+// read for every span so they can be reloaded at runtime.
+func newFilterSpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	return &filterSpanProcessor{next: next}
+}
+
+// OnStart forwards span to the wrapped processor.
+func (p *filterSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd forwards s to the wrapped processor unless a drop rule matches it.
+func (p *filterSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if rules := activeDropRules.Load(); rules != nil {
+		for _, rule := range *rules {
+			if rule.matches(s) {
+				return
+			}
+		}
+	}
+	p.next.OnEnd(s)
+}
+
+// Shutdown shuts down the wrapped processor.
+func (p *filterSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush flushes the wrapped processor.
+func (p *filterSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// WithDroppedSpanNames replaces the regular expressions used to drop spans
+// before export.
+//
+// Each pattern is matched against the span name and against path-like
+// attributes (url.path, http.route, http.target, rpc.method). By default,
+// otelx drops health checks, readiness/liveness probes and metrics scrapes.
+// Calling WithDroppedSpanNames() with no patterns disables name-based dropping.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithDroppedSpanNames(`/healthz$`, `^GET /internal/`),
+//	)
+func WithDroppedSpanNames(patterns ...string) Option {
+	return func(c *config) {
+		rules := c.spanDropRules[:0:0]
+		for _, r := range c.spanDropRules {
+			if r.key != "" {
+				rules = append(rules, r)
+			}
+		}
+		c.spanDropRules = append(rules, compileDropRules("", patterns)...)
+	}
+}
+
+// WithDroppedSpanAttribute drops spans whose attribute key has a value
+// matching pattern. It can be used multiple times to add several rules.
+//
+// Example:
+//
+//	otelx.WithDroppedSpanAttribute("user_agent.original", `(?i)kube-probe`)
+func WithDroppedSpanAttribute(key, pattern string) Option {
+	return func(c *config) {
+		c.spanDropRules = append(c.spanDropRules,
+			compileDropRules(attribute.Key(key), []string{pattern})...)
+	}
+}
@@ -0,0 +1,140 @@
+package otelx
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDefaultDropRules(t *testing.T) {
+	rules := compileDropRules("", defaultDroppedSpanPatterns)
+
+	tests := []struct {
+		name    string
+		span    tracetest.SpanStub
+		dropped bool
+	}{
+		{name: "health span name", span: tracetest.SpanStub{Name: "GET /healthz"}, dropped: true},
+		{name: "readiness path", span: tracetest.SpanStub{Name: "GET", Attributes: []attribute.KeyValue{attribute.String("url.path", "/readyz")}}, dropped: true},
+		{name: "metrics scrape", span: tracetest.SpanStub{Name: "GET /metrics/"}, dropped: true},
+		{name: "grpc health check", span: tracetest.SpanStub{Name: "grpc.health.v1.Health/Check"}, dropped: true},
+		{name: "grpc health method", span: tracetest.SpanStub{Name: "rpc", Attributes: []attribute.KeyValue{attribute.String("rpc.method", "/grpc.health.v1.Health/Watch")}}, dropped: true},
+		{name: "business route ending in metrics", span: tracetest.SpanStub{Name: "GET /api/v1/orders/metrics"}},
+		{name: "health in another attribute", span: tracetest.SpanStub{Name: "GET", Attributes: []attribute.KeyValue{attribute.String("user_agent.original", "/healthz")}}},
+		{name: "business span", span: tracetest.SpanStub{Name: "GET /orders/{id}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dropped(rules, tt.span.Snapshot()); got != tt.dropped {
+				t.Errorf("dropped = %v, want %v", got, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestDropRuleOptions(t *testing.T) {
+	probe := tracetest.SpanStub{
+		Name:       "GET /internal/stats",
+		Attributes: []attribute.KeyValue{attribute.String("user_agent.original", "kube-probe/1.29")},
+	}.Snapshot()
+	health := tracetest.SpanStub{Name: "GET /healthz"}.Snapshot()
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]bool
+	}{
+		{
+			name: "defaults",
+			want: map[string]bool{"probe": false, "health": true},
+		},
+		{
+			name: "names replaced",
+			opts: []Option{WithDroppedSpanNames(`^GET /internal/`)},
+			want: map[string]bool{"probe": true, "health": false},
+		},
+		{
+			name: "names disabled",
+			opts: []Option{WithDroppedSpanNames()},
+			want: map[string]bool{"probe": false, "health": false},
+		},
+		{
+			name: "attribute rule",
+			opts: []Option{WithDroppedSpanAttribute("user_agent.original", `(?i)kube-probe`)},
+			want: map[string]bool{"probe": true, "health": true},
+		},
+		{
+			name: "attribute rule kept when names are replaced",
+			opts: []Option{
+				WithDroppedSpanAttribute("user_agent.original", `(?i)kube-probe`),
+				WithDroppedSpanNames(),
+			},
+			want: map[string]bool{"probe": true, "health": false},
+		},
+		{
+			name: "invalid pattern skipped",
+			opts: []Option{WithDroppedSpanNames(`(`, `^GET /internal/`)},
+			want: map[string]bool{"probe": true, "health": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			for _, opt := range tt.opts {
+				opt(&cfg)
+			}
+			spans := map[string]sdktrace.ReadOnlySpan{"probe": probe, "health": health}
+			for name, span := range spans {
+				if got := dropped(cfg.spanDropRules, span); got != tt.want[name] {
+					t.Errorf("%s dropped = %v, want %v", name, got, tt.want[name])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterSpanProcessor(t *testing.T) {
+	t.Cleanup(func() { activeDropRules.Store(nil) })
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newFilterSpanProcessor(rec)))
+	tracer := tp.Tracer("test")
+
+	end := func(name string) {
+		_, span := tracer.Start(context.Background(), name)
+		span.End()
+	}
+
+	end("GET /healthz")
+	setDropRules(compileDropRules("", defaultDroppedSpanPatterns))
+	end("GET /healthz")
+	end("GET /orders")
+	setDropRules(compileDropRules("", []string{`^GET /orders$`}))
+	end("GET /healthz")
+	end("GET /orders")
+
+	var got []string
+	for _, s := range rec.Ended() {
+		got = append(got, s.Name())
+	}
+	want := []string{"GET /healthz", "GET /orders", "GET /healthz"}
+	if !slices.Equal(got, want) {
+		t.Errorf("exported %v, want %v", got, want)
+	}
+}
+
+// dropped reports whether one of rules matches span.
+func dropped(rules []spanDropRule, span sdktrace.ReadOnlySpan) bool {
+	for _, r := range rules {
+		if r.matches(span) {
+			return true
+		}
+	}
+	return false
+}
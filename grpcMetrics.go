@@ -2,18 +2,22 @@ package otelx
 
 import (
 	"context"
-	"time"
+	"errors"
+	"io"
+	"strings"
+	"sync"
 
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/metric"
+	api "go.opentelemetry.io/otel/metric"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 // UnaryServerMetricsInterceptor returns a gRPC unary server interceptor that
 // records OpenTelemetry metrics for each unary RPC call.
 //
-// The interceptor measures two metrics for every RPC:
+// The interceptor measures three metrics for every RPC:
 //
 //  1. http_requests_total (counter)
 //     - method       : gRPC method name (e.g. /package.Service/Method)
@@ -23,6 +27,9 @@ import (
 //     - method       : same as above
 //     - status_code  : same as above
 //
+//  3. rpc_errors_total (counter), for non-OK status codes only
+//     - same attributes as above
+//
 // This allows Prometheus, Tempo, and Grafana dashboards to provide detailed
 // RPC performance metrics across all services.
 //
@@ -35,40 +42,53 @@ import (
 // This interceptor is lightweight and does not affect tracing; tracing must be
 // enabled separately via the OTEL trace provider.
 //
+// If telemetry is disabled when the interceptor is created, it simply invokes
+// the handler without measuring anything.
+//
 // Example:
 //
 //	server := grpc.NewServer(
 //	    grpc.UnaryInterceptor(otelx.UnaryServerMetricsInterceptor()),
 //	)
 func UnaryServerMetricsInterceptor() grpc.UnaryServerInterceptor {
+	if !metricsEnabled.Load() && !servicesEnabled.Load() {
+		return suppressedUnary(&suppressed.measurements, requestMeasurements)
+	}
+
 	return func(
 		ctx context.Context,
 		req any,
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (any, error) {
-		start := time.Now()
+		m := instrumentsFor(ctx)
+		if m == nil || telemetryPaused.Load() {
+			return handler(ctx, req)
+		}
+
+		start := settings().clock.Now()
 
 		resp, err := handler(ctx, req) // call the actual RPC
 
-		duration := time.Since(start).Seconds()
+		duration := since(start)
 
 		code := status.Code(err)
 
-		// Record metrics
-		metrics.RequestCounter.Add(ctx, 1,
-			metric.WithAttributes(
-				attribute.String("method", info.FullMethod),
-				attribute.Int("status_code", int(code)),
-			),
-		)
-
-		metrics.RequestHistogram.Record(ctx, duration,
-			metric.WithAttributes(
-				attribute.String("method", info.FullMethod),
-				attribute.Int("status_code", int(code)),
-			),
-		)
+		// Record metrics using a cached attribute set.
+		attrs := requestAttrs.get(attrKey{
+			method: info.FullMethod,
+			code:   int(code),
+			flags:  featureFlagDimensions(ctx),
+		})
+
+		m.RequestCounter.Add(ctx, 1, attrs)
+		m.RequestHistogram.Record(ctx, durationValue(duration), attrs)
+		if code != codes.OK {
+			m.RPCErrorCounter.Add(ctx, 1, attrs)
+		}
+
+		checkSLO(ctx, m, info.FullMethod, duration, attrs)
+		checkSlow(ctx, m, info.FullMethod, duration, attrs)
 
 		return resp, err
 	}
@@ -82,11 +102,17 @@ func UnaryServerMetricsInterceptor() grpc.UnaryServerInterceptor {
 //
 //  1. http_requests_total (counter)
 //  2. http_request_duration_seconds (histogram)
+//  3. rpc_errors_total (counter, non-OK codes only)
 //
 // The attributes added are:
 //   - method       : gRPC method full name (/pkg.Service/Method)
 //   - status_code  : gRPC status code
 //
+// rpc_server_active_streams{method} counts the streams in progress.
+//
+// With WithStreamMessageLatency, the interval between messages is also
+// recorded in rpc_stream_message_interval_seconds.
+//
 // Stream RPCs are measured from the time the handler starts until the handler
 // returns, which provides total session duration for the stream.
 //
@@ -97,34 +123,188 @@ func UnaryServerMetricsInterceptor() grpc.UnaryServerInterceptor {
 //	)
 //
 // This interceptor is designed to be used with NewMeterProvider() and is fully
-// OTEL-compliant.
+// OTEL-compliant. Like the unary variant, it degrades to a pass-through when
+// telemetry is disabled.
 func StreamServerMetricsInterceptor() grpc.StreamServerInterceptor {
+	if !metricsEnabled.Load() && !servicesEnabled.Load() {
+		return suppressedStream(&suppressed.measurements, requestMeasurements)
+	}
+
 	return func(
 		srv any,
 		ss grpc.ServerStream,
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
-		start := time.Now()
+		m := instrumentsFor(ss.Context())
+		if m == nil || telemetryPaused.Load() {
+			return handler(srv, ss)
+		}
+
+		start := settings().clock.Now()
+
+		active := api.WithAttributes(attribute.String("method", info.FullMethod))
+		m.ActiveStreams.Add(ss.Context(), 1, active)
+		defer m.ActiveStreams.Add(ss.Context(), -1, active)
+
+		stream := ss
+		if settings().streamMessageLatency {
+			stream = newMeasuredServerStream(ss, m, info.FullMethod, start)
+		}
 
-		err := handler(srv, ss) // call the actual stream handler
+		err := handler(srv, stream) // call the actual stream handler
 
-		duration := time.Since(start).Seconds()
+		duration := since(start)
 		code := status.Code(err)
 
-		metrics.RequestCounter.Add(ss.Context(), 1,
-			metric.WithAttributes(
-				attribute.String("method", info.FullMethod),
-				attribute.Int("status_code", int(code)),
-			),
-		)
-		metrics.RequestHistogram.Record(ss.Context(), duration,
-			metric.WithAttributes(
-				attribute.String("method", info.FullMethod),
-				attribute.Int("status_code", int(code)),
-			),
-		)
+		attrs := requestAttrs.get(attrKey{
+			method: info.FullMethod,
+			code:   int(code),
+			flags:  featureFlagDimensions(ss.Context()),
+		})
+
+		m.RequestCounter.Add(ss.Context(), 1, attrs)
+		m.RequestHistogram.Record(ss.Context(), durationValue(duration), attrs)
+		if code != codes.OK {
+			m.RPCErrorCounter.Add(ss.Context(), 1, attrs)
+		}
+
+		checkSLO(ss.Context(), m, info.FullMethod, duration, attrs)
+		checkSlow(ss.Context(), m, info.FullMethod, duration, attrs)
 
 		return err
 	}
 }
+
+// passthroughUnary is the unary interceptor used when telemetry is disabled.
+func passthroughUnary(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	return handler(ctx, req)
+}
+
+// passthroughStream is the stream interceptor used when telemetry is disabled.
+func passthroughStream(
+	srv any,
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, ss)
+}
+
+// UnaryClientMetricsInterceptor returns a gRPC unary client interceptor that
+// records outgoing calls in their own instruments, separate from the serving
+// metrics:
+//
+//  1. rpc_client_requests_total (counter)
+//  2. rpc_client_duration_seconds (histogram)
+//
+// with the attributes:
+//   - method          : gRPC method full name (/pkg.Service/Method)
+//   - status_code     : gRPC status code
+//   - rpc.service     : target service (pkg.Service)
+//   - server.address  : target of the client connection
+//
+// Example:
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithUnaryInterceptor(otelx.UnaryClientMetricsInterceptor()),
+//	)
+func UnaryClientMetricsInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		m := instrumentsFor(ctx)
+		if m == nil || telemetryPaused.Load() {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		start := settings().clock.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		recordClientCall(ctx, m, method, cc, since(start), err)
+		return err
+	}
+}
+
+// StreamClientMetricsInterceptor is the streaming counterpart of
+// UnaryClientMetricsInterceptor. Streams are measured from when they are
+// opened until the first error returned by RecvMsg (io.EOF for a stream that
+// completed normally); streams that are not read to completion are only
+// recorded if opening them fails.
+func StreamClientMetricsInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		m := instrumentsFor(ctx)
+		if m == nil || telemetryPaused.Load() {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		start := settings().clock.Now()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			recordClientCall(ctx, m, method, cc, since(start), err)
+			return cs, err
+		}
+
+		return &measuredClientStream{
+			ClientStream: cs,
+			record: func(err error) {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				recordClientCall(ctx, m, method, cc, since(start), err)
+			},
+		}, nil
+	}
+}
+
+// measuredClientStream calls record once RecvMsg reports the end of a client
+// stream. It backs both the stream metrics and the stream CLIENT span.
+type measuredClientStream struct {
+	grpc.ClientStream
+	once   sync.Once
+	record func(error)
+}
+
+// RecvMsg forwards to the underlying stream and records the call on the first
+// error.
+func (s *measuredClientStream) RecvMsg(msg any) error {
+	err := s.ClientStream.RecvMsg(msg)
+	if err != nil {
+		s.once.Do(func() { s.record(err) })
+	}
+	return err
+}
+
+// recordClientCall records an outgoing call to method on cc.
+func recordClientCall(ctx context.Context, m *Metrics, method string, cc *grpc.ClientConn, duration float64, err error) {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+
+	attrs := requestAttrs.get(attrKey{
+		method:        method,
+		code:          int(status.Code(err)),
+		rpcService:    service,
+		serverAddress: cc.Target(),
+	})
+
+	m.RPCClientCounter.Add(ctx, 1, attrs)
+	m.RPCClientHistogram.Record(ctx, durationValue(duration), attrs)
+}
@@ -0,0 +1,265 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier so trace
+// context can be injected into and extracted from RPC headers.
+type metadataCarrier metadata.MD
+
+// Get returns the first value for key.
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Set stores value under key, replacing existing values.
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys lists the keys stored in the carrier.
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// rpcAttributes returns the semantic convention attributes for fullMethod
+// (/package.Service/Method).
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return []attribute.KeyValue{
+		semconv.RPCSystemGRPC,
+		semconv.RPCService(service),
+		semconv.RPCMethod(method),
+	}
+}
+
+// isServerError reports whether code indicates a server-side failure. Per the
+// semantic conventions, client errors such as NotFound or InvalidArgument do
+// not mark SERVER spans as failed.
+func isServerError(code grpccodes.Code) bool {
+	switch code {
+	case grpccodes.Unknown, grpccodes.DeadlineExceeded, grpccodes.Unimplemented,
+		grpccodes.Internal, grpccodes.Unavailable, grpccodes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// endRPCSpan records the gRPC status and its details on span and ends it.
+// Client spans mark every non-OK status as an error; server spans only mark
+// server failures.
+func endRPCSpan(span trace.Span, err error, server bool) {
+	s := status.Convert(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(s.Code())))
+	recordStatusDetails(span, s)
+	if s.Code() != grpccodes.OK && (!server || isServerError(s.Code())) {
+		span.SetStatus(codes.Error, s.Message())
+	}
+	span.End()
+}
+
+// startServerSpan extracts the remote trace context from the incoming
+// metadata and starts a SERVER span for fullMethod with t.
+func startServerSpan(ctx context.Context, t trace.Tracer, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	return t.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(rpcAttributes(fullMethod)...),
+		trace.WithAttributes(capturedMetadataAttributes(ctx)...),
+		trace.WithAttributes(featureFlagAttributes(ctx)...),
+	)
+}
+
+// startClientSpan starts a CLIENT span for fullMethod with t and injects its
+// trace context into the outgoing metadata.
+func startClientSpan(ctx context.Context, t trace.Tracer, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := t.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rpcAttributes(fullMethod)...),
+	)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// UnaryServerTracingInterceptor returns a gRPC unary server interceptor that
+// continues the caller's trace and wraps each RPC in a SERVER span named after
+// the method (package.Service/Method).
+//
+// Spans carry rpc.system, rpc.service, rpc.method and rpc.grpc.status_code,
+// plus any metadata allowlisted with WithCapturedMetadata. Only server-side failures (Internal, Unavailable, ...) mark the span as
+// failed. Status details are recorded as rpc.grpc.status_detail events.
+//
+// Like the metrics interceptors, it must be created after NewTraceProvider()
+// and degrades to a pass-through when tracing is disabled:
+//
+//	grpc.NewServer(
+//	    grpc.ChainUnaryInterceptor(
+//	        otelx.UnaryServerTracingInterceptor(),
+//	        otelx.UnaryServerMetricsInterceptor(),
+//	    ),
+//	)
+func UnaryServerTracingInterceptor() grpc.UnaryServerInterceptor {
+	if !tracingEnabled.Load() && !servicesEnabled.Load() {
+		return suppressedUnary(&suppressed.spans, 1)
+	}
+
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		t := tracerFor(ctx)
+		if t == nil {
+			return handler(ctx, req)
+		}
+
+		ctx, span := startServerSpan(ctx, t, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+
+		endRPCSpan(span, err, true)
+		return resp, err
+	}
+}
+
+// StreamServerTracingInterceptor is the streaming counterpart of
+// UnaryServerTracingInterceptor. The SERVER span covers the whole stream and
+// is available to the handler through ss.Context().
+func StreamServerTracingInterceptor() grpc.StreamServerInterceptor {
+	if !tracingEnabled.Load() && !servicesEnabled.Load() {
+		return suppressedStream(&suppressed.spans, 1)
+	}
+
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		t := tracerFor(ss.Context())
+		if t == nil {
+			return handler(srv, ss)
+		}
+
+		ctx, span := startServerSpan(ss.Context(), t, info.FullMethod)
+
+		err := handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+
+		endRPCSpan(span, err, true)
+		return err
+	}
+}
+
+// UnaryClientTracingInterceptor returns a gRPC unary client interceptor that
+// wraps each outgoing call in a CLIENT span and propagates the trace context
+// through the request metadata.
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithUnaryInterceptor(otelx.UnaryClientTracingInterceptor()),
+//	)
+func UnaryClientTracingInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		t := tracerFor(ctx)
+		if t == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, span := startClientSpan(ctx, t, method)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		endRPCSpan(span, err, false)
+		return err
+	}
+}
+
+// StreamClientTracingInterceptor returns a gRPC stream client interceptor that
+// starts a CLIENT span when the stream is opened and propagates the trace
+// context through the request metadata. Like StreamClientMetricsInterceptor,
+// the span lasts until the first error returned by RecvMsg and records the
+// stream's final status (OK for io.EOF), or ends immediately if opening the
+// stream fails.
+func StreamClientTracingInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		t := tracerFor(ctx)
+		if t == nil {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		ctx, span := startClientSpan(ctx, t, method)
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endRPCSpan(span, err, false)
+			return cs, err
+		}
+
+		return &measuredClientStream{
+			ClientStream: cs,
+			record: func(err error) {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				endRPCSpan(span, err, false)
+			},
+		}, nil
+	}
+}
+
+// contextServerStream overrides the context of a grpc.ServerStream so handlers
+// observe values (such as the active span) added by interceptors.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overridden stream context.
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
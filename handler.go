@@ -0,0 +1,112 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HandlerFunc is an HTTP handler returning an error, adapted to http.Handler
+// with standard error handling and telemetry. When the handler returns an
+// error, ServeHTTP:
+//
+//   - maps it to a status code (see WithErrorStatusMapper)
+//   - records it on the request span: with RecordErrorChain for 5xx statuses,
+//     as an exception event without failing the span for 4xx ones
+//   - increments http_handler_errors_total{method, path, status_code}
+//   - responds with the status and its standard text if the handler has not
+//     written a response yet; error messages are never sent to the client
+//
+// Example:
+//
+//	mux.Handle("GET /orders/{id}", otelx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+//	    order, err := repo.Find(r.Context(), r.PathValue("id"))
+//	    if err != nil {
+//	        return fmt.Errorf("finding order: %w", err)
+//	    }
+//	    return json.NewEncoder(w).Encode(order)
+//	}))
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls f and handles the error it returns.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := NewResponseWriter(w)
+
+	err := f(rw, r)
+	if err == nil {
+		return
+	}
+
+	status := errorStatus(err)
+	ctx := r.Context()
+
+	span := trace.SpanFromContext(ctx)
+	if status >= http.StatusInternalServerError {
+		RecordErrorChain(span, err)
+	} else {
+		span.RecordError(err)
+	}
+
+	if m := instrumentsFor(ctx); m != nil && !telemetryPaused.Load() {
+		m.HandlerErrorCounter.Add(ctx, 1, requestAttrs.get(attrKey{
+			method: r.Method,
+			path:   metricPath(r, status),
+			code:   status,
+			http:   true,
+		}))
+	}
+
+	if !rw.Written() {
+		http.Error(rw, http.StatusText(status), status)
+	}
+}
+
+// StatusCoder is implemented by errors carrying their HTTP status code, which
+// the default error status mapper uses.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// WithErrorStatusMapper sets how HandlerFunc maps returned errors to HTTP
+// status codes. Returning 0 falls back to the default mapping:
+//
+//   - errors implementing StatusCoder anywhere in their chain use their code
+//   - context.DeadlineExceeded becomes 504 Gateway Timeout
+//   - anything else becomes 500 Internal Server Error
+//
+// Example:
+//
+//	otelx.WithErrorStatusMapper(func(err error) int {
+//	    switch {
+//	    case errors.Is(err, sql.ErrNoRows):
+//	        return http.StatusNotFound
+//	    case errors.Is(err, ErrForbidden):
+//	        return http.StatusForbidden
+//	    }
+//	    return 0
+//	})
+func WithErrorStatusMapper(mapper func(error) int) Option {
+	return func(c *config) {
+		c.errorStatusMapper = mapper
+	}
+}
+
+// errorStatus returns the HTTP status code for err.
+func errorStatus(err error) int {
+	if mapper := settings().errorStatusMapper; mapper != nil {
+		if status := mapper(err); status != 0 {
+			return status
+		}
+	}
+
+	var coder StatusCoder
+	switch {
+	case errors.As(err, &coder) && coder.StatusCode() != 0:
+		return coder.StatusCode()
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,131 @@
+package otelx
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// signalHealth tracks the export outcomes of one signal.
+type signalHealth struct {
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastFailure         *time.Time `json:"last_failure,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// exportHealth records the latest export results per signal for
+// HealthHandler.
+var exportHealth struct {
+	mu      sync.Mutex
+	signals map[string]*signalHealth
+}
+
+// droppedSpans counts spans dropped because the export queue was full or over
+// its memory limit, as reported by HealthHandler.
+var droppedSpans atomic.Int64
+
+// recordExportHealth stores the outcome of an export of signal.
+func recordExportHealth(signal string, err error) {
+	now := time.Now()
+
+	exportHealth.mu.Lock()
+	defer exportHealth.mu.Unlock()
+
+	if exportHealth.signals == nil {
+		exportHealth.signals = make(map[string]*signalHealth)
+	}
+	h, ok := exportHealth.signals[signal]
+	if !ok {
+		h = &signalHealth{}
+		exportHealth.signals[signal] = h
+	}
+
+	if err != nil {
+		h.LastFailure = &now
+		h.LastError = err.Error()
+		h.ConsecutiveFailures++
+		return
+	}
+	h.LastSuccess = &now
+	h.ConsecutiveFailures = 0
+}
+
+// healthReport is the document served by HealthHandler.
+type healthReport struct {
+	// Status is "ok", "degraded" (the collector is unreachable or the last
+	// export of a signal failed) or "disabled".
+	Status    string                  `json:"status"`
+	Collector exporterReport          `json:"collector"`
+	Signals   map[string]signalHealth `json:"signals"`
+	Dropped   map[string]int64        `json:"dropped"`
+
+	// Suppressed counts the spans and measurements not emitted while
+	// telemetry is disabled (see WithSuppressedTelemetryAccounting).
+	Suppressed map[string]int64 `json:"suppressed,omitempty"`
+}
+
+// HealthHandler returns an http.Handler reporting the health of the telemetry
+// pipeline as JSON: collector connectivity, the last successful and failed
+// export per signal, the number of spans dropped because the export queue
+// was full or over its memory limit and, with
+// WithSuppressedTelemetryAccounting, the telemetry not emitted while disabled.
+//
+// The response is always 200 OK so broken telemetry never fails liveness or
+// readiness probes; dashboards should look at the status field instead.
+//
+//	mux.Handle("/health/telemetry", otelx.HealthHandler())
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := newHealthReport()
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	})
+}
+
+// newHealthReport collects the current pipeline health.
+func newHealthReport() healthReport {
+	pipelineInfo.mu.RLock()
+	report := healthReport{
+		Status: "ok",
+		Collector: exporterReport{
+			Endpoint: pipelineInfo.endpoint,
+			State:    "not connected",
+		},
+		Signals:    make(map[string]signalHealth),
+		Dropped:    map[string]int64{"spans": droppedSpans.Load()},
+		Suppressed: suppressedReport(),
+	}
+	pipelineInfo.mu.RUnlock()
+
+	if !IsEnabled() || (grpcConnection == nil && !localStandalone(*settings())) {
+		report.Status = "disabled"
+		return report
+	}
+
+	if conn := grpcConnection; conn != nil {
+		state := conn.GetState()
+		report.Collector.State = state.String()
+		if state == connectivity.TransientFailure || state == connectivity.Shutdown {
+			report.Status = "degraded"
+		}
+	}
+
+	exportHealth.mu.Lock()
+	for signal, h := range exportHealth.signals {
+		report.Signals[signal] = *h
+		if h.ConsecutiveFailures > 0 {
+			report.Status = "degraded"
+		}
+	}
+	exportHealth.mu.Unlock()
+
+	return report
+}
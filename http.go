@@ -7,7 +7,9 @@ import (
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPClient returns a new *http.Client configured with OpenTelemetry tracing support.
@@ -20,6 +22,13 @@ import (
 //
 // The client uses a 20-second timeout by default.
 //
+// Requests marked with ContextWithRetryAttempt record their attempt on the
+// span (http.request.resend_count) and a retried="true"/"false" dimension on
+// the request metrics, quantifying how much downstream flakiness retries hide.
+//
+// Request and response body sizes are recorded per target host in
+// http_client_request_size_bytes and http_client_response_size_bytes.
+//
 // Example:
 //
 //	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com", body)
@@ -30,14 +39,76 @@ import (
 //	}
 //	defer resp.Body.Close()
 //
+// ClientOptions customize the client spans, for example their names:
+//
+//	client := otelx.HTTPClient(ctx, req, otelx.WithSpanNameFormatter(otelx.MethodHostPathSpanName))
+//
 // Note: You must call this function *before* sending the request to ensure
 // trace propagation headers are properly included.
-func HTTPClient(ctx context.Context, req *http.Request) *http.Client {
+func HTTPClient(ctx context.Context, req *http.Request, opts ...ClientOption) *http.Client {
 	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 	return &http.Client{
-		Timeout:   20 * time.Second,
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
+		Timeout:   defaultHTTPTimeout,
+		Transport: clientTransport(http.DefaultTransport, clientOptions(opts)...),
+	}
+}
+
+// ClientOption customizes the client spans of HTTPClient and DoRequest.
+type ClientOption func(*[]otelhttp.Option)
+
+// WithSpanNameFormatter names client spans with formatter instead of the
+// default "HTTP GET", which makes span lists unreadable when a service calls
+// many targets. MethodHostPathSpanName is a ready-made formatter.
+func WithSpanNameFormatter(formatter func(r *http.Request) string) ClientOption {
+	return func(opts *[]otelhttp.Option) {
+		*opts = append(*opts, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return formatter(r)
+		}))
+	}
+}
+
+// MethodHostPathSpanName names client spans "GET payments-api /v1/charges".
+// Paths embedding IDs create a span name per ID; use a formatter mapping
+// them to route templates for such targets.
+func MethodHostPathSpanName(r *http.Request) string {
+	return r.Method + " " + r.URL.Hostname() + " " + r.URL.Path
+}
+
+// WithClientFilter skips the client span of requests for which filter returns
+// false; trace context is still propagated. Several filters must all accept a
+// request for it to be traced.
+//
+// Example:
+//
+//	otelx.WithClientFilter(func(r *http.Request) bool {
+//	    return r.URL.Host != "metadata.google.internal"
+//	})
+func WithClientFilter(filter func(r *http.Request) bool) ClientOption {
+	return func(opts *[]otelhttp.Option) {
+		*opts = append(*opts, otelhttp.WithFilter(filter))
+	}
+}
+
+// clientOptions returns the otelhttp options of opts.
+func clientOptions(opts []ClientOption) []otelhttp.Option {
+	var out []otelhttp.Option
+	for _, opt := range opts {
+		opt(&out)
 	}
+	return out
+}
+
+// defaultHTTPTimeout is the timeout of clients returned by HTTPClient.
+const defaultHTTPTimeout = 20 * time.Second
+
+// clientTransport returns the instrumented transport used by HTTPClient and
+// DoRequestWithTimeout, sending requests through next, with opts added to the
+// otelhttp options.
+func clientTransport(next http.RoundTripper, opts ...otelhttp.Option) http.RoundTripper {
+	opts = append([]otelhttp.Option{
+		otelhttp.WithMetricAttributesFn(retryMetricAttributes),
+	}, opts...)
+	return otelhttp.NewTransport(sizeTransport{next: resendTransport{next: next}}, opts...)
 }
 
 // DoRequest executes an HTTP request with OpenTelemetry tracing and context propagation.
@@ -69,7 +140,29 @@ func HTTPClient(ctx context.Context, req *http.Request) *http.Client {
 // Return values:
 //   - *http.Response: the HTTP response returned by the server
 //   - error: if the request fails or the context is canceled
-func DoRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
-	client := HTTPClient(ctx, req)
+func DoRequest(ctx context.Context, req *http.Request, opts ...ClientOption) (*http.Response, error) {
+	client := HTTPClient(ctx, req, opts...)
+	return client.Do(req)
+}
+
+// DoRequestWithTimeout is DoRequest with a timeout of d instead of the default
+// 20 seconds, for calls that need a shorter or longer deadline than the rest.
+// A d of zero disables the timeout. The timeout is recorded in seconds on the
+// client span as http.request.timeout.
+//
+// Example:
+//
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://search.internal/q", nil)
+//	resp, err := otelx.DoRequestWithTimeout(ctx, req, 500*time.Millisecond)
+func DoRequestWithTimeout(ctx context.Context, req *http.Request, d time.Duration, opts ...ClientOption) (*http.Response, error) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	client := &http.Client{
+		Timeout: d,
+		Transport: clientTransport(http.DefaultTransport, append(clientOptions(opts),
+			otelhttp.WithSpanOptions(trace.WithAttributes(
+				attribute.Float64("http.request.timeout", d.Seconds()),
+			)),
+		)...),
+	}
 	return client.Do(req)
 }
@@ -0,0 +1,153 @@
+package otelx
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeterministicIDGenerator is an sdktrace.IDGenerator that produces a
+// reproducible sequence of trace and span IDs from a fixed seed.
+//
+// Two generators created with the same seed always yield the same IDs in the
+// same order, which makes exported spans stable across test runs.
+//
+// It must not be used in production: the IDs are predictable and are not
+// suitable for distinguishing traces across services.
+type DeterministicIDGenerator struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+var _ sdktrace.IDGenerator = (*DeterministicIDGenerator)(nil)
+
+// NewDeterministicIDGenerator returns a DeterministicIDGenerator seeded with
+// seed.
+//
+// Example:
+//
+//	tp, cleanup := otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithIDGenerator(otelx.NewDeterministicIDGenerator(1)),
+//	)
+//	defer cleanup()
+func NewDeterministicIDGenerator(seed int64) *DeterministicIDGenerator {
+	return &DeterministicIDGenerator{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// NewIDs returns a new trace ID and span ID.
+func (g *DeterministicIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var tid trace.TraceID
+	for !tid.IsValid() {
+		binary.BigEndian.PutUint64(tid[:8], g.rand.Uint64())
+		binary.BigEndian.PutUint64(tid[8:], g.rand.Uint64())
+	}
+
+	return tid, g.newSpanID()
+}
+
+// NewSpanID returns a new span ID for a span belonging to traceID.
+func (g *DeterministicIDGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.newSpanID()
+}
+
+// newSpanID draws the next non-zero span ID. The caller must hold g.mu.
+func (g *DeterministicIDGenerator) newSpanID() trace.SpanID {
+	var sid trace.SpanID
+	for !sid.IsValid() {
+		binary.BigEndian.PutUint64(sid[:], g.rand.Uint64())
+	}
+	return sid
+}
+
+// randomSpanID returns a random non-zero span ID.
+func randomSpanID() trace.SpanID {
+	var sid trace.SpanID
+	for !sid.IsValid() {
+		binary.BigEndian.PutUint64(sid[:], randv2.Uint64())
+	}
+	return sid
+}
+
+// XRayIDGenerator is an sdktrace.IDGenerator producing trace IDs in the AWS
+// X-Ray format: the first 4 bytes hold the start time in Unix seconds and the
+// remaining 12 bytes are random. Use it when spans are exported to X-Ray,
+// which rejects traces whose ID does not encode a recent timestamp.
+type XRayIDGenerator struct{}
+
+var _ sdktrace.IDGenerator = XRayIDGenerator{}
+
+// NewXRayIDGenerator returns an XRayIDGenerator.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithIDGenerator(otelx.NewXRayIDGenerator()),
+//	)
+func NewXRayIDGenerator() XRayIDGenerator {
+	return XRayIDGenerator{}
+}
+
+// NewIDs returns a new X-Ray compatible trace ID and a random span ID.
+func (XRayIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	var tid trace.TraceID
+	binary.BigEndian.PutUint32(tid[:4], uint32(settings().clock.Now().Unix()))
+	binary.BigEndian.PutUint32(tid[4:8], randv2.Uint32())
+	binary.BigEndian.PutUint64(tid[8:], randv2.Uint64())
+	return tid, randomSpanID()
+}
+
+// NewSpanID returns a random span ID.
+func (XRayIDGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	return randomSpanID()
+}
+
+// ShardIDGenerator is an sdktrace.IDGenerator embedding a fixed shard prefix
+// in the first two bytes of every trace ID, the remaining bytes being random.
+// It lets storage and routing layers locate a trace's shard from its ID
+// alone.
+type ShardIDGenerator struct {
+	shard uint16
+}
+
+var _ sdktrace.IDGenerator = ShardIDGenerator{}
+
+// NewShardIDGenerator returns a ShardIDGenerator for shard.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithIDGenerator(otelx.NewShardIDGenerator(42)),
+//	)
+func NewShardIDGenerator(shard uint16) ShardIDGenerator {
+	return ShardIDGenerator{shard: shard}
+}
+
+// NewIDs returns a new trace ID prefixed with the shard and a random span ID.
+func (g ShardIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	var tid trace.TraceID
+	for !tid.IsValid() {
+		binary.BigEndian.PutUint16(tid[:2], g.shard)
+		binary.BigEndian.PutUint16(tid[2:4], uint16(randv2.Uint32()))
+		binary.BigEndian.PutUint32(tid[4:8], randv2.Uint32())
+		binary.BigEndian.PutUint64(tid[8:], randv2.Uint64())
+	}
+	return tid, randomSpanID()
+}
+
+// NewSpanID returns a random span ID.
+func (g ShardIDGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	return randomSpanID()
+}
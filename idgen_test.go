@@ -0,0 +1,65 @@
+package otelx
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDeterministicIDGenerator(t *testing.T) {
+	tests := []struct {
+		name      string
+		seedA     int64
+		seedB     int64
+		wantEqual bool
+	}{
+		{name: "same seed", seedA: 1, seedB: 1, wantEqual: true},
+		{name: "same zero seed", seedA: 0, seedB: 0, wantEqual: true},
+		{name: "different seeds", seedA: 1, seedB: 2, wantEqual: false},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewDeterministicIDGenerator(tt.seedA)
+			b := NewDeterministicIDGenerator(tt.seedB)
+
+			for i := 0; i < 5; i++ {
+				tidA, sidA := a.NewIDs(ctx)
+				tidB, sidB := b.NewIDs(ctx)
+				childA := a.NewSpanID(ctx, tidA)
+				childB := b.NewSpanID(ctx, tidB)
+
+				for _, id := range []interface{ IsValid() bool }{tidA, sidA, childA} {
+					if !id.IsValid() {
+						t.Fatalf("call %d: invalid ID %v", i, id)
+					}
+				}
+				equal := tidA == tidB && sidA == sidB && childA == childB
+				if equal != tt.wantEqual {
+					t.Fatalf("call %d: IDs equal = %v, want %v (%v/%v/%v vs %v/%v/%v)",
+						i, equal, tt.wantEqual, tidA, sidA, childA, tidB, sidB, childB)
+				}
+			}
+		})
+	}
+}
+
+func TestDeterministicIDGeneratorUnique(t *testing.T) {
+	ctx := context.Background()
+	gen := NewDeterministicIDGenerator(42)
+
+	traces := make(map[trace.TraceID]bool)
+	spans := make(map[trace.SpanID]bool)
+	for i := 0; i < 1000; i++ {
+		tid, sid := gen.NewIDs(ctx)
+		if traces[tid] {
+			t.Fatalf("trace ID %v repeated", tid)
+		}
+		if spans[sid] {
+			t.Fatalf("span ID %v repeated", sid)
+		}
+		traces[tid], spans[sid] = true, true
+	}
+}
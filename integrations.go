@@ -0,0 +1,75 @@
+package otelx
+
+import (
+	"context"
+	"time"
+
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// globalMeter is the meter of the global MeterProvider installed by
+// NewMeterProvider.
+var globalMeter api.Meter
+
+// TracerFor returns the tracer otelx's instrumentation uses for ctx: the
+// Service's if ctx carries one (see ContextWithService), otherwise the global
+// tracer. It returns a no-op tracer when neither is available or telemetry is
+// paused.
+//
+// It lets integrations living in their own modules, such as
+// github.com/edr3x/otelx/oteltemporal and github.com/edr3x/otelx/otelaws,
+// record into the same pipeline as the middleware and interceptors.
+func TracerFor(ctx context.Context) trace.Tracer {
+	if t := tracerFor(ctx); t != nil && !telemetryPaused.Load() {
+		return t
+	}
+	return noop.NewTracerProvider().Tracer("noop")
+}
+
+// MeterFor returns the meter otelx's instrumentation records into for ctx,
+// the Service's or the global one, and whether metrics are being recorded. It
+// returns false when neither is available or telemetry is paused.
+//
+// Instruments are created once per meter; integrations should cache them by
+// meter and name them with InstrumentName and InstrumentDescription so
+// WithMetricPrefix and WithInstrumentName apply:
+//
+//	meter, ok := otelx.MeterFor(ctx)
+//	if !ok {
+//	    return
+//	}
+//	inst := instrumentsFor(meter)
+//	inst.duration.Record(ctx, otelx.Now().Sub(start).Seconds())
+func MeterFor(ctx context.Context) (api.Meter, bool) {
+	if telemetryPaused.Load() {
+		return nil, false
+	}
+	if s := ServiceFromContext(ctx); s != nil {
+		return s.meter, true
+	}
+	if metricsEnabled.Load() {
+		return globalMeter, true
+	}
+	return nil, false
+}
+
+// InstrumentName returns the name of the otelx instrument called name by
+// default, honoring WithMetricPrefix and WithInstrumentName.
+func InstrumentName(name string) string {
+	return instrumentName(name)
+}
+
+// InstrumentDescription returns the description option of the otelx
+// instrument called name by default, honoring WithInstrumentDescription and
+// defaulting to def.
+func InstrumentDescription(name, def string) api.InstrumentOption {
+	return instrumentDescription(name, def)
+}
+
+// Now returns the current time of the clock used to measure durations (see
+// WithClock).
+func Now() time.Time {
+	return settings().clock.Now()
+}
@@ -0,0 +1,69 @@
+package otelx
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// logInterval is how often each distinct message may be logged.
+const logInterval = time.Minute
+
+// maxLoggedMessages bounds the number of distinct messages tracked; older
+// entries are forgotten when it is reached.
+const maxLoggedMessages = 256
+
+// logEntry tracks when a message was last logged and how many identical
+// messages were suppressed since.
+type logEntry struct {
+	last       time.Time
+	suppressed int
+}
+
+// logLimiter deduplicates otelx's internal logs.
+var logLimiter = struct {
+	mu      sync.Mutex
+	entries map[string]*logEntry
+}{entries: make(map[string]*logEntry)}
+
+// logf logs like log.Printf, but each distinct message at most once per
+// logInterval. The next time a suppressed message is logged, it reports how
+// many identical messages were dropped, so a flapping collector does not
+// flood stdout with the same export error.
+func logf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	now := time.Now()
+
+	logLimiter.mu.Lock()
+	e, ok := logLimiter.entries[msg]
+	if ok && now.Sub(e.last) < logInterval {
+		e.suppressed++
+		logLimiter.mu.Unlock()
+		return
+	}
+	if !ok {
+		if len(logLimiter.entries) >= maxLoggedMessages {
+			clear(logLimiter.entries)
+		}
+		e = &logEntry{}
+		logLimiter.entries[msg] = e
+	}
+	suppressed := e.suppressed
+	e.last, e.suppressed = now, 0
+	logLimiter.mu.Unlock()
+
+	if suppressed > 0 {
+		log.Printf("%s (%d identical messages suppressed)\n", trimNewline(msg), suppressed)
+		return
+	}
+	log.Print(msg)
+}
+
+// trimNewline removes a single trailing newline from s.
+func trimNewline(s string) string {
+	if n := len(s); n > 0 && s[n-1] == '\n' {
+		return s[:n-1]
+	}
+	return s
+}
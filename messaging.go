@@ -0,0 +1,57 @@
+package otelx
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartProducerSpan starts a PRODUCER span for publishing a message to
+// destination (topic, queue or exchange) on the given messaging system
+// (e.g. "kafka", "rabbitmq", "aws_sqs").
+//
+// The span is named "publish <destination>" and carries the messaging.*
+// semantic convention attributes so backends can link producers and
+// consumers in the service graph. Inject the returned context into the
+// message headers with the global propagator:
+//
+//	ctx, span := otelx.StartProducerSpan(ctx, "kafka", "orders")
+//	defer span.End()
+//	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+func StartProducerSpan(ctx context.Context, system, destination string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return startMessagingSpan(ctx, trace.SpanKindProducer, "publish", system, destination, opts)
+}
+
+// StartConsumerSpan starts a CONSUMER span for processing a message received
+// from destination on the given messaging system.
+//
+// ctx should already carry the producer's trace context, typically extracted
+// from the message headers:
+//
+//	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+//	ctx, span := otelx.StartConsumerSpan(ctx, "kafka", "orders")
+//	defer span.End()
+func StartConsumerSpan(ctx context.Context, system, destination string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return startMessagingSpan(ctx, trace.SpanKindConsumer, "process", system, destination, opts)
+}
+
+// startMessagingSpan starts a span of the given kind for a messaging
+// operation.
+func startMessagingSpan(
+	ctx context.Context,
+	kind trace.SpanKind,
+	operation, system, destination string,
+	opts []trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	t := tracerFor(ctx)
+	if t == nil {
+		return StartSpan(ctx, opts...)
+	}
+
+	opts = append([]trace.SpanStartOption{
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(MessagingAttrs(system, destination, operation)...),
+	}, opts...)
+
+	return t.Start(ctx, operation+" "+destination, opts...)
+}
@@ -1,11 +1,11 @@
 package otelx
 
 import (
+	"bufio"
+	"io"
+	"net"
 	"net/http"
 	"time"
-
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/metric"
 )
 
 // responseWriter is a thin wrapper around http.ResponseWriter that captures
@@ -17,20 +17,56 @@ import (
 //
 // By default, the status code is initialized to http.StatusOK until WriteHeader
 // is explicitly called by the handler.
+//
+// The optional http.Flusher, http.Hijacker, http.Pusher and io.ReaderFrom
+// interfaces are forwarded to the wrapped ResponseWriter, and Unwrap exposes it
+// to http.ResponseController, so WebSocket upgrades, SSE flushing and sendfile
+// keep working behind the middleware.
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode   int
+	wroteHeader  bool
+	bytesWritten int64
+
+	// firstWrite is when the response started, used for slow request
+	// timing breakdowns.
+	firstWrite time.Time
 }
 
-// NewResponseWriter creates a new responseWriter that wraps an existing
-// http.ResponseWriter while defaulting the status code to http.StatusOK.
+// ResponseWriter is an http.ResponseWriter that tracks what was written, as
+// returned by NewResponseWriter. Other middleware can implement it so that
+// otelx reuses their writer instead of wrapping it again.
+type ResponseWriter interface {
+	http.ResponseWriter
+
+	// Status returns the status code written, http.StatusOK by default.
+	Status() int
+
+	// BytesWritten returns the number of response body bytes written.
+	BytesWritten() int64
+
+	// Written reports whether the response has started.
+	Written() bool
+}
+
+// NewResponseWriter wraps w to track its status code, defaulting to
+// http.StatusOK, and body size.
+//
+// If w already implements ResponseWriter, for example because an outer otelx
+// middleware or a logging middleware wrapped it, w is returned as is, so
+// composed middlewares share one writer and agree on the status code.
 //
 // This helper is typically used in HTTP middleware to capture both the
 // handler response and any modifications to the HTTP status code.
-func NewResponseWriter(w http.ResponseWriter) *responseWriter {
-	return &responseWriter{w, http.StatusOK}
+func NewResponseWriter(w http.ResponseWriter) ResponseWriter {
+	if rw, ok := w.(ResponseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 }
 
+var _ ResponseWriter = (*responseWriter)(nil)
+
 // WriteHeader updates the tracked status code and forwards the call
 // to the underlying ResponseWriter.
 //
@@ -38,10 +74,102 @@ func NewResponseWriter(w http.ResponseWriter) *responseWriter {
 // sets the actual HTTP response status. This wrapper preserves the semantics
 // by only storing the first status used.
 func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.started()
+	}
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Write records that the response has started (implicitly with the current
+// status) and forwards b to the underlying ResponseWriter.
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.started()
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytesWritten += int64(n)
+	return n, err
+}
+
+// started records that the response started with the current status.
+func (rw *responseWriter) started() {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.firstWrite = settings().clock.Now()
+	}
+}
+
+// Written reports whether the response has started.
+func (rw *responseWriter) Written() bool {
+	return rw.wroteHeader
+}
+
+// BytesWritten returns the number of response body bytes written so far.
+func (rw *responseWriter) BytesWritten() int64 {
+	return rw.bytesWritten
+}
+
+// Unwrap returns the underlying ResponseWriter, so http.ResponseController
+// can reach optional capabilities such as deadlines and full-duplex mode.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Flush sends buffered data to the client if the underlying ResponseWriter
+// supports it, keeping server-sent events working behind the middleware.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		rw.started()
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, for example for
+// WebSocket upgrades. The request is then recorded with status 101 unless a
+// status was already written. It returns http.ErrNotSupported if the
+// underlying ResponseWriter cannot be hijacked.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if !rw.wroteHeader {
+		rw.statusCode = http.StatusSwitchingProtocols
+		rw.started()
+	}
+	return h.Hijack()
+}
+
+// Push initiates an HTTP/2 server push if the underlying ResponseWriter
+// supports it, and returns http.ErrNotSupported otherwise.
+func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := rw.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// ReadFrom copies from src using the underlying ResponseWriter's ReadFrom
+// when available, preserving the sendfile optimization for static files.
+func (rw *responseWriter) ReadFrom(src io.Reader) (int64, error) {
+	rw.started()
+
+	var n int64
+	var err error
+	if rf, ok := rw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		// Hide ReadFrom so io.Copy does not call back into rw.
+		n, err = io.Copy(writerOnly{rw.ResponseWriter}, src)
+	}
+	rw.bytesWritten += n
+	return n, err
+}
+
+// writerOnly hides every method of an io.Writer but Write.
+type writerOnly struct {
+	io.Writer
+}
+
 // Status returns the final HTTP status code written for the request.
 //
 // It is used after the wrapped handler finishes so middleware can record
@@ -53,16 +181,20 @@ func (rw *responseWriter) Status() int {
 // MetricsMiddleware instruments every HTTP request with OpenTelemetry
 // metrics using the global otelx.Metrics instance.
 //
-// It records two metrics per request:
+// It records three metrics per request:
 //
 //  1. http_requests_total (counter)
 //     - method        (e.g., GET, POST)
-//     - path          (full URL path)
+//     - path          (full URL path, or "<unmatched>" for 404s that no
+//     route matched)
 //     - status_code   (integer)
 //
 //  2. http_request_duration_seconds (histogram)
 //     - same attributes as above
 //
+//  3. http_errors_total (counter), for 5xx responses only
+//     - same attributes as above
+//
 // This middleware must be registered *after* calling
 // NewMeterProvider(), otherwise the metrics instruments
 // will not be initialized.
@@ -76,36 +208,88 @@ func (rw *responseWriter) Status() int {
 //	    otelx.MetricsMiddleware(mux),
 //	)
 //
-// Each request is timed precisely and attributes are attached via
-// metric.WithAttributes, matching OTEL best practices for HTTP server metrics.
+// When telemetry is disabled (NewMeterProvider was not called or failed), the
+// middleware returns next unchanged: no response writer wrapping, no
+// timestamps and no allocations are added to the request path. With
+// WithSuppressedTelemetryAccounting it only counts the requests.
+//
+// Each request is timed precisely and attributes are attached via a cached
+// metric.WithAttributeSet, so the hot path does not rebuild attributes for
+// method/path/status combinations it has already seen.
 func MetricsMiddleware(next http.Handler) http.Handler {
+	if !metricsEnabled.Load() && !servicesEnabled.Load() {
+		return suppressedHandler(next, &suppressed.measurements, requestMeasurements)
+	}
+
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
+		m := instrumentsFor(ctx)
+		if m == nil || telemetryPaused.Load() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if settings().syntheticMode == SyntheticExclude && isSynthetic(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		rw := NewResponseWriter(w)
-		start := time.Now()
+		start := settings().clock.Now()
 
 		next.ServeHTTP(rw, r)
+		recordRoute(r)
+
+		duration := since(start)
+
+		userAgent, clientVersion := clientDimensions(r)
+
+		// Reuse a precomputed attribute set for this method/path/status.
+		attrs := requestAttrs.get(attrKey{
+			method:        r.Method,
+			path:          metricPath(r, rw.Status()),
+			code:          rw.Status(),
+			http:          true,
+			userAgent:     userAgent,
+			clientVersion: clientVersion,
+			flags:         featureFlagDimensions(ctx),
+			synthetic:     syntheticDimension(r),
+		})
+
+		m.RequestCounter.Add(ctx, 1, attrs)
+		m.RequestHistogram.Record(ctx, durationValue(duration), attrs)
+		if rw.Status() >= http.StatusInternalServerError {
+			m.HTTPErrorCounter.Add(ctx, 1, attrs)
+		}
 
-		duration := time.Since(start).Seconds()
-
-		// Record metrics correctly using metric.WithAttributes
-		metrics.RequestCounter.Add(ctx, 1,
-			metric.WithAttributes(
-				attribute.String("method", r.Method),
-				attribute.String("path", r.URL.Path),
-				attribute.Int("status_code", rw.Status()),
-			),
-		)
-
-		metrics.RequestHistogram.Record(ctx, duration,
-			metric.WithAttributes(
-				attribute.String("method", r.Method),
-				attribute.String("path", r.URL.Path),
-				attribute.Int("status_code", rw.Status()),
-			),
-		)
+		checkSLO(ctx, m, thresholdRoute(r), duration, attrs)
+		checkSlow(ctx, m, thresholdRoute(r), duration, attrs, httpTimingBreakdown(start, rw, duration)...)
 	}
 
 	return http.HandlerFunc(fn)
 }
+
+// unmatchedPath is the path recorded for requests that no route matched.
+const unmatchedPath = "<unmatched>"
+
+// metricPath returns the path attribute for r once it has been served with
+// status. 404 responses without a matched http.ServeMux pattern, typically
+// scanners probing random URLs, are grouped under "<unmatched>" so they
+// cannot create unbounded series. Routers that do not set r.Pattern have all
+// their 404s grouped.
+func metricPath(r *http.Request, status int) string {
+	if status == http.StatusNotFound && matchedPattern(r) == "" {
+		return unmatchedPath
+	}
+	return r.URL.Path
+}
+
+// thresholdRoute returns the route SLOs and slow thresholds of r are looked
+// up with: the route of the ServeMux pattern that matched r, such as
+// "/orders/{id}", or the path when none did.
+func thresholdRoute(r *http.Request) string {
+	if route := patternRoute(matchedPattern(r)); route != "" {
+		return route
+	}
+	return r.URL.Path
+}
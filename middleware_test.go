@@ -0,0 +1,68 @@
+package otelx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetricPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		pattern string
+		status  int
+		want    string
+	}{
+		{name: "ok", path: "/users", status: http.StatusOK, want: "/users"},
+		{name: "ok with pattern", path: "/users/42", pattern: "GET /users/{id}", status: http.StatusOK, want: "/users/42"},
+		{name: "unmatched 404", path: "/wp-login.php", status: http.StatusNotFound, want: unmatchedPath},
+		{name: "matched 404", path: "/users/42", pattern: "GET /users/{id}", status: http.StatusNotFound, want: "/users/42"},
+		{name: "server error", path: "/orders", status: http.StatusInternalServerError, want: "/orders"},
+		{name: "method not allowed", path: "/orders", status: http.StatusMethodNotAllowed, want: "/orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Pattern = tt.pattern
+			if got := metricPath(r, tt.status); got != tt.want {
+				t.Errorf("metricPath(%q, %d) = %q, want %q", tt.path, tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholdRoute(t *testing.T) {
+	thresholds := routeThresholds{
+		"/orders/{id}": time.Second,
+		"/checkout":    2 * time.Second,
+		"/static/*":    3 * time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		pattern string
+		want    time.Duration
+		found   bool
+	}{
+		{name: "pattern with wildcard", path: "/orders/42", pattern: "GET /orders/{id}", want: time.Second, found: true},
+		{name: "literal pattern", path: "/checkout", pattern: "POST /checkout", want: 2 * time.Second, found: true},
+		{name: "prefix", path: "/static/app.js", pattern: "/static/", want: 3 * time.Second, found: true},
+		{name: "no pattern falls back to path", path: "/checkout", want: 2 * time.Second, found: true},
+		{name: "unconfigured route", path: "/users/7", pattern: "GET /users/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Pattern = tt.pattern
+			got, found := thresholds.lookup(thresholdRoute(r))
+			if got != tt.want || found != tt.found {
+				t.Errorf("lookup(%q) = %v, %v, want %v, %v", thresholdRoute(r), got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package otelx
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// WithPrometheusNaming guarantees that exported metric and attribute names are
+// Prometheus-safe, whatever names the instruments were created with:
+//
+//   - characters outside [a-zA-Z0-9_:] become underscores
+//     (http.server.request.duration becomes
+//     http_server_request_duration_seconds)
+//   - the unit is appended as a suffix (_seconds for "s", _bytes for "By",
+//     _ratio for "1" on gauges)
+//   - monotonic counters end in _total
+//
+// It applies to the collector and stdout exporters but not to readers passed
+// to WithMetricReaders. Setting OTELX_METRIC_NAMING=prometheus enables it per
+// deployment, for example only where metrics are sent to Mimir rather than to
+// an OTLP vendor.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "auth-service", otelx.WithPrometheusNaming())
+func WithPrometheusNaming() Option {
+	return func(c *config) {
+		c.prometheusNaming = true
+	}
+}
+
+// prometheusNamingEnabled reports whether c or OTELX_METRIC_NAMING selects
+// Prometheus naming.
+func (c config) prometheusNamingEnabled() bool {
+	return c.prometheusNaming || os.Getenv("OTELX_METRIC_NAMING") == "prometheus"
+}
+
+// prometheusNamingExporter rewrites metric and attribute names to be
+// Prometheus-safe before exporting.
+type prometheusNamingExporter struct {
+	sdkmetric.Exporter
+}
+
+// Export renames the metrics of rm and their data point attributes in place,
+// then forwards rm.
+func (e prometheusNamingExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for i := range rm.ScopeMetrics {
+		metrics := rm.ScopeMetrics[i].Metrics
+		for j := range metrics {
+			metrics[j].Name = prometheusMetricName(metrics[j])
+			metrics[j].Data = prometheusAttributes(metrics[j].Data)
+		}
+	}
+	return e.Exporter.Export(ctx, rm)
+}
+
+// prometheusUnits maps UCUM units to Prometheus name suffixes.
+var prometheusUnits = map[string]string{
+	"s":  "seconds",
+	"ms": "milliseconds",
+	"us": "microseconds",
+	"ns": "nanoseconds",
+	"By": "bytes",
+	"KB": "kilobytes",
+	"MB": "megabytes",
+	"%":  "percent",
+}
+
+// prometheusMetricName returns the Prometheus-safe name of m.
+func prometheusMetricName(m metricdata.Metrics) string {
+	name := sanitizePrometheusName(m.Name)
+
+	monotonic := false
+	switch d := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		monotonic = d.IsMonotonic
+	case metricdata.Sum[float64]:
+		monotonic = d.IsMonotonic
+	}
+	if monotonic {
+		name = strings.TrimSuffix(name, "_total")
+	}
+
+	suffix := prometheusUnits[m.Unit]
+	if m.Unit == "1" {
+		switch m.Data.(type) {
+		case metricdata.Gauge[int64], metricdata.Gauge[float64]:
+			suffix = "ratio"
+		}
+	}
+	if suffix != "" && !strings.HasSuffix(name, "_"+suffix) {
+		name += "_" + suffix
+	}
+
+	if monotonic {
+		name += "_total"
+	}
+	return name
+}
+
+// sanitizePrometheusName replaces invalid characters with underscores,
+// collapses repeated underscores and avoids a leading digit.
+func sanitizePrometheusName(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	for i, r := range name {
+		valid := r == '_' || r == ':' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9' && i > 0)
+		if i == 0 && r >= '0' && r <= '9' {
+			b.WriteByte('_')
+			valid = true
+		}
+		if !valid {
+			r = '_'
+		}
+		if r == '_' && strings.HasSuffix(b.String(), "_") {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sanitizeLabelName returns the Prometheus-safe form of an attribute key.
+// Label names cannot contain colons.
+func sanitizeLabelName(key attribute.Key) attribute.Key {
+	name := strings.ReplaceAll(sanitizePrometheusName(string(key)), ":", "_")
+	return attribute.Key(name)
+}
+
+// prometheusAttributes renames the data point attributes of data.
+func prometheusAttributes(data metricdata.Aggregation) metricdata.Aggregation {
+	switch d := data.(type) {
+	case metricdata.Sum[int64]:
+		renamePoints(d.DataPoints, func(p *metricdata.DataPoint[int64]) *attribute.Set { return &p.Attributes })
+	case metricdata.Sum[float64]:
+		renamePoints(d.DataPoints, func(p *metricdata.DataPoint[float64]) *attribute.Set { return &p.Attributes })
+	case metricdata.Gauge[int64]:
+		renamePoints(d.DataPoints, func(p *metricdata.DataPoint[int64]) *attribute.Set { return &p.Attributes })
+	case metricdata.Gauge[float64]:
+		renamePoints(d.DataPoints, func(p *metricdata.DataPoint[float64]) *attribute.Set { return &p.Attributes })
+	case metricdata.Histogram[int64]:
+		renamePoints(d.DataPoints, func(p *metricdata.HistogramDataPoint[int64]) *attribute.Set { return &p.Attributes })
+	case metricdata.Histogram[float64]:
+		renamePoints(d.DataPoints, func(p *metricdata.HistogramDataPoint[float64]) *attribute.Set { return &p.Attributes })
+	case metricdata.ExponentialHistogram[int64]:
+		renamePoints(d.DataPoints, func(p *metricdata.ExponentialHistogramDataPoint[int64]) *attribute.Set { return &p.Attributes })
+	case metricdata.ExponentialHistogram[float64]:
+		renamePoints(d.DataPoints, func(p *metricdata.ExponentialHistogramDataPoint[float64]) *attribute.Set { return &p.Attributes })
+	case metricdata.Summary:
+		renamePoints(d.DataPoints, func(p *metricdata.SummaryDataPoint) *attribute.Set { return &p.Attributes })
+	}
+	return data
+}
+
+// renamePoints sanitizes the attribute keys of points in place; attrs returns
+// a point's attribute set.
+func renamePoints[P any](points []P, attrs func(*P) *attribute.Set) {
+	for i := range points {
+		set := attrs(&points[i])
+
+		renamed := false
+		kvs := set.ToSlice()
+		for j, kv := range kvs {
+			if key := sanitizeLabelName(kv.Key); key != kv.Key {
+				kvs[j].Key = key
+				renamed = true
+			}
+		}
+		if renamed {
+			*set = attribute.NewSet(kvs...)
+		}
+	}
+}
+
+// WithMetricPrefix prepends prefix to the name of every instrument otelx
+// creates, so otelx metrics fit an established naming scheme.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "auth-service", otelx.WithMetricPrefix("myorg_"))
+//	// http_requests_total is exported as myorg_http_requests_total
+func WithMetricPrefix(prefix string) Option {
+	return func(c *config) {
+		c.metricPrefix = prefix
+	}
+}
+
+// WithInstrumentName renames the otelx instrument name (such as
+// "http_requests_total") to newName. The metric prefix still applies.
+//
+// Example:
+//
+//	otelx.WithInstrumentName("http_request_duration_seconds", "http_server_latency_seconds")
+func WithInstrumentName(name, newName string) Option {
+	return func(c *config) {
+		if c.instrumentNames == nil {
+			c.instrumentNames = make(map[string]string)
+		}
+		c.instrumentNames[name] = newName
+	}
+}
+
+// WithInstrumentDescription replaces the description of the otelx instrument
+// name, identified by its default name.
+func WithInstrumentDescription(name, description string) Option {
+	return func(c *config) {
+		if c.instrumentDescriptions == nil {
+			c.instrumentDescriptions = make(map[string]string)
+		}
+		c.instrumentDescriptions[name] = description
+	}
+}
+
+// instrumentName returns the configured name of the otelx instrument name.
+func instrumentName(name string) string {
+	if renamed, ok := settings().instrumentNames[name]; ok {
+		name = renamed
+	}
+	return settings().metricPrefix + name
+}
+
+// instrumentDescription returns the description option of the otelx
+// instrument name, defaulting to def.
+func instrumentDescription(name, def string) api.InstrumentOption {
+	if desc, ok := settings().instrumentDescriptions[name]; ok {
+		def = desc
+	}
+	return api.WithDescription(def)
+}
@@ -0,0 +1,86 @@
+package otelx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FlagEvaluationHook is an OpenFeature hook recording every flag evaluation
+// on the active trace and in a counter, so it is visible which flags
+// influenced a slow or failing request:
+//
+//   - a "feature_flag.evaluation" event on the span in the evaluation context,
+//     with feature_flag.key, feature_flag.variant, feature_flag.reason,
+//     feature_flag.value and feature_flag.provider_name (plus error.type for
+//     failed evaluations)
+//   - feature_flag_evaluations_total{feature_flag.key, feature_flag.variant,
+//     feature_flag.reason}
+//
+// The evaluated value is only recorded on the event, keeping the counter's
+// cardinality bounded by the flags' variants.
+//
+// Example:
+//
+//	openfeature.AddHooks(otelx.NewFlagEvaluationHook())
+type FlagEvaluationHook struct {
+	openfeature.UnimplementedHook
+
+	evaluations api.Int64Counter
+}
+
+var _ openfeature.Hook = (*FlagEvaluationHook)(nil)
+
+// NewFlagEvaluationHook creates the hook and its counter from the global
+// MeterProvider.
+func NewFlagEvaluationHook() *FlagEvaluationHook {
+	evaluations, err := otel.Meter(instrumentationName).Int64Counter(
+		instrumentName("feature_flag_evaluations_total"),
+		instrumentDescription("feature_flag_evaluations_total", "Total number of feature flag evaluations"),
+		api.WithUnit("{evaluation}"),
+	)
+	if err != nil {
+		logf("failed to create feature flag evaluations counter: %v\n", err)
+	}
+	return &FlagEvaluationHook{evaluations: evaluations}
+}
+
+// Finally records the evaluation once it has completed, successfully or not.
+func (h *FlagEvaluationHook) Finally(
+	ctx context.Context,
+	hookContext openfeature.HookContext,
+	details openfeature.InterfaceEvaluationDetails,
+	_ openfeature.HookHints,
+) {
+	if telemetryPaused.Load() {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("feature_flag.key", hookContext.FlagKey()),
+		attribute.String("feature_flag.variant", details.Variant),
+		attribute.String("feature_flag.reason", string(details.Reason)),
+	}
+
+	if h.evaluations != nil {
+		h.evaluations.Add(ctx, 1, api.WithAttributes(attrs...))
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs = append(attrs,
+		attribute.String("feature_flag.value", fmt.Sprint(details.Value)),
+		attribute.String("feature_flag.provider_name", hookContext.ProviderMetadata().Name),
+	)
+	if details.ErrorCode != "" {
+		attrs = append(attrs, attribute.String("error.type", string(details.ErrorCode)))
+	}
+	span.AddEvent("feature_flag.evaluation", trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,320 @@
+package otelx
+
+import (
+	"crypto/tls"
+	"net/netip"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+)
+
+// Option customizes how otelx builds its providers and instruments requests.
+//
+// Options are accepted by NewTraceProvider() and NewMeterProvider() and are
+// applied on top of the package defaults. Because the HTTP middleware and gRPC
+// interceptors share the package-level state initialized by the providers,
+// options that affect instrumentation (such as WithClock) take effect for all
+// of them.
+//
+// Each call starts from the defaults: the configuration of the provider
+// created last is the one the instrumentation uses, so pass the same options
+// to every provider.
+//
+// Example:
+//
+//	opts := []otelx.Option{
+//	    otelx.WithIDGenerator(otelx.NewDeterministicIDGenerator(42)),
+//	}
+//	tp, cleanup := otelx.NewTraceProvider(ctx, "auth-service", opts...)
+//	defer cleanup()
+//	cleanupMetrics := otelx.NewMeterProvider(ctx, "auth-service", opts...)
+//	defer cleanupMetrics()
+type Option func(*config)
+
+// config holds the resolved settings built from the package defaults and any
+// Options supplied by the caller.
+type config struct {
+	// idGenerator overrides the SDK's random trace/span ID generator.
+	idGenerator sdktrace.IDGenerator
+
+	// sampler, when set, replaces the ratio-based sampler.
+	sampler sdktrace.Sampler
+
+	// samplingPriority honors the sampling.priority baggage member.
+	samplingPriority bool
+
+	// clock is the time source used to measure request durations.
+	clock Clock
+
+	// spanDropRules select finished spans that are dropped before export.
+	spanDropRules []spanDropRule
+
+	// scrubRules mask or remove sensitive attribute values before export.
+	scrubRules []scrubRule
+
+	// spanProcessors are registered on the TracerProvider in addition to the
+	// OTLP batch processor.
+	spanProcessors []sdktrace.SpanProcessor
+
+	// metricReaders are registered on the MeterProvider in addition to the
+	// OTLP periodic reader.
+	metricReaders []sdkmetric.Reader
+
+	// spanExporters and metricExporters receive telemetry in addition to
+	// the OTLP collector exporters.
+	spanExporters   []sdktrace.SpanExporter
+	metricExporters []sdkmetric.Exporter
+
+	// stdout enables the stdout exporters for both signals.
+	stdout bool
+
+	// diskBuffer, when set, spills spans and metrics that failed to export to disk.
+	diskBuffer *DiskBuffer
+
+	// debugSpans is the number of errored spans kept for DebugHandler. Zero
+	// disables span recording.
+	debugSpans int
+
+	// spanMetrics enables RED metrics derived from finished spans.
+	spanMetrics bool
+
+	// streamMessageLatency enables the per-message stream latency histogram.
+	streamMessageLatency bool
+
+	// prometheusNaming rewrites exported metric and attribute names to be
+	// Prometheus-safe.
+	prometheusNaming bool
+
+	// metricPrefix is prepended to the otelx instrument names.
+	metricPrefix string
+
+	// instrumentNames and instrumentDescriptions override the names and
+	// descriptions of otelx instruments, keyed on their default names.
+	instrumentNames        map[string]string
+	instrumentDescriptions map[string]string
+
+	// traceRetry and metricRetry override the OTLP exporters' retry
+	// policies; traceTimeout and metricTimeout their export timeouts.
+	traceRetry    *ExportRetry
+	metricRetry   *ExportRetry
+	traceTimeout  time.Duration
+	metricTimeout time.Duration
+
+	// connectTimeout, when positive, is how long to wait for the collector
+	// connection at startup.
+	connectTimeout time.Duration
+
+	// profilerLabels sets pprof labels for the duration of StartSpan spans.
+	profilerLabels bool
+
+	// flagEvaluator supplies feature flag assignments in addition to
+	// baggage, and flagDimensions lists the flags recorded on request
+	// metrics.
+	flagEvaluator  FlagEvaluator
+	flagDimensions []string
+
+	// contextExtractors add context-derived attributes to StartSpan spans.
+	contextExtractors []ContextExtractor
+
+	// syntheticMode enables synthetic traffic detection, using the
+	// syntheticUserAgents substrings and syntheticHeaders markers.
+	syntheticMode       SyntheticMode
+	syntheticUserAgents []string
+	syntheticHeaders    []string
+
+	// spanMemoryLimit bounds the approximate bytes queued per span exporter.
+	spanMemoryLimit int64
+
+	// dialOptions are appended to the collector connection's DialOptions.
+	dialOptions []grpc.DialOption
+
+	// collectorConn, when set, is used instead of dialing the collector.
+	collectorConn *grpc.ClientConn
+
+	// collectorSecurity overrides the TLS choice made from the endpoint
+	// scheme; collectorTLS configures TLS connections.
+	collectorSecurity collectorSecurity
+	collectorTLS      *tls.Config
+
+	// schemaURL pins the semantic conventions schema (see WithSchemaURL).
+	schemaURL string
+
+	// cardinalityReport is the interval of the cardinality report log.
+	cardinalityReport time.Duration
+
+	// countSuppressed counts the telemetry not emitted while disabled.
+	countSuppressed bool
+
+	// millisecondDurations records request durations in milliseconds.
+	millisecondDurations bool
+
+	// errorStatusMapper maps errors returned by HandlerFunc to status codes.
+	errorStatusMapper func(error) int
+
+	// resourceDetectors replace host detection when set; noHostDetection
+	// disables it. detectionTimeout bounds detection, zero meaning no limit.
+	resourceDetectors []resource.Detector
+	noHostDetection   bool
+	detectionTimeout  time.Duration
+
+	// tlsMetrics records inbound TLS handshakes and connection attributes.
+	tlsMetrics bool
+
+	// slos maps routes and methods to latency objectives.
+	slos routeThresholds
+
+	// slowThresholds maps routes and methods to slow request thresholds.
+	slowThresholds routeThresholds
+
+	// trustedProxies are the proxies whose forwarding headers are trusted
+	// when resolving the client IP.
+	trustedProxies []netip.Prefix
+
+	// userAgent records the User-Agent on spans; normalizeUserAgent also
+	// reduces it to a client family used as a metric dimension.
+	userAgent          bool
+	normalizeUserAgent bool
+
+	// clientVersionHeader names the header carrying the client version.
+	clientVersionHeader string
+
+	// requestIDHeader is the header carrying the request ID.
+	requestIDHeader string
+
+	// correlationKeys are legacy correlation headers mapped to baggage.
+	correlationKeys []string
+
+	// capturedHeaders and capturedMetadata are the allowlisted HTTP headers
+	// and gRPC metadata keys recorded on SERVER spans.
+	capturedHeaders  []string
+	capturedMetadata []string
+
+	// reloadOnSIGHUP calls Reload whenever the process receives SIGHUP.
+	reloadOnSIGHUP bool
+
+	// profile overrides the defaults profile derived from ENV.
+	profile *string
+
+	// metricAttributes, when set, is the allowlist of metric attribute keys.
+	metricAttributes map[attribute.Key]bool
+
+	// attributeValueLimit caps the distinct values per request metric
+	// attribute. Zero disables the guard.
+	attributeValueLimit int
+}
+
+// shared is the configuration shared by the providers, the HTTP middleware
+// and the gRPC interceptors, published by the latest configure call.
+var shared atomic.Pointer[config]
+
+// defaultSettings is used until configure is first called.
+var defaultSettings = defaultConfig()
+
+// settings returns the shared configuration. It must not be modified.
+func settings() *config {
+	if cfg := shared.Load(); cfg != nil {
+		return cfg
+	}
+	return &defaultSettings
+}
+
+// defaultConfig returns the configuration used when no Options are given.
+func defaultConfig() config {
+	return config{
+		clock:           systemClock{},
+		spanDropRules:   compileDropRules("", defaultDroppedSpanPatterns),
+		scrubRules:      compileScrubRules(DefaultScrubRules()),
+		requestIDHeader: DefaultRequestIDHeader,
+		correlationKeys: DefaultCorrelationKeys,
+
+		syntheticUserAgents: DefaultSyntheticUserAgents,
+	}
+}
+
+// configure builds the configuration of opts on top of the defaults,
+// publishes it as the shared configuration and returns it.
+//
+// Each call starts over, so options given to one provider are not inherited
+// by the next and options adding processors, readers or exporters register
+// them once per provider.
+//
+// The request attribute cache is cleared, as opts may change the metric
+// attribute allowlist the cached sets were built with.
+func configure(opts []Option) config {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	shared.Store(&cfg)
+	requestAttrs.reset()
+	return cfg
+}
+
+// WithIDGenerator sets the generator used by the TracerProvider to create
+// trace and span IDs.
+//
+// This is useful in tests, where a deterministic generator (see
+// NewDeterministicIDGenerator) makes exported telemetry reproducible and
+// suitable for golden-file comparisons, and in production to emit IDs in a
+// specific format (NewXRayIDGenerator, NewShardIDGenerator) or any custom
+// sdktrace.IDGenerator.
+//
+// Passing nil keeps the SDK's default random generator.
+func WithIDGenerator(gen sdktrace.IDGenerator) Option {
+	return func(c *config) {
+		c.idGenerator = gen
+	}
+}
+
+// WithClock sets the time source used by the HTTP middleware and gRPC
+// interceptors to measure request durations.
+//
+// Injecting a ManualClock lets tests fast-forward time and assert exact
+// durations instead of relying on wall-clock timing, while a CoarseClock
+// lowers the measurement overhead on extremely hot endpoints. Passing nil
+// restores the system clock.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		if clock == nil {
+			clock = systemClock{}
+		}
+		c.clock = clock
+	}
+}
+
+// WithSpanProcessors registers additional span processors on the
+// TracerProvider created by NewTraceProvider.
+//
+// They run alongside otelx's own OTLP pipeline and receive every span,
+// including those dropped or scrubbed before OTLP export, which lets advanced
+// users add custom processing without abandoning otelx.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithSpanProcessors(sdktrace.NewSimpleSpanProcessor(debugExporter)),
+//	)
+func WithSpanProcessors(processors ...sdktrace.SpanProcessor) Option {
+	return func(c *config) {
+		c.spanProcessors = append(c.spanProcessors, processors...)
+	}
+}
+
+// WithMetricReaders registers additional metric readers on the MeterProvider
+// created by NewMeterProvider, e.g. a Prometheus exporter or a manual reader
+// used in tests.
+//
+// A Reader can only be registered with a single MeterProvider, so readers
+// passed here must not be shared with other providers.
+func WithMetricReaders(readers ...sdkmetric.Reader) Option {
+	return func(c *config) {
+		c.metricReaders = append(c.metricReaders, readers...)
+	}
+}
@@ -0,0 +1,52 @@
+package otelx
+
+import (
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestConfigureStartsFromDefaults(t *testing.T) {
+	t.Cleanup(func() { shared.Store(nil) })
+
+	reader := sdkmetric.NewManualReader()
+	opts := []Option{
+		WithMetricReaders(reader),
+		WithSLO("/checkout", time.Second),
+	}
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantReaders int
+		wantSLO     bool
+	}{
+		{name: "first call", opts: opts, wantReaders: 1, wantSLO: true},
+		{name: "same options again", opts: opts, wantReaders: 1, wantSLO: true},
+		{name: "no options", wantReaders: 0, wantSLO: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := configure(tt.opts)
+			if got := len(cfg.metricReaders); got != tt.wantReaders {
+				t.Errorf("configure() registered %d readers, want %d", got, tt.wantReaders)
+			}
+			if _, got := settings().slos.lookup("/checkout"); got != tt.wantSLO {
+				t.Errorf("shared SLO configured = %v, want %v", got, tt.wantSLO)
+			}
+		})
+	}
+}
+
+// useSettings publishes a copy of the shared configuration changed by fn for
+// the duration of the test.
+func useSettings(t *testing.T, fn func(*config)) {
+	t.Helper()
+	saved := shared.Load()
+	cfg := *settings()
+	fn(&cfg)
+	shared.Store(&cfg)
+	t.Cleanup(func() { shared.Store(saved) })
+}
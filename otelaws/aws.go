@@ -0,0 +1,141 @@
+// Package otelaws traces and measures AWS SDK v2 calls with otelx.
+//
+// It lives in its own module so that services not using AWS do not pull in
+// the AWS SDK:
+//
+//	go get github.com/edr3x/otelx/otelaws
+package otelaws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/edr3x/otelx"
+	contribaws "go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+// Instrument instruments the AWS SDK v2 clients created from cfg:
+//
+//   - every API call gets a CLIENT span named after the service and operation
+//     (S3.GetObject, DynamoDB.Query...), a child of the span in the call's
+//     context, with the contrib otelaws service-specific attributes (DynamoDB
+//     tables, SQS queue URLs, SNS topics)
+//   - the trace context is injected into the requests with the configured
+//     propagators
+//   - aws_client_duration_seconds measures the calls, retries included, with
+//     the service, operation and status_code (0 when no response was
+//     received), so S3, DynamoDB, SQS and the other services get their own
+//     latency series
+//
+// Spans and metrics go to the Service carried by the call's context, if any,
+// like the rest of otelx (see otelx.TracerFor and otelx.MeterFor). Call it
+// before creating the clients.
+//
+// Example:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	otelaws.Instrument(&cfg)
+//	s3Client := s3.NewFromConfig(cfg)
+//
+//	// Traced as a child of the span in ctx:
+//	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+func Instrument(cfg *aws.Config) {
+	contribaws.AppendMiddlewares(&cfg.APIOptions,
+		contribaws.WithTracerProvider(awsTracerProvider{}),
+		contribaws.WithTextMapPropagator(otel.GetTextMapPropagator()),
+	)
+	cfg.APIOptions = append(cfg.APIOptions, addAWSMetrics)
+}
+
+// addAWSMetrics adds the aws_client_duration_seconds middleware to stack.
+func addAWSMetrics(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OtelxAWSMetrics", func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		duration := durationFor(ctx)
+		if duration == nil {
+			return next.HandleInitialize(ctx, in)
+		}
+
+		start := otelx.Now()
+		out, metadata, err := next.HandleInitialize(ctx, in)
+
+		var code int
+		if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+			code = resp.StatusCode
+		}
+		duration.Record(ctx, otelx.Now().Sub(start).Seconds(), api.WithAttributes(
+			attribute.String("service", awsmiddleware.GetServiceID(ctx)),
+			attribute.String("operation", awsmiddleware.GetOperationName(ctx)),
+			attribute.Int("status_code", code),
+		))
+		return out, metadata, err
+	}), middleware.After)
+}
+
+// meterDurations caches aws_client_duration_seconds per meter, so the global
+// providers and every otelx.Service get their own.
+var meterDurations sync.Map // api.Meter -> api.Float64Histogram
+
+// durationFor returns aws_client_duration_seconds on otelx's meter for ctx,
+// or nil when metrics are disabled or paused.
+func durationFor(ctx context.Context) api.Float64Histogram {
+	meter, ok := otelx.MeterFor(ctx)
+	if !ok {
+		return nil
+	}
+	if h, ok := meterDurations.Load(meter); ok {
+		return h.(api.Float64Histogram)
+	}
+
+	h, err := meter.Float64Histogram(
+		otelx.InstrumentName("aws_client_duration_seconds"),
+		otelx.InstrumentDescription("aws_client_duration_seconds", "AWS SDK call duration in seconds"),
+		api.WithUnit("s"),
+		api.WithExplicitBucketBoundaries(
+			0.005, 0.01, 0.025, 0.05, 0.1,
+			0.25, 0.5, 1, 2.5, 5,
+			10, 30,
+		),
+	)
+	if err != nil {
+		otel.Handle(fmt.Errorf("aws_client_duration_seconds: %w", err))
+		return nil
+	}
+	actual, _ := meterDurations.LoadOrStore(meter, h)
+	return actual.(api.Float64Histogram)
+}
+
+// awsTracerProvider hands contrib otelaws a tracer resolving otelx's tracer
+// per call.
+type awsTracerProvider struct {
+	embedded.TracerProvider
+}
+
+// Tracer returns the per-call tracer.
+func (awsTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return awsTracer{}
+}
+
+// awsTracer starts spans with otelx.TracerFor.
+type awsTracer struct {
+	embedded.Tracer
+}
+
+// Start starts a span with the tracer for ctx.
+func (awsTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otelx.TracerFor(ctx).Start(ctx, name, opts...)
+}
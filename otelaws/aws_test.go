@@ -0,0 +1,132 @@
+package otelaws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/edr3x/otelx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// testService returns an otelx.Service recording into the returned span
+// recorder and metric reader, without a collector.
+func testService(t *testing.T) (*otelx.Service, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	t.Setenv("ENV", "local")
+	t.Setenv("OTEL_COLLECTOR_ENDPOINT", "")
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	s, err := otelx.NewService(context.Background(), "billing",
+		otelx.WithSpanProcessors(spans),
+		otelx.WithMetricReaders(reader),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+	return s, spans, reader
+}
+
+func TestInstrument(t *testing.T) {
+	saved := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(saved) })
+
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "success", status: http.StatusOK},
+		{name: "server error", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, spans, reader := testService(t)
+
+			var traceparent string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				traceparent = r.Header.Get("Traceparent")
+				w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			cfg := aws.Config{
+				Region:           "us-east-1",
+				Credentials:      aws.AnonymousCredentials{},
+				BaseEndpoint:     aws.String(srv.URL),
+				RetryMaxAttempts: 1,
+			}
+			Instrument(&cfg)
+			client := sqs.NewFromConfig(cfg)
+
+			ctx, parent := s.StartSpan(context.Background())
+			_, err := client.ListQueues(ctx, &sqs.ListQueuesInput{})
+			parent.End()
+			if (err != nil) != (tt.status != http.StatusOK) {
+				t.Fatalf("ListQueues() error = %v", err)
+			}
+
+			var call trace.SpanContext
+			for _, span := range spans.Ended() {
+				if span.Name() == "SQS.ListQueues" {
+					call = span.SpanContext()
+					if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+						t.Error("SQS.ListQueues span is not a child of the caller's span")
+					}
+					if span.SpanKind() != trace.SpanKindClient {
+						t.Errorf("span kind = %v, want client", span.SpanKind())
+					}
+				}
+			}
+			if !call.IsValid() {
+				t.Fatal("no SQS.ListQueues span recorded into the Service")
+			}
+			if want := call.TraceID().String(); len(traceparent) < 35 || traceparent[3:35] != want {
+				t.Errorf("traceparent = %q, want trace %s", traceparent, want)
+			}
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+			if got := callCount(rm, "SQS", "ListQueues", tt.status); got != 1 {
+				t.Errorf("aws_client_duration_seconds recorded %d calls with status %d, want 1", got, tt.status)
+			}
+		})
+	}
+}
+
+// callCount returns the number of calls to service and operation answered
+// with status recorded in rm.
+func callCount(rm metricdata.ResourceMetrics, service, operation string, status int) uint64 {
+	var n uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != otelx.InstrumentName("aws_client_duration_seconds") {
+				continue
+			}
+			for _, p := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				svc, _ := p.Attributes.Value("service")
+				op, _ := p.Attributes.Value("operation")
+				code, _ := p.Attributes.Value("status_code")
+				if svc.AsString() == service && op.AsString() == operation && code.AsInt64() == int64(status) {
+					n += p.Count
+				}
+			}
+		}
+	}
+	return n
+}
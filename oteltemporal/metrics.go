@@ -0,0 +1,87 @@
+package oteltemporal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/edr3x/otelx"
+	"go.opentelemetry.io/otel"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// instruments are the Temporal instruments created on one meter.
+type instruments struct {
+	scheduleToStart  api.Float64Histogram
+	activityDuration api.Float64Histogram
+	workflowDuration api.Float64Histogram
+}
+
+// meterInstruments caches the instruments per meter, so the global providers
+// and every otelx.Service get their own.
+var meterInstruments sync.Map // api.Meter -> *instruments
+
+// instrumentsFor returns the instruments recording into otelx's meter for
+// ctx, or nil when metrics are disabled or paused.
+func instrumentsFor(ctx context.Context) *instruments {
+	meter, ok := otelx.MeterFor(ctx)
+	if !ok {
+		return nil
+	}
+	if m, ok := meterInstruments.Load(meter); ok {
+		return m.(*instruments)
+	}
+
+	m, err := newInstruments(meter)
+	if err != nil {
+		otel.Handle(err)
+		return nil
+	}
+	actual, _ := meterInstruments.LoadOrStore(meter, m)
+	return actual.(*instruments)
+}
+
+// newInstruments creates the Temporal instruments on meter.
+func newInstruments(meter api.Meter) (*instruments, error) {
+	buckets := api.WithExplicitBucketBoundaries(
+		0.01, 0.05, 0.1, 0.5, 1,
+		5, 10, 30, 60, 300,
+		900, 3600,
+	)
+
+	scheduleToStart, err := meter.Float64Histogram(
+		otelx.InstrumentName("temporal_activity_schedule_to_start_seconds"),
+		otelx.InstrumentDescription("temporal_activity_schedule_to_start_seconds", "Time Temporal activities waited for a worker in seconds"),
+		api.WithUnit("s"),
+		buckets,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("temporal_activity_schedule_to_start_seconds: %w", err)
+	}
+
+	activityDuration, err := meter.Float64Histogram(
+		otelx.InstrumentName("temporal_activity_execution_seconds"),
+		otelx.InstrumentDescription("temporal_activity_execution_seconds", "Temporal activity execution duration in seconds"),
+		api.WithUnit("s"),
+		buckets,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("temporal_activity_execution_seconds: %w", err)
+	}
+
+	workflowDuration, err := meter.Float64Histogram(
+		otelx.InstrumentName("temporal_workflow_execution_seconds"),
+		otelx.InstrumentDescription("temporal_workflow_execution_seconds", "Temporal workflow execution duration in seconds"),
+		api.WithUnit("s"),
+		buckets,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("temporal_workflow_execution_seconds: %w", err)
+	}
+
+	return &instruments{
+		scheduleToStart:  scheduleToStart,
+		activityDuration: activityDuration,
+		workflowDuration: workflowDuration,
+	}, nil
+}
@@ -0,0 +1,200 @@
+// Package oteltemporal traces and measures Temporal workflows and activities
+// with otelx.
+//
+// It lives in its own module so that services not using Temporal do not pull
+// in the Temporal SDK:
+//
+//	go get github.com/edr3x/otelx/oteltemporal
+package oteltemporal
+
+import (
+	"context"
+
+	"github.com/edr3x/otelx"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Option configures the interceptor returned by NewInterceptor.
+type Option func(*config)
+
+type config struct {
+	service *otelx.Service
+}
+
+// WithService records the workflows and activities run by the worker into
+// the providers of s (see otelx.NewService) instead of the global ones.
+//
+// Workflow code has no context.Context to carry a Service, so a worker of a
+// named Service must be given one explicitly to report its workflows under
+// that service.name. Activities run with s in their context, so spans they
+// start with otelx are recorded into s too.
+func WithService(s *otelx.Service) Option {
+	return func(c *config) {
+		c.service = s
+	}
+}
+
+// NewInterceptor returns a Temporal interceptor tracing and measuring
+// workflows and activities with otelx:
+//
+//   - the trace context flows from the client starting a workflow into the
+//     workflow, its activities and child workflows through Temporal headers,
+//     using the global propagator
+//   - spans are emitted for starting, signaling, querying and running
+//     workflows and activities, with temporal.workflow.type,
+//     temporal.activity.type, temporal.workflow.id and temporal.run.id
+//   - temporal_activity_schedule_to_start_seconds measures how long
+//     activities waited for a worker, and temporal_activity_execution_seconds
+//     and temporal_workflow_execution_seconds how long they ran, with the
+//     type, task_queue and result
+//
+// Workflow spans and metrics are only emitted outside of replay, so replayed
+// histories do not produce duplicates.
+//
+// Without WithService, telemetry is recorded into the global providers
+// installed by otelx.NewTracerProvider and otelx.NewMeterProvider. Activities
+// whose context carries a Service (through the worker's
+// BackgroundActivityContext) are still recorded into it, but workflows are
+// not.
+//
+// Set it on the client; workers created from the client use it too. A worker
+// of a named Service sets its own on worker.Options.Interceptors.
+//
+// Example:
+//
+//	c, err := client.Dial(client.Options{
+//	    Interceptors: []interceptor.ClientInterceptor{oteltemporal.NewInterceptor()},
+//	})
+//
+//	// A worker of the billing Service:
+//	w := worker.New(c, "billing", worker.Options{
+//	    Interceptors: []interceptor.WorkerInterceptor{
+//	        oteltemporal.NewInterceptor(oteltemporal.WithService(billing)),
+//	    },
+//	})
+func NewInterceptor(opts ...Option) interceptor.Interceptor {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	base := context.Background()
+	if cfg.service != nil {
+		base = otelx.ContextWithService(base, cfg.service)
+	}
+	return &temporalInterceptor{
+		service: cfg.service,
+		base:    base,
+		tracing: interceptor.NewTracingInterceptor(temporalTracer{base: base}),
+	}
+}
+
+// temporalInterceptor adds the otelx metrics under the SDK's tracing
+// interceptor.
+type temporalInterceptor struct {
+	interceptor.InterceptorBase
+	service *otelx.Service
+	base    context.Context
+	tracing interceptor.Interceptor
+}
+
+// InterceptClient traces the client calls.
+func (t *temporalInterceptor) InterceptClient(next interceptor.ClientOutboundInterceptor) interceptor.ClientOutboundInterceptor {
+	return t.tracing.InterceptClient(next)
+}
+
+// InterceptActivity traces and measures activity executions.
+func (t *temporalInterceptor) InterceptActivity(ctx context.Context, next interceptor.ActivityInboundInterceptor) interceptor.ActivityInboundInterceptor {
+	measured := &temporalActivityInbound{
+		ActivityInboundInterceptorBase: interceptor.ActivityInboundInterceptorBase{Next: next},
+		service:                        t.service,
+	}
+	return t.tracing.InterceptActivity(ctx, measured)
+}
+
+// InterceptWorkflow traces and measures workflow executions.
+func (t *temporalInterceptor) InterceptWorkflow(ctx workflow.Context, next interceptor.WorkflowInboundInterceptor) interceptor.WorkflowInboundInterceptor {
+	measured := &temporalWorkflowInbound{
+		WorkflowInboundInterceptorBase: interceptor.WorkflowInboundInterceptorBase{Next: next},
+		base:                           t.base,
+	}
+	return t.tracing.InterceptWorkflow(ctx, measured)
+}
+
+// InterceptNexusOperation traces Nexus operation handlers.
+func (t *temporalInterceptor) InterceptNexusOperation(ctx context.Context, next interceptor.NexusOperationInboundInterceptor) interceptor.NexusOperationInboundInterceptor {
+	return t.tracing.InterceptNexusOperation(ctx, next)
+}
+
+// temporalActivityInbound records the activity metrics.
+type temporalActivityInbound struct {
+	interceptor.ActivityInboundInterceptorBase
+	service *otelx.Service
+}
+
+// ExecuteActivity runs the activity and records its latencies.
+func (a *temporalActivityInbound) ExecuteActivity(ctx context.Context, in *interceptor.ExecuteActivityInput) (any, error) {
+	if a.service != nil {
+		ctx = otelx.ContextWithService(ctx, a.service)
+	}
+	m := instrumentsFor(ctx)
+	if m == nil {
+		return a.Next.ExecuteActivity(ctx, in)
+	}
+
+	info := activity.GetInfo(ctx)
+	typ := attribute.String("activity_type", info.ActivityType.Name)
+	queue := attribute.String("task_queue", info.TaskQueue)
+	m.scheduleToStart.Record(ctx, info.StartedTime.Sub(info.ScheduledTime).Seconds(),
+		api.WithAttributes(typ, queue))
+
+	start := otelx.Now()
+	result, err := a.Next.ExecuteActivity(ctx, in)
+	m.activityDuration.Record(ctx, otelx.Now().Sub(start).Seconds(),
+		api.WithAttributes(typ, queue, temporalResult(err)))
+	return result, err
+}
+
+// temporalWorkflowInbound records the workflow metrics.
+type temporalWorkflowInbound struct {
+	interceptor.WorkflowInboundInterceptorBase
+
+	// base carries the Service given to WithService, if any.
+	base context.Context
+}
+
+// ExecuteWorkflow runs the workflow and records its duration, from the
+// workflow start to its completion in workflow time, unless replaying.
+func (w *temporalWorkflowInbound) ExecuteWorkflow(ctx workflow.Context, in *interceptor.ExecuteWorkflowInput) (any, error) {
+	result, err := w.Next.ExecuteWorkflow(ctx, in)
+
+	if workflow.IsReplaying(ctx) {
+		return result, err
+	}
+	m := instrumentsFor(w.base)
+	if m == nil {
+		return result, err
+	}
+	info := workflow.GetInfo(ctx)
+	m.workflowDuration.Record(w.base,
+		workflow.Now(ctx).Sub(info.WorkflowStartTime).Seconds(),
+		api.WithAttributes(
+			attribute.String("workflow_type", info.WorkflowType.Name),
+			attribute.String("task_queue", info.TaskQueueName),
+			temporalResult(err),
+		))
+	return result, err
+}
+
+// temporalResult returns the result attribute of an execution ending with
+// err.
+func temporalResult(err error) attribute.KeyValue {
+	if err != nil {
+		return attribute.String("result", "failure")
+	}
+	return attribute.String("result", "success")
+}
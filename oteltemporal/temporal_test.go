@@ -0,0 +1,143 @@
+package oteltemporal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edr3x/otelx"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// testService returns an otelx.Service recording into the returned span
+// recorder and metric reader, without a collector.
+func testService(t *testing.T) (*otelx.Service, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	t.Setenv("ENV", "local")
+	t.Setenv("OTEL_COLLECTOR_ENDPOINT", "")
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	s, err := otelx.NewService(context.Background(), "billing",
+		otelx.WithSpanProcessors(spans),
+		otelx.WithMetricReaders(reader),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+	return s, spans, reader
+}
+
+var errDeclined = errors.New("card declined")
+
+// Charge is the activity of the Checkout workflow.
+func Charge(ctx context.Context, card string) error {
+	if otelx.ServiceFromContext(ctx) == nil {
+		return errors.New("activity context does not carry the Service")
+	}
+	if card == "declined" {
+		return errDeclined
+	}
+	return nil
+}
+
+// Checkout is the workflow run by the tests.
+func Checkout(ctx workflow.Context, card string) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+	})
+	return workflow.ExecuteActivity(ctx, Charge, card).Get(ctx, nil)
+}
+
+func TestInterceptor(t *testing.T) {
+	tests := []struct {
+		card   string
+		result string
+	}{
+		{card: "valid", result: "success"},
+		{card: "declined", result: "failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.card, func(t *testing.T) {
+			s, spans, reader := testService(t)
+
+			var suite testsuite.WorkflowTestSuite
+			env := suite.NewTestWorkflowEnvironment()
+			env.SetWorkerOptions(worker.Options{
+				Interceptors: []interceptor.WorkerInterceptor{NewInterceptor(WithService(s))},
+			})
+			env.RegisterWorkflow(Checkout)
+			env.RegisterActivity(Charge)
+
+			env.ExecuteWorkflow(Checkout, tt.card)
+			if !env.IsWorkflowCompleted() {
+				t.Fatal("workflow did not complete")
+			}
+			if err := env.GetWorkflowError(); (err != nil) != (tt.result == "failure") {
+				t.Fatalf("workflow error = %v, want result %s", err, tt.result)
+			}
+
+			names := make(map[string]bool)
+			for _, span := range spans.Ended() {
+				names[span.Name()] = true
+				if span.SpanContext().TraceID() != spans.Ended()[0].SpanContext().TraceID() {
+					t.Errorf("span %s is not in the workflow's trace", span.Name())
+				}
+			}
+			for _, want := range []string{"RunWorkflow:Checkout", "StartActivity:Charge", "RunActivity:Charge"} {
+				if !names[want] {
+					t.Errorf("no %s span among %v", want, names)
+				}
+			}
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{
+				"temporal_activity_schedule_to_start_seconds",
+				"temporal_activity_execution_seconds",
+				"temporal_workflow_execution_seconds",
+			} {
+				want := tt.result
+				if name == "temporal_activity_schedule_to_start_seconds" {
+					want = ""
+				}
+				if got := histogramCount(rm, otelx.InstrumentName(name), want); got != 1 {
+					t.Errorf("%s recorded %d times with result %q, want 1", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// histogramCount returns the number of measurements of the histogram name in
+// rm whose result attribute is result.
+func histogramCount(rm metricdata.ResourceMetrics, name, result string) uint64 {
+	var n uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, p := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				if v, _ := p.Attributes.Value(attribute.Key("result")); v.AsString() == result {
+					n += p.Count
+				}
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,132 @@
+package oteltemporal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/edr3x/otelx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/interceptor"
+)
+
+// temporalHeaderKey is the Temporal header carrying the trace context. It
+// matches the Temporal OpenTelemetry contrib package, so workers instrumented
+// with either continue each other's traces.
+const temporalHeaderKey = "_tracer-data"
+
+// temporalSpanContextKey is the key under which the SDK stores spans in
+// contexts, such as workflow.Context, that otel cannot hold spans in.
+type temporalSpanContextKey struct{}
+
+// temporalTracer implements the SDK's tracing interceptor on otelx's tracer
+// and the global propagator.
+type temporalTracer struct {
+	interceptor.BaseTracer
+
+	// base is the context spans without a parent, and parents extracted from
+	// headers, start from. It carries the Service given to WithService, if
+	// any.
+	base context.Context
+}
+
+// temporalSpan is a span started through temporalTracer, or a parent
+// extracted from a header.
+type temporalSpan struct {
+	trace.Span
+	ctx context.Context
+}
+
+// Finish ends the span, recording the error if any.
+func (s *temporalSpan) Finish(opts *interceptor.TracerFinishSpanOptions) {
+	if opts.Error != nil {
+		otelx.RecordErrorChain(s.Span, opts.Error)
+	}
+	s.End()
+}
+
+// Options returns the header and context keys.
+func (temporalTracer) Options() interceptor.TracerOptions {
+	return interceptor.TracerOptions{
+		SpanContextKey: temporalSpanContextKey{},
+		HeaderKey:      temporalHeaderKey,
+	}
+}
+
+// UnmarshalSpan extracts a parent from header data.
+func (t temporalTracer) UnmarshalSpan(data map[string]string) (interceptor.TracerSpanRef, error) {
+	ctx := otel.GetTextMapPropagator().Extract(t.base, propagation.MapCarrier(data))
+	return &temporalSpan{Span: trace.SpanFromContext(ctx), ctx: ctx}, nil
+}
+
+// MarshalSpan injects span into header data.
+func (temporalTracer) MarshalSpan(span interceptor.TracerSpan) (map[string]string, error) {
+	data := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(span.(*temporalSpan).ctx, data)
+	return data, nil
+}
+
+// SpanFromContext returns the span in ctx, or nil.
+func (temporalTracer) SpanFromContext(ctx context.Context) interceptor.TracerSpan {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil
+	}
+	return &temporalSpan{Span: span, ctx: ctx}
+}
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func (temporalTracer) ContextWithSpan(ctx context.Context, span interceptor.TracerSpan) context.Context {
+	return trace.ContextWithSpan(ctx, span.(*temporalSpan).Span)
+}
+
+// StartSpan starts a span for a Temporal operation.
+func (t temporalTracer) StartSpan(opts *interceptor.TracerStartSpanOptions) (interceptor.TracerSpan, error) {
+	ctx := t.base
+	if parent, ok := opts.Parent.(*temporalSpan); ok {
+		ctx = parent.ctx
+	}
+
+	kind := trace.SpanKindClient
+	for _, prefix := range []string{"Run", "Handle", "Validate"} {
+		if strings.HasPrefix(opts.Operation, prefix) {
+			kind = trace.SpanKindServer
+		}
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("temporal.operation", opts.Operation)}
+	switch {
+	case strings.Contains(opts.Operation, "Activity"):
+		attrs = append(attrs, attribute.String("temporal.activity.type", opts.Name))
+	case strings.Contains(opts.Operation, "Workflow"):
+		attrs = append(attrs, attribute.String("temporal.workflow.type", opts.Name))
+	}
+	for k, v := range opts.Tags {
+		attrs = append(attrs, attribute.String(temporalTagKey(k), v))
+	}
+
+	ctx, span := otelx.TracerFor(ctx).Start(ctx, opts.Operation+":"+opts.Name,
+		trace.WithSpanKind(kind),
+		trace.WithTimestamp(opts.Time),
+		trace.WithAttributes(attrs...),
+	)
+	return &temporalSpan{Span: span, ctx: ctx}, nil
+}
+
+// temporalTags maps the SDK's span tags to attribute names.
+var temporalTags = map[string]string{
+	"temporalWorkflowID": "temporal.workflow.id",
+	"temporalRunID":      "temporal.run.id",
+	"temporalActivityID": "temporal.activity.id",
+	"temporalUpdateID":   "temporal.update.id",
+}
+
+// temporalTagKey returns the attribute name of the SDK span tag k.
+func temporalTagKey(k string) string {
+	if name, ok := temporalTags[k]; ok {
+		return name
+	}
+	return k
+}
@@ -4,16 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	api "go.opentelemetry.io/otel/metric"
-	"go.opentelemetry.io/otel/propagation"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -21,19 +22,30 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
-	metrics        Metrics
+	// metrics starts out with no-op instruments so recording is safe before
+	// NewMeterProvider is called or when it fails.
+	metrics        = noopMetrics()
 	tracer         trace.Tracer
 	grpcConnection *grpc.ClientConn
+
+	// metricsEnabled reports whether NewMeterProvider successfully installed
+	// the instruments. Instrumentation created while it is false short-circuits
+	// to a pass-through so the disabled path costs nothing per request.
+	metricsEnabled atomic.Bool
+
+	// tracingEnabled reports whether NewTraceProvider successfully installed
+	// the SDK tracer provider.
+	tracingEnabled atomic.Bool
 )
 
 // Metrics holds pre-initialized OpenTelemetry instruments for recording
 // HTTP request metrics.
 //
-// These instruments are created when NewMeterProvider() is called.
+// These instruments are created when NewMeterProvider() is called. Until then,
+// and if it fails, they are no-ops, so recording never hits a nil instrument.
 // Typical usage:
 //
 //	metrics.RequestCounter.Add(ctx, 1, attribute.String("route", "/login"))
@@ -43,6 +55,56 @@ var (
 type Metrics struct {
 	RequestCounter   api.Int64Counter
 	RequestHistogram api.Float64Histogram
+
+	// SLOBreachCounter counts requests slower than their objective (see
+	// WithSLO).
+	SLOBreachCounter api.Int64Counter
+
+	// SlowRequestCounter counts requests slower than their slow threshold
+	// (see WithSlowThreshold).
+	SlowRequestCounter api.Int64Counter
+
+	// HTTPErrorCounter counts HTTP requests answered with a 5xx status and
+	// RPCErrorCounter counts gRPC calls finished with a non-OK code, giving
+	// recording rules and autoscalers a direct error series.
+	HTTPErrorCounter api.Int64Counter
+	RPCErrorCounter  api.Int64Counter
+
+	// PanicCounter counts handler panics recovered by RecoveryMiddleware
+	// and the gRPC recovery interceptors.
+	PanicCounter api.Int64Counter
+
+	// HandlerErrorCounter counts the errors returned by HandlerFunc handlers.
+	HandlerErrorCounter api.Int64Counter
+
+	// RPCClientCounter and RPCClientHistogram measure outgoing gRPC calls
+	// made through the client metrics interceptors, kept apart from the
+	// serving instruments so dependency dashboards stay distinct.
+	RPCClientCounter   api.Int64Counter
+	RPCClientHistogram api.Float64Histogram
+
+	// ClientRequestSize and ClientResponseSize measure the bodies of outgoing
+	// HTTP calls made through HTTPClient and DoRequest, per target host.
+	ClientRequestSize  api.Int64Histogram
+	ClientResponseSize api.Int64Histogram
+
+	// StreamMessageHistogram measures the interval between stream messages
+	// (see WithStreamMessageLatency).
+	StreamMessageHistogram api.Float64Histogram
+
+	// ActiveStreams tracks the streaming RPCs in progress per method, showing
+	// the saturation of long-lived streaming endpoints.
+	ActiveStreams api.Int64UpDownCounter
+
+	// OpenConnections tracks the HTTP server connections by state (new,
+	// active, idle) and ConnectionEvents counts their state transitions,
+	// including hijacked and closed (see ConnStateHook).
+	OpenConnections  api.Int64UpDownCounter
+	ConnectionEvents api.Int64Counter
+
+	// TLSHandshakeHistogram measures inbound TLS handshakes by protocol
+	// version and cipher suite (see WithTLSMetrics).
+	TLSHandshakeHistogram api.Float64Histogram
 }
 
 // initCollector establishes a shared gRPC connection to the OpenTelemetry
@@ -51,11 +113,16 @@ type Metrics struct {
 //
 // Behavior:
 //   - Respects OTEL_ENABLE=false (returns a known error instead of connecting)
-//   - Requires OTEL_COLLECTOR_ENDPOINT to be set (host:port)
+//   - Requires OTEL_COLLECTOR_ENDPOINT to be set (host:port or a unix socket
+//     such as unix:///var/run/otel/collector.sock), unless a connection was
+//     given with WithCollectorConn
 //   - Returns the existing cached connection if already initialized
 //
+// The connection options (WithCollectorConn, WithDialOptions,
+// WithCollectorTLS...) are taken from cfg on the first call only.
+//
 // This function should not be used directly by applications.
-func initCollector() (*grpc.ClientConn, error) {
+func initCollector(cfg config) (*grpc.ClientConn, error) {
 	if grpcConnection != nil {
 		return grpcConnection, nil
 	}
@@ -65,22 +132,136 @@ func initCollector() (*grpc.ClientConn, error) {
 		return nil, errors.New("tracing disabled via OTEL_ENABLE=false")
 	}
 
+	conn := cfg.collectorConn
+	if conn == nil {
+		var err error
+		if conn, err = dialCollector(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Route SDK errors, such as failed exports while the collector is
+	// flapping, through the deduplicating logger.
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logf("opentelemetry error: %v\n", err)
+	}))
+
+	grpcConnection = conn
+	return conn, nil
+}
+
+// dialCollector creates a connection to OTEL_COLLECTOR_ENDPOINT.
+func dialCollector(cfg config) (*grpc.ClientConn, error) {
 	otlpEndpoint := os.Getenv("OTEL_COLLECTOR_ENDPOINT")
 	if otlpEndpoint == "" {
 		return nil, errors.New("OTEL_COLLECTOR_ENDPOINT not set")
 	}
 
-	// It connects the OpenTelemetry Collector through local gRPC connection.
-	conn, err := grpc.NewClient(otlpEndpoint,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	target, secure := collectorEndpoint(otlpEndpoint)
+	if isUnixTarget(target) {
+		logf("connecting to collector over unix socket %s\n", target)
+	}
+
+	// It connects the OpenTelemetry Collector through gRPC, using TLS when
+	// the endpoint's scheme or WithCollectorTLS asks for it.
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(collectorCredentials(cfg, secure)),
+		grpc.WithChainUnaryInterceptor(payloadSizeInterceptor),
+	}, cfg.dialOptions...)
+	conn, err := grpc.NewClient(target, dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC connection to collector: %w", err)
 	}
 
-	fmt.Println("grpc connection success")
+	return conn, nil
+}
 
-	grpcConnection = conn
+// WithBlockingConnect makes NewTraceProvider, NewMeterProvider and NewService
+// wait up to timeout for the collector connection to become ready, and fail
+// (falling back to no-op telemetry) if it does not, instead of connecting
+// lazily and only surfacing problems as missing telemetry later.
+//
+// Example:
+//
+//	tp, cleanup := otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithBlockingConnect(5*time.Second),
+//	)
+//	if tp == nil {
+//	    log.Fatal("telemetry unavailable")
+//	}
+func WithBlockingConnect(timeout time.Duration) Option {
+	return func(c *config) {
+		c.connectTimeout = timeout
+	}
+}
+
+// WithDialOptions adds grpc.DialOptions used to create the collector
+// connection, such as keepalive parameters, a default service config for
+// client-side load balancing across a headless service, resolvers or
+// interceptors. They are applied after otelx's own options, so they can
+// override them.
+//
+// The collector connection is shared and created once, by the first of
+// NewTraceProvider, NewMeterProvider, NewLoggerProvider or NewService to
+// run; pass the options to that call.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithDialOptions(
+//	        grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+//	        grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: 30 * time.Second}),
+//	    ),
+//	)
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *config) {
+		c.dialOptions = opts
+	}
+}
+
+// WithCollectorConn makes otelx export over conn instead of dialing
+// OTEL_COLLECTOR_ENDPOINT itself, for applications that already manage a
+// connection to the collector or agent with their own credentials and
+// lifecycle. otelx never closes conn; close it after the providers have been
+// shut down. OTEL_ENABLE still applies.
+//
+// Like WithDialOptions, it must be passed to the first provider constructor
+// that runs.
+//
+// Example:
+//
+//	conn, err := grpc.NewClient("agent:4317", grpc.WithTransportCredentials(creds))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer conn.Close()
+//
+//	tp, cleanup := otelx.NewTraceProvider(ctx, "auth-service", otelx.WithCollectorConn(conn))
+//	defer cleanup()
+func WithCollectorConn(conn *grpc.ClientConn) Option {
+	return func(c *config) {
+		c.collectorConn = conn
+	}
+}
+
+// connectCollector returns the shared collector connection, waiting for it
+// to become ready when WithBlockingConnect is set.
+func connectCollector(ctx context.Context, cfg config) (*grpc.ClientConn, error) {
+	if localStandalone(cfg) {
+		return nil, nil
+	}
+
+	conn, err := initCollector(cfg)
+	if err != nil || cfg.connectTimeout <= 0 {
+		return conn, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.connectTimeout)
+	defer cancel()
+
+	if _, err := waitReady(ctx, conn); err != nil {
+		return nil, err
+	}
 	return conn, nil
 }
 
@@ -89,22 +270,34 @@ func initCollector() (*grpc.ClientConn, error) {
 //
 //   - service.name      (explicit argument)
 //   - service.version   from $SERVICE_VERSION
-//   - deployment.environment from $ENV
-//   - host.*            automatically via resource.WithHost()
+//   - deployment.environment from $ENV (deployment.environment.name from
+//     schema 1.27.0, see WithSchemaURL)
+//   - host.*            detected once per process via resource.WithHost()
+//     (see WithResourceDetectors), or inherited from the parent process
+//     (see ChildProcessEnv)
+//   - process.pid, process.parent_pid and service.instance.id in child
+//     processes started with ChildProcessEnv
 //
 // These attributes help Tempo/Jaeger/Grafana correctly group and filter spans.
 //
 // This function is used internally by NewTraceProvider() and NewMeterProvider().
 func newResource(ctx context.Context, service string) (*resource.Resource, error) {
-	return resource.New(
+	res, err := resource.New(
 		ctx,
 		resource.WithAttributes(
 			semconv.ServiceNameKey.String(service),
 			semconv.ServiceVersionKey.String(os.Getenv("SERVICE_VERSION")),
-			semconv.DeploymentEnvironmentKey.String(os.Getenv("ENV")),
+			deploymentEnvironmentKey().String(os.Getenv("ENV")),
 		),
-		resource.WithHost(), // automatically adds host.id, host.name
+		// host.id and host.name, detected once per process or inherited
+		// from the parent (see WithResourceDetectors and ChildProcessEnv).
+		resource.WithAttributes(detectedAttributes(ctx)...),
+		resource.WithAttributes(processAttributes()...),
 	)
+	if err != nil {
+		return nil, err
+	}
+	return pinSchema(res), nil
 }
 
 // NewTraceProvider configures and registers a global OpenTelemetry
@@ -114,9 +307,12 @@ func newResource(ctx context.Context, service string) (*resource.Resource, error
 //
 //  1. Connects to the OTEL Collector via gRPC
 //  2. Creates an OTLP trace exporter
-//  3. Attaches a BatchSpanProcessor for efficient export
+//  3. Attaches a BatchSpanProcessor for efficient export, dropping health
+//     check and metrics scrape spans beforehand (see WithDroppedSpanNames)
+//     and masking sensitive attribute values (see WithScrubRules)
 //  4. Builds a Resource containing service metadata
-//  5. Sets W3C TraceContext + Baggage as global propagators
+//  5. Sets W3C TraceContext + Baggage (or the OTEL_PROPAGATORS selection) as
+//     global propagators
 //  6. Exposes a package-level tracer used by StartSpan()
 //
 // If OTEL_ENABLE=false or the connection fails, a No-Op tracer is installed
@@ -133,62 +329,144 @@ func newResource(ctx context.Context, service string) (*resource.Resource, error
 //
 // Traces created through StartSpan() or otel.Tracer() will automatically be sent
 // to the collector if telemetry is enabled.
-func NewTraceProvider(ctx context.Context, service string) (*sdktrace.TracerProvider, func()) {
+//
+// Optional Options (e.g. WithIDGenerator) customize the provider.
+func NewTraceProvider(ctx context.Context, service string, opts ...Option) (*sdktrace.TracerProvider, func()) {
+	cfg := configure(opts)
+
 	clean := func() {}
-	conn, err := initCollector()
+	conn, err := connectCollector(ctx, cfg)
 	if err != nil {
-		log.Printf("failed to grpc connection: %v\n", err)
+		logf("failed to grpc connection: %v\n", err)
 		tp := noop.NewTracerProvider()
 		tracer = tp.Tracer("noop")
 		return nil, clean
 	}
 
-	traceExporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+	// Define standard resource attributes used by all traces.
+	res, err := newResource(ctx, service)
 	if err != nil {
-		log.Printf("failed to create exporter: %v\n", err)
+		logf("failed to create resource: %v\n", err)
 		return nil, clean
 	}
 
-	// Define standard resource attributes used by all traces.
-	res, err := newResource(ctx, service)
+	// Drop noisy spans (health checks, metrics scrapes) and scrub sensitive
+	// attribute values before batching for each exporter.
+	baseDropRules = cfg.spanDropRules
+	setDropRules(cfg.spanDropRules)
+
+	// The sampler, enabled flag and ignored paths can be changed at runtime
+	// through Reload; apply their initial values from the environment.
+	sampler := activeSampler
+	if err := Reload(); err != nil {
+		logf("invalid telemetry configuration: %v\n", err)
+	}
+
+	tpOpts, err := tracerProviderOptions(ctx, conn, cfg, res)
 	if err != nil {
-		log.Printf("failed to create resource: %v\n", err)
+		logf("failed to create exporter: %v\n", err)
 		return nil, clean
 	}
+	if cfg.spanMetrics {
+		if smp := newSpanMetricsProcessor(); smp != nil {
+			tpOpts = append(tpOpts, sdktrace.WithSpanProcessor(
+				newFilterSpanProcessor(smp),
+			))
+		}
+	}
+	if cfg.debugSpans > 0 {
+		debugRecorder = newSpanRecorder(cfg.debugSpans)
+		tpOpts = append(tpOpts, sdktrace.WithSpanProcessor(debugRecorder))
+	}
 
-	bsm := sdktrace.NewBatchSpanProcessor(traceExporter)
-
-	// Create the tracer provider with batching exporter and resource.
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(bsm),
-	)
+	tp := sdktrace.NewTracerProvider(tpOpts...)
 
 	// Register as global provider.
 	otel.SetTracerProvider(tp)
 
-	// Set global propagators: TraceContext + Baggage.
-	// Ensures correct trace propagation across microservices.
-	otel.SetTextMapPropagator(
-		propagation.NewCompositeTextMapPropagator(
-			propagation.Baggage{},
-			propagation.TraceContext{},
-		),
-	)
+	setPropagator()
 
-	tracer = tp.Tracer(service)
+	tracer = tp.Tracer(service, tracerOptions()...)
+	tracingEnabled.Store(true)
+	recordPipeline(service, res, sampler)
 
-	cleanup := func() {
+	stopReload := func() {}
+	if cfg.reloadOnSIGHUP {
+		stopReload = watchSIGHUP()
+	}
+	stopWatch := func() {}
+	if path := os.Getenv("OTELX_CONFIG_FILE"); path != "" {
+		stopWatch = watchConfigFile(path)
+	}
+
+	shutdown := registerShutdown("traces", func(ctx context.Context) error {
+		stopReload()
+		stopWatch()
+		tracingEnabled.Store(false)
 		// Graceful shutdown ensures pending spans are flushed.
-		if err := tp.Shutdown(ctx); err != nil {
-			log.Printf("error shutting down tracer provider: %v", err)
+		return tp.Shutdown(ctx)
+	})
+	cleanup := func() {
+		if err := shutdown(ctx); err != nil {
+			logf("error shutting down tracer provider: %v", err)
 		}
 	}
 
 	return tp, cleanup
 }
 
+// setPropagator sets the global propagators selected by OTEL_PROPAGATORS
+// (TraceContext + Baggage by default, plus the legacy correlation headers
+// mapped onto baggage). It ensures correct trace propagation across
+// microservices.
+func setPropagator() {
+	otel.SetTextMapPropagator(newPropagator())
+}
+
+// tracerProviderOptions builds the TracerProvider options shared by
+// NewTraceProvider and NewService: the instrumented OTLP exporter on conn and
+// any extra exporters behind the filtering and scrubbing pipeline, the
+// reloadable sampler, span limits and the configured ID generator and span
+// processors.
+func tracerProviderOptions(ctx context.Context, conn *grpc.ClientConn, cfg config, res *resource.Resource) ([]sdktrace.TracerProviderOption, error) {
+	var exporters []sdktrace.SpanExporter
+
+	// conn is nil when the local profile runs without a collector.
+	if conn != nil {
+		traceExporter, err := otlptracegrpc.New(ctx, append(cfg.traceExporterOptions(), otlptracegrpc.WithGRPCConn(conn))...)
+		if err != nil {
+			return nil, err
+		}
+
+		// Record export results and latency for every exporter.
+		collectorExporter := instrumentSpanExporter(traceExporter)
+		if cfg.diskBuffer != nil {
+			// Keep spans on disk while the collector is unreachable.
+			collectorExporter = newDiskBufferExporter(collectorExporter, *cfg.diskBuffer)
+		}
+		exporters = append(exporters, collectorExporter)
+	}
+
+	for _, exp := range cfg.extraSpanExporters() {
+		exporters = append(exporters, instrumentSpanExporter(exp))
+	}
+
+	// Create the tracer provider with batching exporter and resource.
+	tpOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(activeSampler),
+		sdktrace.WithResource(res),
+		sdktrace.WithSpanProcessor(newSpanPipeline(cfg, exporters)),
+		sdktrace.WithRawSpanLimits(activeProfile().spanLimits()),
+	}
+	if cfg.idGenerator != nil {
+		tpOpts = append(tpOpts, sdktrace.WithIDGenerator(cfg.idGenerator))
+	}
+	for _, sp := range cfg.spanProcessors {
+		tpOpts = append(tpOpts, sdktrace.WithSpanProcessor(sp))
+	}
+	return tpOpts, nil
+}
+
 // NewMeterProvider initializes the global OpenTelemetry MeterProvider and
 // registers metrics instruments used across the service.
 //
@@ -202,79 +480,332 @@ func NewTraceProvider(ctx context.Context, service string) (*sdktrace.TracerProv
 // The following instruments are created by default:
 //
 //   - http_requests_total                (counter)
-//   - http_request_duration_seconds      (histogram)
+//   - http_request_duration_seconds      (histogram, see WithMillisecondDurations)
+//   - slo_breaches_total                 (counter, see WithSLO)
+//   - slow_requests_total                (counter, see WithSlowThreshold)
+//   - http_errors_total                  (counter, 5xx responses)
+//   - rpc_errors_total                   (counter, non-OK gRPC codes)
+//   - panics_total                       (counter, recovered handler panics)
+//   - http_handler_errors_total          (counter, see HandlerFunc)
+//   - rpc_server_active_streams          (up-down counter, streams in progress)
+//   - http_server_open_connections       (up-down counter, see ConnStateHook)
+//   - http_server_connection_events_total (counter, see ConnStateHook)
+//   - tls_handshake_duration_seconds     (histogram, see WithTLSMetrics)
+//   - rpc_client_requests_total          (counter, outgoing gRPC calls)
+//   - rpc_client_duration_seconds        (histogram, outgoing gRPC calls)
+//   - http_client_request_size_bytes     (histogram, outgoing HTTP calls)
+//   - http_client_response_size_bytes    (histogram, outgoing HTTP calls)
+//   - rpc_stream_message_interval_seconds (histogram, see WithStreamMessageLatency)
 //
-// These match common Prometheus naming conventions.
+// These match common Prometheus naming conventions. Every instrument carries
+// unit metadata ("s" for durations, annotations such as "{request}" for
+// counters) so backends can label axes and convert values.
+//
+// Optional Options (e.g. WithClock) customize the provider and the
+// instrumentation that records into it.
 //
 // Returns a cleanup function that flushes and shuts down the provider.
-func NewMeterProvider(ctx context.Context, service string) func() {
+func NewMeterProvider(ctx context.Context, service string, opts ...Option) func() {
+	cfg := configure(opts)
+
 	emptyCleanup := func() {}
-	conn, err := initCollector()
+	conn, err := connectCollector(ctx, cfg)
 	if err != nil {
-		log.Printf("failed to grpc connection: %v\n", err)
+		logf("failed to grpc connection: %v\n", err)
 		return emptyCleanup
 	}
 
-	metricExporter, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn))
+	// Define standard resource attributes used by all traces.
+	res, err := newResource(ctx, service)
 	if err != nil {
-		log.Printf("failed to grpc connection: %v\n", err)
+		logf("failed to create resource: %v\n", err)
 		return emptyCleanup
 	}
 
-	// Define standard resource attributes used by all traces.
-	res, err := newResource(ctx, service)
+	mpOpts, err := meterProviderOptions(ctx, conn, cfg, res)
 	if err != nil {
-		log.Printf("failed to create resource: %v\n", err)
+		logf("failed to grpc connection: %v\n", err)
 		return emptyCleanup
 	}
 
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
-		sdkmetric.WithResource(res),
-	)
+	mp := sdkmetric.NewMeterProvider(mpOpts...)
+	recordPipeline(service, res, nil)
 	otel.SetMeterProvider(mp)
 
-	meter := mp.Meter(service)
+	meter := mp.Meter(service, meterOptions()...)
+	m, err := newMetrics(meter)
+	if err != nil {
+		logf("failed to create instruments: %v\n", err)
+		return emptyCleanup
+	}
+	metrics, globalMeter = m, meter
+	metricsEnabled.Store(true)
+
+	shutdown := registerShutdown("metrics", func(ctx context.Context) error {
+		metricsEnabled.Store(false)
+		return mp.Shutdown(ctx)
+	})
+
+	return func() {
+		if err := shutdown(ctx); err != nil {
+			logf("error shutting down meter provider: %v", err)
+		}
+	}
+}
+
+// meterProviderOptions builds the MeterProvider options shared by
+// NewMeterProvider and NewService: periodic readers for the instrumented OTLP
+// exporter on conn and any extra exporters, plus the configured readers.
+func meterProviderOptions(ctx context.Context, conn *grpc.ClientConn, cfg config, res *resource.Resource) ([]sdkmetric.Option, error) {
+	// Record export results and latency for every exporter, renaming
+	// metrics first when Prometheus naming is enabled.
+	wrap := func(exp sdkmetric.Exporter, buffered bool) sdkmetric.Exporter {
+		exp = instrumentMetricExporter(exp)
+		if buffered {
+			// Keep metrics on disk while the collector is unreachable.
+			exp = newDiskBufferMetricExporter(exp, *cfg.diskBuffer)
+		}
+		if cfg.prometheusNamingEnabled() {
+			exp = prometheusNamingExporter{Exporter: exp}
+		}
+		if cfg.cardinalityReport > 0 {
+			exp = cardinalityExporter{Exporter: exp, interval: cfg.cardinalityReport}
+		}
+		return exp
+	}
+
+	mpOpts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+
+	// conn is nil when the local profile runs without a collector.
+	if conn != nil {
+		metricExporter, err := otlpmetricgrpc.New(ctx, append(cfg.metricExporterOptions(), otlpmetricgrpc.WithGRPCConn(conn))...)
+		if err != nil {
+			return nil, err
+		}
+		mpOpts = append(mpOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(wrap(metricExporter, cfg.diskBuffer != nil))))
+	}
+	for _, exp := range cfg.extraMetricExporters() {
+		mpOpts = append(mpOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(wrap(exp, false))))
+	}
+	for _, r := range cfg.metricReaders {
+		mpOpts = append(mpOpts, sdkmetric.WithReader(r))
+	}
+	if view := cfg.metricAttributesView(); view != nil {
+		mpOpts = append(mpOpts, sdkmetric.WithView(view))
+	}
+	return mpOpts, nil
+}
+
+// noopMetrics returns Metrics backed by no-op instruments.
+func noopMetrics() Metrics {
+	m, _ := newMetrics(metricnoop.NewMeterProvider().Meter(instrumentationName))
+	return m
+}
 
-	// Initialize metrics
+// newMetrics creates the request instruments listed on NewMeterProvider from
+// meter.
+func newMetrics(meter api.Meter) (Metrics, error) {
 	counter, err := meter.Int64Counter(
-		"http_requests_total",
-		api.WithDescription("Total number of HTTP requests"),
+		instrumentName("http_requests_total"),
+		instrumentDescription("http_requests_total", "Total number of HTTP requests"),
+		api.WithUnit("{request}"),
 	)
 	if err != nil {
-		log.Printf("failed to create counter: %s\n", err.Error())
-		return emptyCleanup
+		return Metrics{}, fmt.Errorf("http_requests_total: %w", err)
 	}
 
 	histogram, err := meter.Float64Histogram(
-		"http_request_duration_seconds",
-		api.WithDescription("HTTP request duration in seconds"),
-		api.WithExplicitBucketBoundaries(
+		durationInstrumentName("http_request_duration_seconds"),
+		instrumentDescription("http_request_duration_seconds", durationDescription("HTTP request duration in seconds")),
+		api.WithUnit(durationUnit()),
+		api.WithExplicitBucketBoundaries(durationBuckets(
 			0.1, 0.2, 0.3, 0.4, 0.5,
 			0.6, 0.7, 0.8, 0.9, 1.0,
 			2.0, 5.0,
+		)...),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("http_request_duration_seconds: %w", err)
+	}
+
+	sloBreaches, err := meter.Int64Counter(
+		instrumentName("slo_breaches_total"),
+		instrumentDescription("slo_breaches_total", "Total number of requests exceeding their latency objective"),
+		api.WithUnit("{request}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("slo_breaches_total: %w", err)
+	}
+
+	slowRequests, err := meter.Int64Counter(
+		instrumentName("slow_requests_total"),
+		instrumentDescription("slow_requests_total", "Total number of requests exceeding their slow threshold"),
+		api.WithUnit("{request}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("slow_requests_total: %w", err)
+	}
+
+	httpErrors, err := meter.Int64Counter(
+		instrumentName("http_errors_total"),
+		instrumentDescription("http_errors_total", "Total number of HTTP requests answered with a 5xx status"),
+		api.WithUnit("{request}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("http_errors_total: %w", err)
+	}
+
+	rpcErrors, err := meter.Int64Counter(
+		instrumentName("rpc_errors_total"),
+		instrumentDescription("rpc_errors_total", "Total number of gRPC calls finished with a non-OK status"),
+		api.WithUnit("{call}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("rpc_errors_total: %w", err)
+	}
+
+	panics, err := meter.Int64Counter(
+		instrumentName("panics_total"),
+		instrumentDescription("panics_total", "Total number of recovered handler panics"),
+		api.WithUnit("{panic}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("panics_total: %w", err)
+	}
+
+	handlerErrors, err := meter.Int64Counter(
+		instrumentName("http_handler_errors_total"),
+		instrumentDescription("http_handler_errors_total", "Total number of errors returned by HandlerFunc handlers"),
+		api.WithUnit("{error}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("http_handler_errors_total: %w", err)
+	}
+
+	clientCounter, err := meter.Int64Counter(
+		instrumentName("rpc_client_requests_total"),
+		instrumentDescription("rpc_client_requests_total", "Total number of outgoing gRPC calls"),
+		api.WithUnit("{call}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("rpc_client_requests_total: %w", err)
+	}
+
+	clientHistogram, err := meter.Float64Histogram(
+		durationInstrumentName("rpc_client_duration_seconds"),
+		instrumentDescription("rpc_client_duration_seconds", durationDescription("Outgoing gRPC call duration in seconds")),
+		api.WithUnit(durationUnit()),
+		api.WithExplicitBucketBoundaries(durationBuckets(
+			0.005, 0.01, 0.025, 0.05, 0.1,
+			0.25, 0.5, 1.0, 2.5, 5.0,
+		)...),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("rpc_client_duration_seconds: %w", err)
+	}
+
+	sizeBuckets := api.WithExplicitBucketBoundaries(
+		1<<8, 1<<10, 1<<12, 1<<14, 1<<16,
+		1<<18, 1<<20, 1<<22, 1<<24,
+	)
+
+	clientRequestSize, err := meter.Int64Histogram(
+		instrumentName("http_client_request_size_bytes"),
+		instrumentDescription("http_client_request_size_bytes", "Body size of outgoing HTTP requests in bytes"),
+		api.WithUnit("By"),
+		sizeBuckets,
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("http_client_request_size_bytes: %w", err)
+	}
+
+	clientResponseSize, err := meter.Int64Histogram(
+		instrumentName("http_client_response_size_bytes"),
+		instrumentDescription("http_client_response_size_bytes", "Body size of responses to outgoing HTTP requests in bytes"),
+		api.WithUnit("By"),
+		sizeBuckets,
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("http_client_response_size_bytes: %w", err)
+	}
+
+	streamMessages, err := meter.Float64Histogram(
+		instrumentName("rpc_stream_message_interval_seconds"),
+		instrumentDescription("rpc_stream_message_interval_seconds", "Interval between messages of streaming RPCs in seconds"),
+		api.WithUnit("s"),
+		api.WithExplicitBucketBoundaries(
+			0.001, 0.005, 0.01, 0.025, 0.05,
+			0.1, 0.25, 0.5, 1.0, 5.0,
 		),
 	)
 	if err != nil {
-		log.Printf("failed to create histogram: %s\n", err.Error())
-		return emptyCleanup
+		return Metrics{}, fmt.Errorf("rpc_stream_message_interval_seconds: %w", err)
 	}
 
-	metrics = Metrics{
-		RequestCounter:   counter,
-		RequestHistogram: histogram,
+	activeStreams, err := meter.Int64UpDownCounter(
+		instrumentName("rpc_server_active_streams"),
+		instrumentDescription("rpc_server_active_streams", "Number of streaming RPCs in progress"),
+		api.WithUnit("{stream}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("rpc_server_active_streams: %w", err)
 	}
 
-	shutdown := func() {
-		if err := mp.Shutdown(ctx); err != nil {
-			log.Printf("error shutting down meter provider: %v", err)
-		}
+	openConns, err := meter.Int64UpDownCounter(
+		instrumentName("http_server_open_connections"),
+		instrumentDescription("http_server_open_connections", "Number of open HTTP server connections by state"),
+		api.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("http_server_open_connections: %w", err)
 	}
 
-	return shutdown
+	connEvents, err := meter.Int64Counter(
+		instrumentName("http_server_connection_events_total"),
+		instrumentDescription("http_server_connection_events_total", "Total number of HTTP server connection state transitions"),
+		api.WithUnit("{event}"),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("http_server_connection_events_total: %w", err)
+	}
+
+	tlsHandshakes, err := meter.Float64Histogram(
+		instrumentName("tls_handshake_duration_seconds"),
+		instrumentDescription("tls_handshake_duration_seconds", "Inbound TLS handshake duration in seconds"),
+		api.WithUnit("s"),
+		api.WithExplicitBucketBoundaries(
+			0.001, 0.0025, 0.005, 0.01, 0.025,
+			0.05, 0.1, 0.25, 0.5, 1.0,
+		),
+	)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("tls_handshake_duration_seconds: %w", err)
+	}
+
+	return Metrics{
+		RequestCounter:     counter,
+		RequestHistogram:   histogram,
+		SLOBreachCounter:   sloBreaches,
+		SlowRequestCounter: slowRequests,
+		HTTPErrorCounter:   httpErrors,
+		RPCErrorCounter:    rpcErrors,
+		PanicCounter:       panics,
+		RPCClientCounter:   clientCounter,
+		RPCClientHistogram: clientHistogram,
+		ClientRequestSize:  clientRequestSize,
+		ClientResponseSize: clientResponseSize,
+
+		StreamMessageHistogram: streamMessages,
+		ActiveStreams:          activeStreams,
+		OpenConnections:        openConns,
+		ConnectionEvents:       connEvents,
+		TLSHandshakeHistogram:  tlsHandshakes,
+		HandlerErrorCounter:    handlerErrors,
+	}, nil
 }
 
-// StartSpan creates a new span using the globally registered tracer.
+// StartSpan creates a new span using the globally registered tracer, or the
+// tracer of the Service carried by ctx (see NewService).
 //
 // Features:
 //   - Automatically generates the span name using caller function name + line
@@ -286,13 +817,25 @@ func NewMeterProvider(ctx context.Context, service string) func() {
 //	ctx, span := otelx.StartSpan(ctx)
 //	defer span.End()
 //
+// Spans default to the INTERNAL kind. Use trace.WithSpanKind for other kinds,
+// or the dedicated helpers: TracingMiddleware and the gRPC tracing
+// interceptors create SERVER spans, HTTPClient and the gRPC client
+// interceptors create CLIENT spans, and StartProducerSpan/StartConsumerSpan
+// create PRODUCER/CONSUMER spans.
+//
+// Attributes returned by the WithContextExtractors extractors are added to
+// every span. With WithProfilerLabels, the goroutine carries trace_id and
+// span_name pprof labels until the span ends.
+//
 // This helper is designed for internal code paths where manually naming each
 // span would be verbose. For API-level or logical spans, prefer explicit names:
 //
 //	ctx, span := tracer.Start(ctx, "database.query")
 func StartSpan(ctx context.Context, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
-	if tracer == nil || grpcConnection == nil {
+	t := tracerFor(ctx)
+	if t == nil {
 		// Use the noop tracer provider
+		countSuppressedSpan()
 		noopTracer := noop.NewTracerProvider().Tracer("noop")
 		return noopTracer.Start(ctx, "noop", opts...)
 	}
@@ -301,5 +844,7 @@ func StartSpan(ctx context.Context, opts ...trace.SpanStartOption) (context.Cont
 	pc, _, line, _ := runtime.Caller(1)
 	fn := runtime.FuncForPC(pc)
 
-	return tracer.Start(ctx, fmt.Sprintf("%s:%d", fn.Name(), line), opts...)
+	name := fmt.Sprintf("%s:%d", fn.Name(), line)
+	spanCtx, span := t.Start(ctx, name, extractedAttributes(ctx, opts)...)
+	return withProfilerLabels(ctx, spanCtx, span, name)
 }
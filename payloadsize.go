@@ -0,0 +1,45 @@
+package otelx
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// exportSignals maps the OTLP collector services to their signal.
+var exportSignals = map[string]string{
+	"opentelemetry.proto.collector.trace.v1.TraceService":     "traces",
+	"opentelemetry.proto.collector.metrics.v1.MetricsService": "metrics",
+	"opentelemetry.proto.collector.logs.v1.LogsService":       "logs",
+}
+
+// payloadSizeInterceptor records the serialized size of every OTLP export
+// request sent over the collector connection in
+// otelx_export_payload_bytes{signal}. Each attempt is recorded, retries
+// included, so the histogram sums to the bandwidth actually used; its count
+// gives the export frequency.
+//
+// Connections passed with WithCollectorConn are not intercepted.
+func payloadSizeInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if msg, ok := req.(proto.Message); ok {
+		service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+		signal, ok := exportSignals[service]
+		if !ok {
+			signal = service
+		}
+		selfTelemetry().payloadBytes.Record(context.Background(), int64(proto.Size(msg)),
+			api.WithAttributes(attribute.String("signal", signal)))
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
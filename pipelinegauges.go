@@ -0,0 +1,106 @@
+package otelx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// pipelineState holds the span queues and metric exporters observed by the
+// pipeline gauges. Entries are removed when their processor or exporter is
+// shut down.
+var pipelineState struct {
+	mu        sync.Mutex
+	queues    map[*spanQueue]struct{}
+	exporters map[*instrumentedMetricExporter]struct{}
+}
+
+// trackSpanQueue adds q to the span queue utilization gauge.
+func trackSpanQueue(q *spanQueue) {
+	pipelineState.mu.Lock()
+	defer pipelineState.mu.Unlock()
+	if pipelineState.queues == nil {
+		pipelineState.queues = make(map[*spanQueue]struct{})
+	}
+	pipelineState.queues[q] = struct{}{}
+}
+
+// untrackSpanQueue removes q from the span queue utilization gauge.
+func untrackSpanQueue(q *spanQueue) {
+	pipelineState.mu.Lock()
+	defer pipelineState.mu.Unlock()
+	delete(pipelineState.queues, q)
+}
+
+// trackMetricExporter adds e to the metric export lag gauge.
+func trackMetricExporter(e *instrumentedMetricExporter) {
+	pipelineState.mu.Lock()
+	defer pipelineState.mu.Unlock()
+	if pipelineState.exporters == nil {
+		pipelineState.exporters = make(map[*instrumentedMetricExporter]struct{})
+	}
+	pipelineState.exporters[e] = struct{}{}
+}
+
+// untrackMetricExporter removes e from the metric export lag gauge.
+func untrackMetricExporter(e *instrumentedMetricExporter) {
+	pipelineState.mu.Lock()
+	defer pipelineState.mu.Unlock()
+	delete(pipelineState.exporters, e)
+}
+
+// registerPipelineGauges creates the observable gauges describing how close
+// the pipeline is to losing telemetry:
+//
+//   - otelx_span_queue_utilization{exporter}: queued spans over the batch
+//     queue size, from 0 to 1; spans are dropped at 1
+//   - otelx_metric_export_lag_seconds{exporter}: time since the periodic
+//     reader last completed an export, which stays close to the export
+//     interval while the reader keeps up
+func registerPipelineGauges(meter api.Meter) {
+	queueUtilization, err := meter.Float64ObservableGauge(
+		instrumentName("otelx_span_queue_utilization"),
+		instrumentDescription("otelx_span_queue_utilization", "Fraction of the batch span processor queue in use"),
+		api.WithUnit("1"),
+	)
+	if err != nil {
+		logf("failed to create span queue utilization gauge: %v\n", err)
+		return
+	}
+
+	exportLag, err := meter.Float64ObservableGauge(
+		instrumentName("otelx_metric_export_lag_seconds"),
+		instrumentDescription("otelx_metric_export_lag_seconds", "Time since the periodic metric reader last completed an export"),
+		api.WithUnit("s"),
+	)
+	if err != nil {
+		logf("failed to create metric export lag gauge: %v\n", err)
+		return
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o api.Observer) error {
+		now := time.Now()
+
+		pipelineState.mu.Lock()
+		defer pipelineState.mu.Unlock()
+
+		for q := range pipelineState.queues {
+			o.ObserveFloat64(queueUtilization,
+				float64(q.inflight.Load())/float64(q.max),
+				api.WithAttributes(attribute.String("exporter", q.exporter)))
+		}
+		for e := range pipelineState.exporters {
+			last := time.Unix(0, e.lastExport.Load())
+			o.ObserveFloat64(exportLag,
+				now.Sub(last).Seconds(),
+				api.WithAttributes(attribute.String("exporter", e.name)))
+		}
+		return nil
+	}, queueUtilization, exportLag)
+	if err != nil {
+		logf("failed to register pipeline gauges: %v\n", err)
+	}
+}
@@ -0,0 +1,56 @@
+package otelx
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithProfilerLabels makes StartSpan set pprof labels on the calling
+// goroutine for the duration of the span:
+//
+//	trace_id   the span's trace ID
+//	span_name  the span name
+//
+// CPU and goroutine profiles can then be sliced by endpoint or by trace during
+// incident analysis (for example with `go tool pprof -tagfocus`). The previous
+// labels are restored when the span ends; End must be called on the goroutine
+// that started the span for that to apply. Goroutines started with the
+// returned context inherit the labels.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service", otelx.WithProfilerLabels())
+func WithProfilerLabels() Option {
+	return func(c *config) {
+		c.profilerLabels = true
+	}
+}
+
+// labeledSpan restores the goroutine's previous pprof labels when it ends.
+type labeledSpan struct {
+	trace.Span
+	parent context.Context
+}
+
+// End ends the span and restores the labels of the parent context.
+func (s labeledSpan) End(opts ...trace.SpanEndOption) {
+	s.Span.End(opts...)
+	pprof.SetGoroutineLabels(s.parent)
+}
+
+// withProfilerLabels labels the goroutine with span when WithProfilerLabels
+// is set. parent is the context the span was started from.
+func withProfilerLabels(parent, ctx context.Context, span trace.Span, name string) (context.Context, trace.Span) {
+	if !settings().profilerLabels || !span.SpanContext().IsValid() {
+		return ctx, span
+	}
+
+	ctx = pprof.WithLabels(ctx, pprof.Labels(
+		"trace_id", span.SpanContext().TraceID().String(),
+		"span_name", name,
+	))
+	pprof.SetGoroutineLabels(ctx)
+	return ctx, labeledSpan{Span: span, parent: parent}
+}
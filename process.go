@@ -0,0 +1,129 @@
+package otelx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Environment variables set by ChildProcessEnv.
+const (
+	// hostAttributesEnv carries the parent's detected host attributes (see
+	// WithResourceDetectors), as comma-separated key=value pairs with
+	// percent-encoded values.
+	hostAttributesEnv = "OTELX_HOST_ATTRIBUTES"
+	// parentPIDEnv carries the parent's process ID.
+	parentPIDEnv = "OTELX_PARENT_PID"
+)
+
+// ChildProcessEnv returns the environment for a pre-forked worker or plugin
+// subprocess: the current environment, which already carries the otelx
+// configuration (OTEL_ENABLE, OTEL_COLLECTOR_ENDPOINT, OTELX_CONFIG_FILE...),
+// plus the host attributes detected by this process and its process ID.
+//
+// A child started with it, and initialized with InitChildProcess or the
+// regular providers:
+//
+//   - reuses the parent's host detection instead of repeating it
+//   - gets its own service.instance.id, process.pid and process.parent_pid
+//     resource attributes, so workers of the same service do not overwrite
+//     each other's metric series
+//
+// Options passed to the parent's providers are not inherited; use
+// OTELX_CONFIG_FILE to share settings other than the environment variables.
+//
+// Example:
+//
+//	cmd := exec.Command(os.Args[0], "worker")
+//	cmd.Env = otelx.ChildProcessEnv(ctx)
+//	err := cmd.Start()
+func ChildProcessEnv(ctx context.Context) []string {
+	var pairs []string
+	for _, kv := range detectedAttributes(ctx) {
+		pairs = append(pairs, string(kv.Key)+"="+url.PathEscape(kv.Value.Emit()))
+	}
+
+	env := os.Environ()
+	kept := env[:0]
+	for _, e := range env {
+		if !strings.HasPrefix(e, hostAttributesEnv+"=") && !strings.HasPrefix(e, parentPIDEnv+"=") {
+			kept = append(kept, e)
+		}
+	}
+	return append(kept,
+		hostAttributesEnv+"="+strings.Join(pairs, ","),
+		parentPIDEnv+"="+strconv.Itoa(os.Getpid()),
+	)
+}
+
+// IsChildProcess reports whether the process was started with
+// ChildProcessEnv.
+func IsChildProcess() bool {
+	_, ok := os.LookupEnv(parentPIDEnv)
+	return ok
+}
+
+// InitChildProcess initializes tracing and metrics in a process started with
+// ChildProcessEnv, like NewTraceProvider and NewMeterProvider. The returned
+// cleanup flushes and shuts both down; call it before the worker exits, as
+// its buffered telemetry is lost otherwise.
+//
+// Example:
+//
+//	func workerMain(ctx context.Context) {
+//	    cleanup := otelx.InitChildProcess(ctx, "image-worker")
+//	    defer cleanup()
+//	    ...
+//	}
+func InitChildProcess(ctx context.Context, service string, opts ...Option) func() {
+	_, cleanupTrace := NewTraceProvider(ctx, service, opts...)
+	cleanupMetrics := NewMeterProvider(ctx, service, opts...)
+	return func() {
+		cleanupTrace()
+		cleanupMetrics()
+	}
+}
+
+// parseHostAttributes parses the value of OTELX_HOST_ATTRIBUTES, skipping
+// malformed pairs.
+func parseHostAttributes(v string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, pair := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(val); err == nil {
+			val = unescaped
+		}
+		attrs = append(attrs, attribute.String(k, val))
+	}
+	return attrs
+}
+
+// processAttributes returns the per-process resource attributes of a child
+// started with ChildProcessEnv, or nil for other processes.
+func processAttributes() []attribute.KeyValue {
+	ppid, err := strconv.Atoi(os.Getenv(parentPIDEnv))
+	if err != nil {
+		return nil
+	}
+
+	host, _ := os.Hostname()
+	for _, kv := range detected.attrs {
+		if kv.Key == semconv.HostNameKey {
+			host = kv.Value.AsString()
+		}
+	}
+	return []attribute.KeyValue{
+		semconv.ProcessPID(os.Getpid()),
+		semconv.ProcessParentPID(ppid),
+		semconv.ServiceInstanceID(fmt.Sprintf("%s-%d", host, os.Getpid())),
+	}
+}
@@ -0,0 +1,114 @@
+package otelx
+
+import (
+	"os"
+	"strings"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// profile holds environment-aware defaults. Explicit Options and environment
+// variables always take precedence over them.
+type profile struct {
+	// stdout enables the stdout exporters.
+	stdout bool
+
+	// standalone exports to the stdout exporters alone, without dialing a
+	// collector, when none is configured (see localStandalone).
+	standalone bool
+
+	// samplerRatio is the sampling ratio used when OTEL_TRACES_SAMPLER_ARG is
+	// not set.
+	samplerRatio float64
+
+	// strictLimits tightens the span limits not set through OTEL_SPAN_*
+	// variables.
+	strictLimits bool
+}
+
+// profiles are the built-in profiles, keyed on the ENV value.
+//
+//	local  stdout exporters in addition to the collector, or alone without
+//	       OTEL_ENABLE when no collector is configured, AlwaysSample
+//	dev    AlwaysSample to the collector
+//	prod   parent-based 10% ratio sampling and stricter span limits
+var profiles = map[string]profile{
+	"local": {stdout: true, standalone: true, samplerRatio: 1},
+	"dev":   {samplerRatio: 1},
+	"prod":  {samplerRatio: 0.1, strictLimits: true},
+}
+
+// defaultProfile is used for unknown environments and when profiles are
+// disabled; it matches otelx's historical behavior.
+var defaultProfile = profile{samplerRatio: 1}
+
+// WithProfile selects the defaults profile explicitly instead of deriving it
+// from ENV. Known profiles are "local", "dev" and "prod"; any other name,
+// including "none", disables profile defaults.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service", otelx.WithProfile("prod"))
+func WithProfile(name string) Option {
+	return func(c *config) {
+		c.profile = &name
+	}
+}
+
+// activeProfile returns the profile selected with WithProfile, or the one
+// matching ENV.
+func activeProfile() profile {
+	name := os.Getenv("ENV")
+	if settings().profile != nil {
+		name = *settings().profile
+	}
+	if p, ok := profiles[name]; ok {
+		return p
+	}
+	return defaultProfile
+}
+
+// localStandalone reports whether telemetry is only written to stdout, without
+// a collector: in the local profile when neither OTEL_COLLECTOR_ENDPOINT nor
+// WithCollectorConn is set. OTEL_ENABLE does not need to be set then, but
+// OTEL_ENABLE=false still disables telemetry.
+func localStandalone(cfg config) bool {
+	if !activeProfile().standalone || cfg.collectorConn != nil || grpcConnection != nil {
+		return false
+	}
+	return os.Getenv("OTEL_COLLECTOR_ENDPOINT") == "" &&
+		strings.ToLower(os.Getenv("OTEL_ENABLE")) != "false"
+}
+
+// Strict span limits applied by the prod profile.
+const (
+	strictAttributeCountLimit       = 64
+	strictEventCountLimit           = 64
+	strictLinkCountLimit            = 32
+	strictAttributeValueLengthLimit = 4096
+)
+
+// spanLimits returns the span limits for p. The OTEL_SPAN_* and
+// OTEL_ATTRIBUTE_* environment variables keep precedence.
+func (p profile) spanLimits() sdktrace.SpanLimits {
+	limits := sdktrace.NewSpanLimits()
+	if !p.strictLimits {
+		return limits
+	}
+
+	tighten := func(field *int, value int, vars ...string) {
+		for _, v := range vars {
+			if _, ok := os.LookupEnv(v); ok {
+				return
+			}
+		}
+		*field = value
+	}
+	tighten(&limits.AttributeCountLimit, strictAttributeCountLimit,
+		"OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT", "OTEL_ATTRIBUTE_COUNT_LIMIT")
+	tighten(&limits.EventCountLimit, strictEventCountLimit, "OTEL_SPAN_EVENT_COUNT_LIMIT")
+	tighten(&limits.LinkCountLimit, strictLinkCountLimit, "OTEL_SPAN_LINK_COUNT_LIMIT")
+	tighten(&limits.AttributeValueLengthLimit, strictAttributeValueLengthLimit,
+		"OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT")
+	return limits
+}
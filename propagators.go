@@ -0,0 +1,127 @@
+package otelx
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultPropagators is used when OTEL_PROPAGATORS is not set.
+const defaultPropagators = "tracecontext,baggage"
+
+// newPropagator builds the global propagator from OTEL_PROPAGATORS, a comma
+// separated list of tracecontext, baggage, b3, b3multi, jaeger, xray or none,
+// defaulting to W3C TraceContext and baggage. Unknown names are logged and
+// skipped.
+//
+// The legacy correlation headers (see WithCorrelationKeys) are mapped onto
+// baggage, so they are only propagated when baggage is listed.
+func newPropagator() propagation.TextMapPropagator {
+	names := os.Getenv("OTEL_PROPAGATORS")
+	if strings.TrimSpace(names) == "" {
+		names = defaultPropagators
+	}
+
+	var props []propagation.TextMapPropagator
+	for name := range strings.SplitSeq(names, ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "tracecontext":
+			props = append(props, propagation.TraceContext{})
+		case "baggage":
+			props = append(props, propagation.Baggage{}, correlationPropagator{})
+		case "b3":
+			props = append(props, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			props = append(props, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			props = append(props, jaeger.Jaeger{})
+		case "xray":
+			props = append(props, xrayPropagator{})
+		case "none":
+			return propagation.NewCompositeTextMapPropagator()
+		case "":
+		default:
+			logf("unsupported propagator %q in OTEL_PROPAGATORS\n", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(props...)
+}
+
+// xrayTraceHeader is the AWS X-Ray tracing header.
+const xrayTraceHeader = "X-Amzn-Trace-Id"
+
+// xrayPropagator propagates span contexts in the AWS X-Ray format:
+//
+//	X-Amzn-Trace-Id: Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+type xrayPropagator struct{}
+
+var _ propagation.TextMapPropagator = xrayPropagator{}
+
+// Inject writes the span context of ctx into carrier.
+func (xrayPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanFromContext(ctx).SpanContext()
+	if !sc.IsValid() {
+		return
+	}
+
+	tid := sc.TraceID().String()
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	carrier.Set(xrayTraceHeader,
+		"Root=1-"+tid[:8]+"-"+tid[8:]+";Parent="+sc.SpanID().String()+";Sampled="+sampled)
+}
+
+// Extract reads a remote span context from carrier. Malformed headers are
+// ignored.
+func (xrayPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	header := carrier.Get(xrayTraceHeader)
+	if header == "" {
+		return ctx
+	}
+
+	cfg := trace.SpanContextConfig{Remote: true}
+	for part := range strings.SplitSeq(header, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "Root":
+			version, rest, _ := strings.Cut(value, "-")
+			epoch, random, ok := strings.Cut(rest, "-")
+			if version != "1" || !ok {
+				return ctx
+			}
+			tid, err := trace.TraceIDFromHex(epoch + random)
+			if err != nil {
+				return ctx
+			}
+			cfg.TraceID = tid
+		case "Parent":
+			sid, err := trace.SpanIDFromHex(value)
+			if err != nil {
+				return ctx
+			}
+			cfg.SpanID = sid
+		case "Sampled":
+			if value == "1" {
+				cfg.TraceFlags = trace.FlagsSampled
+			}
+		}
+	}
+
+	sc := trace.NewSpanContext(cfg)
+	if !sc.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the header handled by the propagator.
+func (xrayPropagator) Fields() []string {
+	return []string{xrayTraceHeader}
+}
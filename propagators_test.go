@@ -0,0 +1,80 @@
+package otelx
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestXRayPropagatorRoundTrip(t *testing.T) {
+	tid, _ := trace.TraceIDFromHex("5759e988bd862e3fe1be46a994272793")
+	sid, _ := trace.SpanIDFromHex("53995c3f42cd8ad8")
+
+	tests := []struct {
+		name   string
+		flags  trace.TraceFlags
+		header string
+	}{
+		{
+			name:   "sampled",
+			flags:  trace.FlagsSampled,
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+		},
+		{
+			name:   "not sampled",
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: tt.flags})
+			ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+			carrier := propagation.MapCarrier{}
+			xrayPropagator{}.Inject(ctx, carrier)
+			if got := carrier.Get(xrayTraceHeader); got != tt.header {
+				t.Fatalf("injected %q, want %q", got, tt.header)
+			}
+
+			got := trace.SpanContextFromContext(xrayPropagator{}.Extract(context.Background(), carrier))
+			if !got.Equal(sc.WithRemote(true)) {
+				t.Errorf("extracted %v, want %v", got, sc.WithRemote(true))
+			}
+		})
+	}
+}
+
+func TestXRayPropagatorExtractMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "empty", header: ""},
+		{name: "wrong version", header: "Root=2-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+		{name: "missing random part", header: "Root=1-5759e988bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8"},
+		{name: "bad trace ID", header: "Root=1-5759e988-zz862e3fe1be46a994272793;Parent=53995c3f42cd8ad8"},
+		{name: "bad parent", header: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=xyz"},
+		{name: "no parent", header: "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			carrier := propagation.MapCarrier{xrayTraceHeader: tt.header}
+			ctx := xrayPropagator{}.Extract(context.Background(), carrier)
+			if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+				t.Errorf("extracted %v from %q, want none", sc, tt.header)
+			}
+		})
+	}
+}
+
+func TestXRayPropagatorInjectInvalid(t *testing.T) {
+	carrier := propagation.MapCarrier{}
+	xrayPropagator{}.Inject(context.Background(), carrier)
+	if len(carrier) != 0 {
+		t.Errorf("injected %v without a span context", carrier)
+	}
+}
@@ -0,0 +1,88 @@
+package otelx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordPanic reports a recovered panic: it records an exception event with
+// the stack trace on the active span, marks the span as failed, increments
+// panics_total with attrs and logs the panic. It returns the panic as an
+// error.
+func recordPanic(ctx context.Context, rec any, attrs ...attribute.KeyValue) error {
+	stack := debug.Stack()
+	err := fmt.Errorf("panic: %v", rec)
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(
+		attribute.String("exception.stacktrace", string(stack)),
+	))
+	span.SetStatus(codes.Error, err.Error())
+
+	if m := instrumentsFor(ctx); m != nil {
+		m.PanicCounter.Add(ctx, 1, api.WithAttributes(attrs...))
+	}
+
+	logf("recovered from %v\n%s", err, stack)
+	return err
+}
+
+// UnaryServerRecoveryInterceptor returns a gRPC unary server interceptor that
+// recovers from handler panics, records them like RecoveryMiddleware does for
+// HTTP (exception event with stack trace, panics_total) and returns an
+// Internal error to the caller.
+//
+// Register it last so the tracing and metrics interceptors observe the
+// Internal status:
+//
+//	grpc.ChainUnaryInterceptor(
+//	    otelx.UnaryServerTracingInterceptor(),
+//	    otelx.UnaryServerMetricsInterceptor(),
+//	    otelx.UnaryServerRecoveryInterceptor(),
+//	)
+func UnaryServerRecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp any, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				recordPanic(ctx, rec, attribute.String("method", info.FullMethod))
+				err = status.Error(grpccodes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecoveryInterceptor is the streaming counterpart of
+// UnaryServerRecoveryInterceptor.
+func StreamServerRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				recordPanic(ss.Context(), rec, attribute.String("method", info.FullMethod))
+				err = status.Error(grpccodes.Internal, "internal error")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
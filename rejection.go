@@ -0,0 +1,162 @@
+package otelx
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+// RejectedRateLimit is the rejection reason of requests turned away by a rate
+// limiter.
+const RejectedRateLimit = "rate_limit"
+
+// RecordRejectedRequest records an HTTP request rejected with status before
+// reaching the otelx middleware, typically by a rate limiter placed in front
+// of it. Rejected requests otherwise bypass the instrumentation, and traffic
+// numbers understate the load actually received.
+//
+// The request is counted in http_requests_total with a rejected=reason
+// attribute (and in http_errors_total for 5xx statuses), and a minimal SERVER
+// span continuing the caller's trace records it with otelx.rejected = reason.
+// Its latency is not recorded, so rejections do not skew the request
+// histogram.
+//
+// Example:
+//
+//	if !limiter.Allow() {
+//	    http.Error(w, "too many requests", http.StatusTooManyRequests)
+//	    otelx.RecordRejectedRequest(r, http.StatusTooManyRequests, otelx.RejectedRateLimit)
+//	    return
+//	}
+func RecordRejectedRequest(r *http.Request, status int, reason string) {
+	ctx := r.Context()
+
+	if t := tracerFor(ctx); t != nil && !telemetryPaused.Load() {
+		ctx := otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+		_, span := t.Start(ctx, serverSpanName(r),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.Int("http.response.status_code", status),
+				attribute.String("otelx.rejected", reason),
+			),
+		)
+		span.End()
+	}
+
+	m := instrumentsFor(ctx)
+	if m == nil || telemetryPaused.Load() {
+		return
+	}
+	attrs := requestAttrs.get(attrKey{
+		method:    r.Method,
+		path:      metricPath(r, status),
+		code:      status,
+		http:      true,
+		synthetic: syntheticDimension(r),
+		rejected:  reason,
+	})
+	m.RequestCounter.Add(ctx, 1, attrs)
+	if status >= http.StatusInternalServerError {
+		m.HTTPErrorCounter.Add(ctx, 1, attrs)
+	}
+}
+
+// rejectionKey is the context key marking requests that got past the limiter
+// wrapped by RejectionMiddleware.
+type rejectionKey struct{}
+
+// RejectionMiddleware wraps an existing rate limiting middleware so that the
+// requests it rejects are recorded with RecordRejectedRequest: a request the
+// limiter answers without calling the next handler counts as rejected for
+// reason, with the status the limiter wrote. Place it in front of the otelx
+// middleware.
+//
+// Example:
+//
+//	limited := otelx.RejectionMiddleware(otelx.RejectedRateLimit, limiter.Middleware)
+//	handler := limited(otelx.TracingMiddleware(otelx.MetricsMiddleware(mux)))
+func RejectionMiddleware(reason string, limiter func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limited := limiter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if passed, ok := r.Context().Value(rejectionKey{}).(*bool); ok {
+				*passed = true
+			}
+			next.ServeHTTP(w, r)
+			recordRoute(r)
+		}))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			passed := new(bool)
+			rw := NewResponseWriter(w)
+			r = r.WithContext(context.WithValue(r.Context(), rejectionKey{}, passed))
+			limited.ServeHTTP(rw, r)
+			recordRoute(r)
+			if !*passed {
+				RecordRejectedRequest(r, rw.Status(), reason)
+			}
+		})
+	}
+}
+
+// RecordRejectedRPC records a gRPC call to fullMethod rejected with err
+// before reaching the otelx interceptors. It is counted in the request counter
+// and rpc_errors_total like calls measured by UnaryServerMetricsInterceptor,
+// with a rejected=reason attribute, and recorded as a minimal SERVER span;
+// see RecordRejectedRequest.
+func RecordRejectedRPC(ctx context.Context, fullMethod string, err error, reason string) {
+	code := status.Code(err)
+
+	if t := tracerFor(ctx); t != nil && !telemetryPaused.Load() {
+		_, span := startServerSpan(ctx, t, fullMethod)
+		span.SetAttributes(attribute.String("otelx.rejected", reason))
+		endRPCSpan(span, err, true)
+	}
+
+	m := instrumentsFor(ctx)
+	if m == nil || telemetryPaused.Load() {
+		return
+	}
+	attrs := requestAttrs.get(attrKey{
+		method:   fullMethod,
+		code:     int(code),
+		rejected: reason,
+	})
+	m.RequestCounter.Add(ctx, 1, attrs)
+	if code != codes.OK {
+		m.RPCErrorCounter.Add(ctx, 1, attrs)
+	}
+}
+
+// RejectionTapHandle wraps a gRPC tap handler, typically a rate limiter
+// installed with grpc.InTapHandle, so the calls it rejects are recorded with
+// RecordRejectedRPC. Tap handlers run before the interceptors, so rejected
+// calls are otherwise invisible.
+//
+// Example:
+//
+//	grpc.NewServer(
+//	    grpc.InTapHandle(otelx.RejectionTapHandle(otelx.RejectedRateLimit, limiter.Tap)),
+//	    otelx.ChainUnaryInterceptors(),
+//	)
+func RejectionTapHandle(reason string, handle tap.ServerInHandle) tap.ServerInHandle {
+	return func(ctx context.Context, info *tap.Info) (context.Context, error) {
+		newCtx, err := handle(ctx, info)
+		if err != nil {
+			if _, ok := metadata.FromIncomingContext(ctx); !ok {
+				ctx = metadata.NewIncomingContext(ctx, info.Header)
+			}
+			RecordRejectedRPC(ctx, info.FullMethodName, err, reason)
+		}
+		return newCtx, err
+	}
+}
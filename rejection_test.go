@@ -0,0 +1,126 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+func TestRejectionMiddleware(t *testing.T) {
+	s, spans, reader := testService(t, "billing")
+
+	limiter := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Over-Limit") != "" {
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	served := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RejectionMiddleware(RejectedRateLimit, limiter)(served)
+
+	tests := []struct {
+		name     string
+		over     bool
+		rejected bool
+	}{
+		{name: "allowed", over: false, rejected: false},
+		{name: "rejected", over: true, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans.Reset()
+			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.over {
+				r.Header.Set("X-Over-Limit", "1")
+			}
+			r = r.WithContext(ContextWithService(r.Context(), s))
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			var rejectedSpans int
+			for _, span := range spans.Ended() {
+				for _, kv := range span.Attributes() {
+					if kv.Key == "otelx.rejected" && kv.Value.AsString() == RejectedRateLimit {
+						rejectedSpans++
+					}
+				}
+			}
+			if got := rejectedSpans == 1; got != tt.rejected {
+				t.Errorf("%d rejection spans, want rejected = %v", rejectedSpans, tt.rejected)
+			}
+		})
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if got := rejectedCount(rm, http.StatusTooManyRequests); got != 1 {
+		t.Errorf("%d rejected requests counted, want 1", got)
+	}
+}
+
+func TestRejectionTapHandle(t *testing.T) {
+	s, spans, reader := testService(t, "billing")
+
+	errLimited := status.Error(codes.ResourceExhausted, "rate limited")
+	limiter := func(ctx context.Context, info *tap.Info) (context.Context, error) {
+		if info.FullMethodName == "/shop.v1.Cart/Checkout" {
+			return nil, errLimited
+		}
+		return ctx, nil
+	}
+	handle := RejectionTapHandle(RejectedRateLimit, limiter)
+
+	ctx := ContextWithService(context.Background(), s)
+	for _, method := range []string{"/shop.v1.Cart/Get", "/shop.v1.Cart/Checkout"} {
+		info := &tap.Info{FullMethodName: method, Header: metadata.MD{}}
+		if _, err := handle(ctx, info); err != nil && !errors.Is(err, errLimited) {
+			t.Fatalf("handle(%s) = %v", method, err)
+		}
+	}
+
+	if got := len(spans.Ended()); got != 1 {
+		t.Errorf("%d rejection spans, want 1", got)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if got := rejectedCount(rm, int(codes.ResourceExhausted)); got != 1 {
+		t.Errorf("%d rejected calls counted, want 1", got)
+	}
+}
+
+// rejectedCount returns the number of rate limited requests counted with
+// status code in rm.
+func rejectedCount(rm metricdata.ResourceMetrics, code int) int64 {
+	var n int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != instrumentName("http_requests_total") {
+				continue
+			}
+			for _, p := range sum.DataPoints {
+				reason, _ := p.Attributes.Value("rejected")
+				got, _ := p.Attributes.Value("status_code")
+				if reason.AsString() == RejectedRateLimit && got.AsInt64() == int64(code) {
+					n += p.Value
+				}
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,281 @@
+package otelx
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// telemetryPaused is set when OTEL_ENABLE is switched off at runtime. Spans are
+// no longer sampled and request metrics are no longer recorded until it is
+// switched back on.
+var telemetryPaused atomic.Bool
+
+// dynamicConfig holds the settings that can change without a restart. Nil
+// fields are left unchanged when applied.
+type dynamicConfig struct {
+	enabled        *bool
+	samplerName    string
+	samplerRatio   *float64
+	ignorePaths    []string
+	slowThresholds routeThresholds
+}
+
+// samplerBox lets a Sampler interface be stored in an atomic.Pointer.
+type samplerBox struct {
+	sdktrace.Sampler
+}
+
+// reloadableSampler delegates to a sampler that can be swapped at runtime and
+// drops every span while telemetry is paused.
+type reloadableSampler struct {
+	current atomic.Pointer[samplerBox]
+}
+
+var _ sdktrace.Sampler = (*reloadableSampler)(nil)
+
+// newReloadableSampler returns a reloadableSampler starting with s.
+func newReloadableSampler(s sdktrace.Sampler) *reloadableSampler {
+	r := &reloadableSampler{}
+	r.set(s)
+	return r
+}
+
+// set replaces the delegate sampler.
+func (r *reloadableSampler) set(s sdktrace.Sampler) {
+	r.current.Store(&samplerBox{Sampler: s})
+}
+
+// ShouldSample delegates to the current sampler unless telemetry is paused
+// or the context requests guaranteed sampling (see WithSamplingPriority).
+func (r *reloadableSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if telemetryPaused.Load() {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.Drop,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	if prioritySampled(p.ParentContext) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return r.current.Load().ShouldSample(p)
+}
+
+// Description describes the current sampler.
+func (r *reloadableSampler) Description() string {
+	return r.current.Load().Description()
+}
+
+// activeSampler is the sampler installed by NewTraceProvider.
+var activeSampler = newReloadableSampler(sdktrace.AlwaysSample())
+
+// ratioSampler returns the sampler used for ratio: AlwaysSample for 1 and
+// above, parent-based trace ID ratio sampling otherwise.
+func ratioSampler(ratio float64) sdktrace.Sampler {
+	if ratio >= 1 {
+		return sdktrace.AlwaysSample()
+	}
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+}
+
+// samplers builds the samplers named by OTEL_TRACES_SAMPLER from the sampling
+// ratio, which only the ratio-based ones use. The empty name selects
+// ratioSampler, otelx's default.
+var samplers = map[string]func(ratio float64) sdktrace.Sampler{
+	"":             ratioSampler,
+	"always_on":    func(float64) sdktrace.Sampler { return sdktrace.AlwaysSample() },
+	"always_off":   func(float64) sdktrace.Sampler { return sdktrace.NeverSample() },
+	"traceidratio": sdktrace.TraceIDRatioBased,
+	"parentbased_always_on": func(float64) sdktrace.Sampler {
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	},
+	"parentbased_always_off": func(float64) sdktrace.Sampler {
+		return sdktrace.ParentBased(sdktrace.NeverSample())
+	},
+	"parentbased_traceidratio": func(ratio float64) sdktrace.Sampler {
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+	},
+}
+
+// samplerName normalizes a sampler name and checks that samplers knows it.
+func samplerName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := samplers[name]; !ok {
+		return "", fmt.Errorf("unsupported sampler %q", name)
+	}
+	return name, nil
+}
+
+// baseDropRules are the drop rules configured through Options. Ignored paths
+// loaded at runtime are added on top of them.
+var baseDropRules []spanDropRule
+
+// applyDynamic applies d to the running pipeline.
+func applyDynamic(d dynamicConfig) {
+	if d.enabled != nil {
+		telemetryPaused.Store(!*d.enabled)
+	}
+	if settings().sampler != nil {
+		activeSampler.set(settings().sampler)
+	} else if d.samplerRatio != nil {
+		activeSampler.set(samplers[d.samplerName](*d.samplerRatio))
+	}
+	if d.ignorePaths != nil {
+		rules := append(baseDropRules[:len(baseDropRules):len(baseDropRules)],
+			compileDropRules("", d.ignorePaths)...)
+		setDropRules(rules)
+	}
+	if d.slowThresholds != nil {
+		merged := make(routeThresholds, len(settings().slowThresholds)+len(d.slowThresholds))
+		for route, threshold := range settings().slowThresholds {
+			merged[route] = threshold
+		}
+		for route, threshold := range d.slowThresholds {
+			merged[route] = threshold
+		}
+		activeSlowThresholds.Store(&merged)
+	}
+}
+
+// dynamicFromEnv reads the reloadable settings from the environment:
+//
+//	OTEL_ENABLE               pauses telemetry when not "true", or when
+//	                          "false" in the local profile running without
+//	                          a collector
+//	OTEL_TRACES_SAMPLER       sampler name
+//	OTEL_TRACES_SAMPLER_ARG   sampling ratio between 0 and 1
+//	OTELX_IGNORE_PATHS        comma-separated span name/path patterns to drop
+func dynamicFromEnv() (dynamicConfig, error) {
+	var d dynamicConfig
+
+	// The local profile runs without OTEL_ENABLE when no collector is
+	// configured, so only an explicit "false" pauses it.
+	enabled := os.Getenv("OTEL_ENABLE") == "true" || localStandalone(*settings())
+	d.enabled = &enabled
+
+	name, err := samplerName(os.Getenv("OTEL_TRACES_SAMPLER"))
+	if err != nil {
+		return d, fmt.Errorf("OTEL_TRACES_SAMPLER: %w", err)
+	}
+	d.samplerName = name
+
+	ratio := activeProfile().samplerRatio
+	if v := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r < 0 || r > 1 {
+			return d, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG: invalid sampling ratio %q", v)
+		}
+		ratio = r
+	}
+	d.samplerRatio = &ratio
+
+	// Thresholds only come from OTELX_CONFIG_FILE; start from none so a
+	// reload drops thresholds removed from the file.
+	d.slowThresholds = routeThresholds{}
+
+	d.ignorePaths = []string{}
+	for _, p := range strings.Split(os.Getenv("OTELX_IGNORE_PATHS"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			d.ignorePaths = append(d.ignorePaths, p)
+		}
+	}
+
+	return d, nil
+}
+
+// Reload re-reads the reloadable settings from the environment and applies
+// them without restarting the service:
+//
+//	OTEL_ENABLE               "true" resumes telemetry, anything else pauses it
+//	OTEL_TRACES_SAMPLER       always_on, always_off, traceidratio,
+//	                          parentbased_always_on, parentbased_always_off or
+//	                          parentbased_traceidratio; unset, AlwaysSample
+//	                          for a ratio of 1 and parentbased_traceidratio
+//	                          otherwise
+//	OTEL_TRACES_SAMPLER_ARG   sampling ratio between 0 and 1 of the ratio-based
+//	                          samplers (default 1, or 0.1 with the prod
+//	                          profile)
+//	OTELX_IGNORE_PATHS        comma-separated patterns of span names and paths
+//	                          to drop, in addition to WithDroppedSpanNames
+//	OTELX_CONFIG_FILE         JSON file whose enabled, sampler, sampler_ratio,
+//	                          ignore_paths and slow_thresholds fields override
+//	                          the variables above
+//
+// The environment of a running process cannot be changed from outside, so
+// reloading is only meaningful with OTELX_CONFIG_FILE, or after the process
+// changed its own environment (see Config.Setenv). NewTraceProvider watches
+// OTELX_CONFIG_FILE and reloads automatically when it changes, so telemetry
+// can be tuned fleet-wide through config management.
+//
+// Pausing stops sampling spans and recording request metrics; the providers
+// and the collector connection are kept so telemetry can be resumed. A service
+// started with telemetry disabled has no providers and cannot be enabled by
+// Reload.
+//
+// On an invalid value, Reload returns an error and leaves the running
+// configuration unchanged.
+func Reload() error {
+	d, err := dynamicFromEnv()
+	if err != nil {
+		return err
+	}
+	if path := os.Getenv("OTELX_CONFIG_FILE"); path != "" {
+		if d, err = dynamicFromFile(path, d); err != nil {
+			return err
+		}
+	}
+	applyDynamic(d)
+	return nil
+}
+
+// WithReloadOnSIGHUP makes NewTraceProvider call Reload whenever the process
+// receives SIGHUP, until the provider's cleanup function is called. As the
+// signal cannot change the process environment, it is meant for applying an
+// edited OTELX_CONFIG_FILE right away instead of at the next poll.
+func WithReloadOnSIGHUP() Option {
+	return func(c *config) {
+		c.reloadOnSIGHUP = true
+	}
+}
+
+// watchSIGHUP calls Reload on every SIGHUP until the returned function is
+// called.
+func watchSIGHUP() func() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sig:
+				if err := Reload(); err != nil {
+					logf("error reloading telemetry configuration: %v\n", err)
+				} else {
+					logf("reloaded telemetry configuration\n")
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sig)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package otelx
+
+import (
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestReloadSampler(t *testing.T) {
+	tests := []struct {
+		name    string
+		sampler string
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{name: "default full ratio", want: sdktrace.AlwaysSample().Description()},
+		{name: "default ratio", arg: "0.25", want: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.25)).Description()},
+		{name: "always_on", sampler: "always_on", arg: "0.25", want: sdktrace.AlwaysSample().Description()},
+		{name: "always_off", sampler: "always_off", want: sdktrace.NeverSample().Description()},
+		{name: "traceidratio", sampler: "traceidratio", arg: "0.5", want: sdktrace.TraceIDRatioBased(0.5).Description()},
+		{name: "parentbased_always_on", sampler: "parentbased_always_on", want: sdktrace.ParentBased(sdktrace.AlwaysSample()).Description()},
+		{name: "parentbased_always_off", sampler: "parentbased_always_off", want: sdktrace.ParentBased(sdktrace.NeverSample()).Description()},
+		{name: "parentbased_traceidratio", sampler: " ParentBased_TraceIDRatio ", arg: "0.1", want: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.1)).Description()},
+		{name: "unsupported sampler", sampler: "jaeger_remote", wantErr: true},
+		{name: "invalid ratio", sampler: "traceidratio", arg: "2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER", tt.sampler)
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", tt.arg)
+			t.Setenv("OTELX_CONFIG_FILE", "")
+			t.Setenv("ENV", "")
+
+			saved := activeSampler.current.Load()
+			t.Cleanup(func() {
+				activeSampler.current.Store(saved)
+				telemetryPaused.Store(false)
+			})
+			activeSampler.set(sdktrace.AlwaysSample())
+
+			err := Reload()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Reload() succeeded, want an error")
+				}
+				if got := activeSampler.Description(); got != sdktrace.AlwaysSample().Description() {
+					t.Errorf("sampler changed to %q on error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+			if got := activeSampler.Description(); got != tt.want {
+				t.Errorf("sampler = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReloadEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		enable     string
+		collector  string
+		wantPaused bool
+	}{
+		{name: "enabled", enable: "true"},
+		{name: "unset", wantPaused: true},
+		{name: "disabled", enable: "false", wantPaused: true},
+		{name: "local without collector", env: "local"},
+		{name: "local disabled", env: "local", enable: "false", wantPaused: true},
+		{name: "local with collector", env: "local", collector: "collector:4317", wantPaused: true},
+		{name: "local with collector enabled", env: "local", enable: "true", collector: "collector:4317"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENV", tt.env)
+			t.Setenv("OTEL_ENABLE", tt.enable)
+			t.Setenv("OTEL_COLLECTOR_ENDPOINT", tt.collector)
+			t.Setenv("OTELX_CONFIG_FILE", "")
+			t.Cleanup(func() { telemetryPaused.Store(false) })
+
+			if err := Reload(); err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+			if got := telemetryPaused.Load(); got != tt.wantPaused {
+				t.Errorf("paused = %v, want %v", got, tt.wantPaused)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package otelx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultRequestIDHeader is the header RequestIDMiddleware reads and writes
+// unless WithRequestIDHeader is used.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// requestIDBaggageKey is the baggage member carrying the request ID across
+// services.
+const requestIDBaggageKey = "request.id"
+
+// maxRequestIDLength is the length above which incoming request IDs are
+// replaced.
+const maxRequestIDLength = 128
+
+// requestIDKey is the context key holding the request ID.
+type requestIDKey struct{}
+
+// WithRequestIDHeader sets the header used by RequestIDMiddleware to read and
+// echo the request ID. The default is X-Request-ID.
+func WithRequestIDHeader(header string) Option {
+	return func(c *config) {
+		c.requestIDHeader = http.CanonicalHeaderKey(header)
+	}
+}
+
+// RequestIDMiddleware correlates legacy request IDs with traces.
+//
+// The request ID is taken from the request ID header, falling back to the
+// request.id baggage member and finally to a newly generated ID. Incoming IDs
+// longer than 128 bytes or with characters other than letters, digits, '.',
+// '_' and '-' are replaced with a generated ID, so clients cannot inject
+// arbitrary data into responses, logs and downstream baggage. It is then:
+//
+//   - stored in the request context, available through RequestID()
+//   - added to the context baggage, so HTTPClient and the gRPC client
+//     interceptors forward it to downstream services
+//   - echoed in the response header
+//   - recorded on the active span as the request.id attribute
+//
+// Place it inside TracingMiddleware so the span exists. Serve wires it
+// automatically.
+//
+// Example:
+//
+//	handler := otelx.TracingMiddleware(otelx.RequestIDMiddleware(mux))
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := settings().requestIDHeader
+
+		id := r.Header.Get(header)
+		if id == "" {
+			id = baggage.FromContext(r.Context()).Member(requestIDBaggageKey).Value()
+		}
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		ctx := ContextWithRequestID(r.Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", id))
+		w.Header().Set(header, id)
+
+		r = r.WithContext(ctx)
+		next.ServeHTTP(w, r)
+		recordRoute(r)
+	})
+}
+
+// ContextWithRequestID returns a copy of ctx carrying id as the request ID,
+// both as a context value and as the request.id baggage member.
+//
+// It is used by RequestIDMiddleware and is useful for background jobs and
+// consumers that receive a request ID out of band.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+
+	member, err := baggage.NewMemberRaw(requestIDBaggageKey, id)
+	if err != nil {
+		logf("invalid request ID %q for baggage: %v\n", id, err)
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		logf("error adding request ID to baggage: %v\n", err)
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string if
+// there is none.
+//
+// IDs set by RequestIDMiddleware or ContextWithRequestID are returned first;
+// otherwise the request.id baggage member propagated by an upstream service is
+// used.
+//
+// Example:
+//
+//	log.Printf("request %s: user not found", otelx.RequestID(ctx))
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return baggage.FromContext(ctx).Member(requestIDBaggageKey).Value()
+}
+
+// validRequestID reports whether id is a non-empty request ID of at most
+// maxRequestIDLength bytes made of [A-Za-z0-9._-].
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit request ID encoded as hex.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		logf("error generating request ID: %v\n", err)
+	}
+	return hex.EncodeToString(b[:])
+}
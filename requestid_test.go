@@ -0,0 +1,33 @@
+package otelx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "hex", id: "4bf92f3577b34da6a3ce929d0e0e4736", want: true},
+		{name: "uuid", id: "0f8fad5b-d9cb-469f-a165-70867728950e", want: true},
+		{name: "dots and underscores", id: "req_1.2", want: true},
+		{name: "max length", id: strings.Repeat("a", maxRequestIDLength), want: true},
+		{name: "empty", id: "", want: false},
+		{name: "too long", id: strings.Repeat("a", maxRequestIDLength+1), want: false},
+		{name: "space", id: "abc def", want: false},
+		{name: "markup", id: "<script>", want: false},
+		{name: "baggage separator", id: "abc,admin=true", want: false},
+		{name: "non ascii", id: "réq", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validRequestID(tt.id); got != tt.want {
+				t.Errorf("validRequestID(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package otelx
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// retryAttemptKey is the context key holding the retry attempt of an outgoing
+// request.
+type retryAttemptKey struct{}
+
+// ContextWithRetryAttempt returns a copy of ctx marking requests sent with it
+// as retry attempt n, where 0 is the first try. Call it from retry loops so the
+// client instrumentation can tell retries apart:
+//
+//   - the CLIENT span gets http.request.resend_count = n when n > 0
+//   - the outgoing request metrics get retried="true" or "false"
+//
+// Example:
+//
+//	for attempt := 0; attempt < 3; attempt++ {
+//	    req, _ := http.NewRequestWithContext(otelx.ContextWithRetryAttempt(ctx, attempt), http.MethodGet, url, nil)
+//	    resp, err = otelx.DoRequest(ctx, req)
+//	    if err == nil && resp.StatusCode < 500 {
+//	        break
+//	    }
+//	}
+func ContextWithRetryAttempt(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, retryAttemptKey{}, n)
+}
+
+// RetryAttempt returns the retry attempt carried by ctx, or 0.
+func RetryAttempt(ctx context.Context) int {
+	n, _ := ctx.Value(retryAttemptKey{}).(int)
+	return n
+}
+
+// retryMetricAttributes adds the bounded retried dimension to the outgoing
+// request metrics recorded by otelhttp.
+func retryMetricAttributes(r *http.Request) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("retried", strconv.FormatBool(RetryAttempt(r.Context()) > 0)),
+	}
+}
+
+// resendTransport records the retry attempt on the CLIENT span started by the
+// otelhttp transport wrapping it.
+type resendTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip sets http.request.resend_count for retries and forwards r.
+func (t resendTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if n := RetryAttempt(r.Context()); n > 0 {
+		trace.SpanFromContext(r.Context()).SetAttributes(
+			attribute.Int("http.request.resend_count", n),
+		)
+	}
+	return t.next.RoundTrip(r)
+}
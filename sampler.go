@@ -0,0 +1,109 @@
+package otelx
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SamplerFunc adapts a function to sdktrace.Sampler so bespoke sampling logic
+// can be passed to WithSamplerFunc.
+type SamplerFunc func(sdktrace.SamplingParameters) sdktrace.SamplingResult
+
+// ShouldSample calls f.
+func (f SamplerFunc) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return f(p)
+}
+
+// Description identifies the sampler in DebugHandler.
+func (f SamplerFunc) Description() string {
+	return "SamplerFunc"
+}
+
+// WithSampler replaces the ratio-based sampler (OTEL_TRACES_SAMPLER_ARG and
+// the defaults profile) with s, keeping the rest of otelx's initialization.
+//
+// The enabled flag is still honored: while telemetry is paused through Reload
+// every span is dropped regardless of s. Reload does not change s.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "checkout",
+//	    otelx.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.05))),
+//	)
+func WithSampler(s sdktrace.Sampler) Option {
+	return func(c *config) {
+		c.sampler = s
+	}
+}
+
+// WithSamplerFunc is a shorthand for WithSampler(SamplerFunc(fn)), for
+// bespoke logic such as sampling by customer tier carried in baggage or by
+// endpoint cost.
+//
+// Example:
+//
+//	otelx.WithSamplerFunc(func(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+//	    tier := baggage.FromContext(p.ParentContext).Member("customer.tier").Value()
+//	    if tier == "enterprise" {
+//	        return sdktrace.AlwaysSample().ShouldSample(p)
+//	    }
+//	    return sdktrace.TraceIDRatioBased(0.01).ShouldSample(p)
+//	})
+func WithSamplerFunc(fn func(sdktrace.SamplingParameters) sdktrace.SamplingResult) Option {
+	return WithSampler(SamplerFunc(fn))
+}
+
+// samplingPriorityKey is the baggage member requesting guaranteed sampling.
+const samplingPriorityKey = "sampling.priority"
+
+// WithSamplingPriority makes the sampler honor the sampling.priority baggage
+// member: spans whose context carries a priority of 1 or more are always
+// sampled, whatever the configured sampler decides. Edge services set it with
+// ContextWithSamplingPriority for specific user sessions or premium tenants,
+// and baggage carries it to every downstream otelx service, giving end-to-end
+// traces.
+//
+// It is opt-in because baggage can be set by callers: enable it only where
+// incoming baggage is trusted or stripped at the edge.
+func WithSamplingPriority() Option {
+	return func(c *config) {
+		c.samplingPriority = true
+	}
+}
+
+// ContextWithSamplingPriority returns a copy of ctx whose baggage carries
+// sampling.priority=priority. Services using WithSamplingPriority sample
+// every span of the request when priority is 1 or more.
+//
+// Example:
+//
+//	if tenant.Premium {
+//	    ctx = otelx.ContextWithSamplingPriority(ctx, 1)
+//	}
+func ContextWithSamplingPriority(ctx context.Context, priority int) context.Context {
+	member, err := baggage.NewMemberRaw(samplingPriorityKey, strconv.Itoa(priority))
+	if err != nil {
+		logf("invalid sampling priority %d: %v\n", priority, err)
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		logf("error adding sampling priority to baggage: %v\n", err)
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// prioritySampled reports whether ctx requests guaranteed sampling through
+// the sampling.priority baggage member.
+func prioritySampled(ctx context.Context) bool {
+	if !settings().samplingPriority {
+		return false
+	}
+	v := baggage.FromContext(ctx).Member(samplingPriorityKey).Value()
+	priority, err := strconv.Atoi(v)
+	return err == nil && priority > 0
+}
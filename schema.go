@@ -0,0 +1,78 @@
+package otelx
+
+import (
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithSchemaURL pins the semantic conventions schema URL (such as
+// "https://opentelemetry.io/schemas/1.26.0") of the Resource and of the
+// tracers and meters otelx creates. Without it the Resource carries the
+// schema of the SDK's resource detectors, which changes with SDK upgrades and
+// conflicts with services pinned elsewhere during a semconv migration.
+//
+// The schema version also selects the Resource's attribute conventions:
+// from 1.27.0 the environment is recorded as deployment.environment.name
+// instead of deployment.environment.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "auth-service",
+//	    otelx.WithSchemaURL("https://opentelemetry.io/schemas/1.27.0"),
+//	)
+func WithSchemaURL(schemaURL string) Option {
+	return func(c *config) {
+		c.schemaURL = schemaURL
+	}
+}
+
+// pinSchema returns res with the schema URL set by WithSchemaURL, or res
+// unchanged when none is set.
+func pinSchema(res *resource.Resource) *resource.Resource {
+	if settings().schemaURL == "" {
+		return res
+	}
+	return resource.NewWithAttributes(settings().schemaURL, res.Attributes()...)
+}
+
+// deploymentEnvironmentKey returns the resource attribute key of the
+// deployment environment under the pinned schema.
+func deploymentEnvironmentKey() attribute.Key {
+	if schemaAtLeast(settings().schemaURL, 1, 27) {
+		return "deployment.environment.name"
+	}
+	return "deployment.environment"
+}
+
+// schemaAtLeast reports whether schemaURL names schema version major.minor
+// or later. Unparsable URLs are treated as older versions.
+func schemaAtLeast(schemaURL string, major, minor int) bool {
+	version := schemaURL[strings.LastIndexByte(schemaURL, '/')+1:]
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return false
+	}
+	gotMajor, err1 := strconv.Atoi(parts[0])
+	gotMinor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+// tracerOptions returns the instrumentation scope options of the tracers
+// created for services.
+func tracerOptions() []trace.TracerOption {
+	return []trace.TracerOption{trace.WithSchemaURL(settings().schemaURL)}
+}
+
+// meterOptions returns the instrumentation scope options of the meters
+// created for services.
+func meterOptions() []api.MeterOption {
+	return []api.MeterOption{api.WithSchemaURL(settings().schemaURL)}
+}
@@ -0,0 +1,274 @@
+package otelx
+
+import (
+	"context"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// redacted replaces sensitive values masked by the scrubbing processor.
+const redacted = "[REDACTED]"
+
+// ScrubRule describes sensitive span attribute values that must be masked or
+// removed before export.
+//
+// Key and Value are regular expressions:
+//   - Key selects attributes by name; empty matches every attribute.
+//   - Value selects the sensitive part of a string value; empty masks the
+//     whole value.
+//
+// When Remove is true the matching attribute is dropped entirely instead of
+// being masked.
+//
+// When Luhn is true, only Value matches whose digits pass the Luhn checksum
+// are masked, so a card number pattern does not also redact order numbers,
+// timestamps and other long identifiers.
+type ScrubRule struct {
+	Key    string
+	Value  string
+	Remove bool
+	Luhn   bool
+}
+
+// DefaultScrubRules returns the rules applied when no WithScrubRules option
+// is given. They mask credentials by attribute name as well as email
+// addresses, bearer tokens and payment card numbers (13 to 19 digits passing
+// the Luhn checksum) found in any string value.
+//
+// The returned slice can be extended and passed back to WithScrubRules:
+//
+//	otelx.WithScrubRules(append(otelx.DefaultScrubRules(),
+//	    otelx.ScrubRule{Key: `^customer\.ssn$`, Remove: true},
+//	)...)
+func DefaultScrubRules() []ScrubRule {
+	return []ScrubRule{
+		{Key: `(?i)(password|passwd|secret|token|authorization|api[-_.]?key|cookie)`},
+		{Value: `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`},
+		{Value: `(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`},
+		{Value: `\b(?:\d[ -]?){12,18}\d\b`, Luhn: true},
+	}
+}
+
+// scrubRule is the compiled form of a ScrubRule.
+type scrubRule struct {
+	key    *regexp.Regexp
+	value  *regexp.Regexp
+	remove bool
+	luhn   bool
+}
+
+// compileScrubRules compiles rules, logging and skipping invalid expressions
+// so that a bad rule never prevents startup.
+func compileScrubRules(rules []ScrubRule) []scrubRule {
+	compiled := make([]scrubRule, 0, len(rules))
+	for _, r := range rules {
+		var sr scrubRule
+		var err error
+		if r.Key != "" {
+			if sr.key, err = regexp.Compile(r.Key); err != nil {
+				logf("invalid scrub key pattern %q: %v\n", r.Key, err)
+				continue
+			}
+		}
+		if r.Value != "" {
+			if sr.value, err = regexp.Compile(r.Value); err != nil {
+				logf("invalid scrub value pattern %q: %v\n", r.Value, err)
+				continue
+			}
+		}
+		sr.remove, sr.luhn = r.Remove, r.Luhn
+		compiled = append(compiled, sr)
+	}
+	return compiled
+}
+
+// scrub applies rules to attrs. It returns attrs unchanged (and false) when
+// no rule modified anything, avoiding allocations for clean spans.
+func scrub(rules []scrubRule, attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		v, keep, changed := scrubValue(rules, kv)
+		if changed && out == nil {
+			out = make([]attribute.KeyValue, i, len(attrs))
+			copy(out, attrs[:i])
+		}
+		if out != nil && keep {
+			out = append(out, attribute.KeyValue{Key: kv.Key, Value: v})
+		}
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+// scrubValue applies rules to a single attribute and reports the resulting
+// value, whether the attribute should be kept and whether it changed.
+func scrubValue(rules []scrubRule, kv attribute.KeyValue) (attribute.Value, bool, bool) {
+	value := kv.Value
+	changed := false
+	for _, r := range rules {
+		if r.key != nil && !r.key.MatchString(string(kv.Key)) {
+			continue
+		}
+		if r.value == nil {
+			if r.remove {
+				return value, false, true
+			}
+			value = attribute.StringValue(redacted)
+			changed = true
+			continue
+		}
+		if value.Type() != attribute.STRING {
+			continue
+		}
+		s := value.AsString()
+		if !r.value.MatchString(s) {
+			continue
+		}
+		var masked string
+		if r.luhn {
+			masked = r.value.ReplaceAllStringFunc(s, maskLuhn)
+			if masked == s {
+				continue
+			}
+		} else {
+			masked = r.value.ReplaceAllString(s, redacted)
+		}
+		if r.remove {
+			return value, false, true
+		}
+		value = attribute.StringValue(masked)
+		changed = true
+	}
+	return value, true, changed
+}
+
+// maskLuhn returns redacted if the digits of s pass the Luhn checksum, and s
+// otherwise.
+func maskLuhn(s string) string {
+	if luhnValid(s) {
+		return redacted
+	}
+	return s
+}
+
+// luhnValid reports whether the digits of s, ignoring any other characters,
+// pass the Luhn checksum used by payment card numbers.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+// scrubbedSpan overrides the attributes and events of a finished span with
+// their scrubbed versions.
+type scrubbedSpan struct {
+	sdktrace.ReadOnlySpan
+	attrs  []attribute.KeyValue
+	events []sdktrace.Event
+}
+
+// Attributes returns the scrubbed span attributes.
+func (s scrubbedSpan) Attributes() []attribute.KeyValue { return s.attrs }
+
+// Events returns the span events with scrubbed attributes.
+func (s scrubbedSpan) Events() []sdktrace.Event { return s.events }
+
+// scrubSpanProcessor is an sdktrace.SpanProcessor that masks or removes
+// sensitive attribute values from finished spans before handing them to next.
+//
+// Running it in-process means compliance does not depend on every developer
+// remembering to sanitize the values they attach to spans.
+type scrubSpanProcessor struct {
+	next  sdktrace.SpanProcessor
+	rules []scrubRule
+}
+
+var _ sdktrace.SpanProcessor = (*scrubSpanProcessor)(nil)
+
+// newScrubSpanProcessor wraps next with rules. When no rules are configured,
+// next is returned unchanged.
+func newScrubSpanProcessor(next sdktrace.SpanProcessor, rules []scrubRule) sdktrace.SpanProcessor {
+	if len(rules) == 0 {
+		return next
+	}
+	return &scrubSpanProcessor{next: next, rules: rules}
+}
+
+// OnStart forwards span to the wrapped processor.
+func (p *scrubSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd scrubs the attributes of s and its events and forwards the result.
+func (p *scrubSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	attrs, changed := scrub(p.rules, s.Attributes())
+
+	events := s.Events()
+	var scrubbedEvents []sdktrace.Event
+	for i, ev := range events {
+		evAttrs, evChanged := scrub(p.rules, ev.Attributes)
+		if !evChanged {
+			continue
+		}
+		if scrubbedEvents == nil {
+			scrubbedEvents = make([]sdktrace.Event, len(events))
+			copy(scrubbedEvents, events)
+		}
+		scrubbedEvents[i].Attributes = evAttrs
+	}
+
+	if !changed && scrubbedEvents == nil {
+		p.next.OnEnd(s)
+		return
+	}
+	if scrubbedEvents == nil {
+		scrubbedEvents = events
+	}
+
+	p.next.OnEnd(scrubbedSpan{ReadOnlySpan: s, attrs: attrs, events: scrubbedEvents})
+}
+
+// Shutdown shuts down the wrapped processor.
+func (p *scrubSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush flushes the wrapped processor.
+func (p *scrubSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// WithScrubRules replaces the rules used to mask sensitive span attributes
+// before export. By default DefaultScrubRules() is applied; calling
+// WithScrubRules() with no rules disables scrubbing.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "payments",
+//	    otelx.WithScrubRules(
+//	        otelx.ScrubRule{Key: `^card\.`, Remove: true},
+//	        otelx.ScrubRule{Value: `\d{3}-\d{2}-\d{4}`},
+//	    ),
+//	)
+func WithScrubRules(rules ...ScrubRule) Option {
+	return func(c *config) {
+		c.scrubRules = compileScrubRules(rules)
+	}
+}
@@ -0,0 +1,95 @@
+package otelx
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestDefaultScrubRules(t *testing.T) {
+	rules := compileScrubRules(DefaultScrubRules())
+
+	tests := []struct {
+		name    string
+		kv      attribute.KeyValue
+		want    string
+		changed bool
+	}{
+		{name: "password key", kv: attribute.String("db.password", "hunter2"), want: redacted, changed: true},
+		{name: "api key", kv: attribute.String("X-Api-Key", "abc"), want: redacted, changed: true},
+		{name: "non-string credential", kv: attribute.Int("auth.token", 42), want: redacted, changed: true},
+		{name: "email", kv: attribute.String("message", "sent to jane.doe@example.com today"), want: "sent to " + redacted + " today", changed: true},
+		{name: "bearer token", kv: attribute.String("header", "Bearer eyJhbGciOi.J9x-y_z"), want: redacted, changed: true},
+		{name: "luhn-valid card", kv: attribute.String("note", "card 4111 1111 1111 1111 declined"), want: "card " + redacted + " declined", changed: true},
+		{name: "luhn-valid card with dashes", kv: attribute.String("note", "5500-0000-0000-0004"), want: redacted, changed: true},
+		{name: "luhn-invalid card", kv: attribute.String("note", "card 4111 1111 1111 1112 declined"), want: "card 4111 1111 1111 1112 declined"},
+		{name: "order number", kv: attribute.String("order.id", "1700000000123"), want: "1700000000123"},
+		{name: "valid and invalid numbers", kv: attribute.String("note", "4111111111111111 and 4111111111111112"), want: redacted + " and 4111111111111112", changed: true},
+		{name: "short number", kv: attribute.String("http.status", "404"), want: "404"},
+		{name: "clean", kv: attribute.String("http.route", "/orders/{id}"), want: "/orders/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, keep, changed := scrubValue(rules, tt.kv)
+			if !keep {
+				t.Fatal("attribute removed, want it kept")
+			}
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if got := v.Emit(); got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubRemove(t *testing.T) {
+	rules := compileScrubRules([]ScrubRule{
+		{Key: `^card\.`, Remove: true},
+		{Value: `\b(?:\d[ -]?){12,18}\d\b`, Luhn: true, Remove: true},
+	})
+
+	attrs := []attribute.KeyValue{
+		attribute.String("card.last4", "1111"),
+		attribute.String("note", "4111111111111111"),
+		attribute.String("ref", "4111111111111112"),
+		attribute.String("http.route", "/pay"),
+	}
+	got, changed := scrub(rules, attrs)
+	if !changed {
+		t.Fatal("scrub() reported no change")
+	}
+	want := []attribute.KeyValue{attrs[2], attrs[3]}
+	if len(got) != len(want) {
+		t.Fatalf("scrub() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("scrub()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{number: "4111111111111111", want: true},
+		{number: "4111-1111-1111-1111", want: true},
+		{number: "378282246310005", want: true},
+		{number: "4111111111111112", want: false},
+		{number: "1234567812345678", want: false},
+		{number: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := luhnValid(tt.number); got != tt.want {
+				t.Errorf("luhnValid(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package otelx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// instrumentationName identifies otelx's own meter and tracer.
+const instrumentationName = "github.com/edr3x/otelx"
+
+// defaultSpanQueueSize matches the SDK's default BatchSpanProcessor queue size.
+const defaultSpanQueueSize = 2048
+
+// selfMetrics holds the instruments describing the telemetry pipeline itself.
+//
+// They make it possible to alert on "silently losing telemetry": failed or slow
+// exports and spans dropped because the export queue was full.
+type selfMetrics struct {
+	exports        api.Int64Counter
+	exportDuration api.Float64Histogram
+	spansDropped   api.Int64Counter
+	payloadBytes   api.Int64Histogram
+}
+
+var (
+	selfOnce sync.Once
+	self     selfMetrics
+)
+
+// selfTelemetry returns otelx's pipeline instruments, creating them on first
+// use from the global MeterProvider. Instruments created before
+// NewMeterProvider is called are forwarded to it once it is registered.
+func selfTelemetry() *selfMetrics {
+	selfOnce.Do(func() {
+		meter := otel.Meter(instrumentationName)
+		fallback := noop.NewMeterProvider().Meter(instrumentationName)
+
+		var err error
+		if self.exports, err = meter.Int64Counter(
+			instrumentName("otelx_exports_total"),
+			instrumentDescription("otelx_exports_total", "Total number of telemetry export attempts by signal and result"),
+			api.WithUnit("{export}"),
+		); err != nil {
+			logf("failed to create exports counter: %v\n", err)
+			self.exports, _ = fallback.Int64Counter("otelx_exports_total")
+		}
+
+		if self.exportDuration, err = meter.Float64Histogram(
+			instrumentName("otelx_export_duration_seconds"),
+			instrumentDescription("otelx_export_duration_seconds", "Telemetry export duration in seconds"),
+			api.WithUnit("s"),
+		); err != nil {
+			logf("failed to create export duration histogram: %v\n", err)
+			self.exportDuration, _ = fallback.Float64Histogram("otelx_export_duration_seconds")
+		}
+
+		if self.spansDropped, err = meter.Int64Counter(
+			instrumentName("otelx_spans_dropped_total"),
+			instrumentDescription("otelx_spans_dropped_total", "Total number of spans dropped because the export queue was full or over its memory limit"),
+			api.WithUnit("{span}"),
+		); err != nil {
+			logf("failed to create dropped spans counter: %v\n", err)
+			self.spansDropped, _ = fallback.Int64Counter("otelx_spans_dropped_total")
+		}
+
+		if self.payloadBytes, err = meter.Int64Histogram(
+			instrumentName("otelx_export_payload_bytes"),
+			instrumentDescription("otelx_export_payload_bytes", "Serialized size of OTLP export requests sent to the collector"),
+			api.WithUnit("By"),
+			api.WithExplicitBucketBoundaries(
+				1<<10, 1<<12, 1<<14, 1<<16,
+				1<<18, 1<<20, 1<<22, 1<<24,
+			),
+		); err != nil {
+			logf("failed to create export payload histogram: %v\n", err)
+			self.payloadBytes, _ = fallback.Int64Histogram("otelx_export_payload_bytes")
+		}
+
+		registerPipelineGauges(meter)
+	})
+	return &self
+}
+
+// recordExport records the outcome of a single export attempt.
+func (m *selfMetrics) recordExport(ctx context.Context, signal, exporter string, seconds float64, err error) {
+	recordExportHealth(signal, err)
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+
+	m.exports.Add(ctx, 1, api.WithAttributes(
+		attribute.String("signal", signal),
+		attribute.String("exporter", exporter),
+		attribute.String("result", result),
+	))
+	m.exportDuration.Record(ctx, seconds, api.WithAttributes(
+		attribute.String("signal", signal),
+		attribute.String("exporter", exporter),
+	))
+}
+
+// exporterName returns a short, stable label describing exp.
+func exporterName(exp any) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", exp), "*")
+}
+
+// instrumentedSpanExporter records export results and latency for the
+// wrapped SpanExporter.
+type instrumentedSpanExporter struct {
+	sdktrace.SpanExporter
+	name string
+}
+
+// instrumentSpanExporter wraps exp so that every export is recorded in the
+// otelx self-telemetry instruments.
+func instrumentSpanExporter(exp sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &instrumentedSpanExporter{SpanExporter: exp, name: exporterName(exp)}
+}
+
+// ExportSpans exports spans and records the outcome.
+func (e *instrumentedSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	start := settings().clock.Now()
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	selfTelemetry().recordExport(context.Background(), "traces", e.name, since(start), err)
+	return err
+}
+
+// instrumentedMetricExporter records export results and latency for the
+// wrapped metric Exporter.
+type instrumentedMetricExporter struct {
+	sdkmetric.Exporter
+	name string
+
+	// lastExport is when the last export completed, in Unix nanoseconds.
+	lastExport atomic.Int64
+}
+
+// instrumentMetricExporter wraps exp so that every export is recorded in the
+// otelx self-telemetry instruments.
+func instrumentMetricExporter(exp sdkmetric.Exporter) sdkmetric.Exporter {
+	e := &instrumentedMetricExporter{Exporter: exp, name: exporterName(exp)}
+	e.lastExport.Store(time.Now().UnixNano())
+	trackMetricExporter(e)
+	return e
+}
+
+// Export exports rm and records the outcome.
+func (e *instrumentedMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	start := settings().clock.Now()
+	err := e.Exporter.Export(ctx, rm)
+	e.lastExport.Store(time.Now().UnixNano())
+	selfTelemetry().recordExport(context.Background(), "metrics", e.name, since(start), err)
+	return err
+}
+
+// Shutdown stops tracking e and shuts down the wrapped exporter.
+func (e *instrumentedMetricExporter) Shutdown(ctx context.Context) error {
+	untrackMetricExporter(e)
+	return e.Exporter.Shutdown(ctx)
+}
+
+// spanQueueSize returns the batch queue size, honoring OTEL_BSP_MAX_QUEUE_SIZE
+// like the SDK does.
+func spanQueueSize() int {
+	if v, err := strconv.Atoi(os.Getenv("OTEL_BSP_MAX_QUEUE_SIZE")); err == nil && v > 0 {
+		return v
+	}
+	return defaultSpanQueueSize
+}
+
+// spanQueue tracks the spans handed to a BatchSpanProcessor that have not yet
+// been passed to its exporter, by count and by approximate memory.
+type spanQueue struct {
+	exporter string
+	max      int64
+	inflight atomic.Int64
+
+	maxBytes int64 // 0 means unbounded
+	bytes    atomic.Int64
+}
+
+// boundedSpanProcessor sits in front of a BatchSpanProcessor and drops spans
+// once its queue is full or over its memory budget, counting every dropped
+// span.
+//
+// The SDK drops spans silently when the batch queue overflows. Enforcing the
+// bound here, slightly before the SDK would, makes drops observable without
+// changing delivery guarantees.
+type boundedSpanProcessor struct {
+	sdktrace.SpanProcessor
+	queue *spanQueue
+}
+
+// OnEnd forwards s unless the queue is full, in which case the span is
+// dropped and counted.
+func (p *boundedSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	// The batch processor ignores unsampled spans.
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+
+	if p.queue.inflight.Add(1) > p.queue.max {
+		p.queue.inflight.Add(-1)
+		dropSpan("queue_full")
+		return
+	}
+
+	if p.queue.maxBytes > 0 {
+		size := spanSize(s)
+		if p.queue.bytes.Add(size) > p.queue.maxBytes {
+			p.queue.bytes.Add(-size)
+			p.queue.inflight.Add(-1)
+			dropSpan("memory_limit")
+			return
+		}
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+// Shutdown stops tracking the queue and shuts down the batch processor.
+func (p *boundedSpanProcessor) Shutdown(ctx context.Context) error {
+	untrackSpanQueue(p.queue)
+	return p.SpanProcessor.Shutdown(ctx)
+}
+
+// dropSpan counts a span dropped before export for reason.
+func dropSpan(reason string) {
+	droppedSpans.Add(1)
+	selfTelemetry().spansDropped.Add(context.Background(), 1,
+		api.WithAttributes(attribute.String("reason", reason)))
+}
+
+// queueTrackingExporter releases queue slots once spans reach the exporter.
+type queueTrackingExporter struct {
+	sdktrace.SpanExporter
+	queue *spanQueue
+}
+
+// ExportSpans exports spans and marks them as no longer queued.
+func (e *queueTrackingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	defer e.release(spans)
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
+
+// release frees the queue slots and memory held by spans.
+func (e *queueTrackingExporter) release(spans []sdktrace.ReadOnlySpan) {
+	e.queue.inflight.Add(-int64(len(spans)))
+	if e.queue.maxBytes > 0 {
+		var size int64
+		for _, s := range spans {
+			size += spanSize(s)
+		}
+		e.queue.bytes.Add(-size)
+	}
+}
+
+// newBatchSpanProcessor returns a BatchSpanProcessor for exp whose queue
+// usage and drops are tracked by otelx. A positive maxBytes bounds the
+// approximate memory held by its queue.
+func newBatchSpanProcessor(exp sdktrace.SpanExporter, maxBytes int64) sdktrace.SpanProcessor {
+	size := spanQueueSize()
+	queue := &spanQueue{exporter: exporterName(exp), max: int64(size), maxBytes: maxBytes}
+	trackSpanQueue(queue)
+
+	bsp := sdktrace.NewBatchSpanProcessor(
+		&queueTrackingExporter{SpanExporter: exp, queue: queue},
+		sdktrace.WithMaxQueueSize(size),
+	)
+	return &boundedSpanProcessor{SpanProcessor: bsp, queue: queue}
+}
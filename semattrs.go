@@ -0,0 +1,133 @@
+package otelx
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// DBAttrs returns the semantic convention attributes of a database call:
+// db.system (such as "postgresql" or "redis"), db.namespace (the database
+// name) and db.operation.name (such as "SELECT" or "findAndModify"). Empty
+// values are omitted.
+//
+// Example:
+//
+//	ctx, span := otelx.StartSpan(ctx, trace.WithSpanKind(trace.SpanKindClient),
+//	    trace.WithAttributes(otelx.DBAttrs("postgresql", "orders", "SELECT")...))
+func DBAttrs(system, name, operation string) []attribute.KeyValue {
+	return nonEmpty(
+		semconv.DBSystemKey.String(system),
+		semconv.DBNamespaceKey.String(name),
+		semconv.DBOperationNameKey.String(operation),
+	)
+}
+
+// MessagingAttrs returns the semantic convention attributes of a messaging
+// operation: messaging.system (such as "kafka"), messaging.destination.name
+// and messaging.operation.type (such as "publish", "receive" or "process").
+// Empty values are omitted. StartProducerSpan and StartConsumerSpan set them
+// already.
+func MessagingAttrs(system, destination, operation string) []attribute.KeyValue {
+	return nonEmpty(
+		semconv.MessagingSystemKey.String(system),
+		semconv.MessagingDestinationNameKey.String(destination),
+		semconv.MessagingOperationTypeKey.String(operation),
+	)
+}
+
+// HTTPAttrs returns the semantic convention attributes describing req:
+// http.request.method, url.full (without user credentials), url.scheme,
+// server.address, server.port, user_agent.original and, for requests routed
+// by http.ServeMux, http.route. Methods outside the standard set are
+// recorded as "_OTHER" with http.request.method_original.
+func HTTPAttrs(req *http.Request) []attribute.KeyValue {
+	attrs := httpMethodAttrs(req.Method)
+
+	if req.URL != nil {
+		u := *req.URL
+		u.User = nil
+		if u.Host == "" {
+			u.Host = req.Host
+		}
+		if u.Scheme == "" {
+			u.Scheme = "http"
+			if req.TLS != nil {
+				u.Scheme = "https"
+			}
+		}
+		attrs = append(attrs,
+			semconv.URLFullKey.String(u.String()),
+			semconv.URLSchemeKey.String(u.Scheme),
+		)
+	}
+
+	host := req.Host
+	if req.URL != nil && req.URL.Host != "" {
+		host = req.URL.Host
+	}
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		attrs = append(attrs, semconv.ServerAddressKey.String(h))
+		if port, err := strconv.Atoi(p); err == nil {
+			attrs = append(attrs, semconv.ServerPortKey.Int(port))
+		}
+	} else if host != "" {
+		attrs = append(attrs, semconv.ServerAddressKey.String(host))
+	}
+
+	if ua := req.UserAgent(); ua != "" {
+		attrs = append(attrs, semconv.UserAgentOriginalKey.String(ua))
+	}
+	if route := patternRoute(matchedPattern(req)); route != "" {
+		attrs = append(attrs, semconv.HTTPRouteKey.String(route))
+	}
+	return attrs
+}
+
+// patternRoute returns the path of a ServeMux pattern such as
+// "GET example.com/items/{id}", without its method and host.
+func patternRoute(pattern string) string {
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimSpace(rest)
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
+
+// httpMethods are the request methods recorded as is in http.request.method.
+var httpMethods = map[string]bool{
+	http.MethodConnect: true, http.MethodDelete: true, http.MethodGet: true,
+	http.MethodHead: true, http.MethodOptions: true, http.MethodPatch: true,
+	http.MethodPost: true, http.MethodPut: true, http.MethodTrace: true,
+}
+
+// httpMethodAttrs returns the http.request.method attributes for method.
+func httpMethodAttrs(method string) []attribute.KeyValue {
+	if method == "" {
+		method = http.MethodGet
+	}
+	if httpMethods[method] {
+		return []attribute.KeyValue{semconv.HTTPRequestMethodKey.String(method)}
+	}
+	return []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String("_OTHER"),
+		semconv.HTTPRequestMethodOriginal(method),
+	}
+}
+
+// nonEmpty returns the attributes of attrs whose value is not empty.
+func nonEmpty(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	kept := attrs[:0]
+	for _, kv := range attrs {
+		if kv.Value.AsString() != "" {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
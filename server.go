@@ -0,0 +1,329 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a span for every incoming HTTP request, extracting
+// the remote trace context from the request headers using the global
+// propagator.
+//
+// Spans are SERVER spans named "<METHOD> <route>", such as
+// "GET /orders/{id}", once an http.ServeMux pattern matched the request, and
+// "<METHOD>" otherwise, so raw paths with IDs do not make span names
+// unbounded. They carry client.address, resolved by ClientIP, and http.route.
+// The route is seen through the otelx middleware, but a third-party
+// middleware passing a copy of the request (r.WithContext) straight to the
+// ServeMux hides it; wrap the ServeMux in RecoveryMiddleware or
+// MetricsMiddleware to keep it. The middleware only traces; request metrics
+// are recorded by MetricsMiddleware.
+//
+// When tracing is disabled (NewTraceProvider was not called or failed), next
+// is returned unchanged.
+//
+// Example:
+//
+//	handler := otelx.TracingMiddleware(otelx.MetricsMiddleware(mux))
+func TracingMiddleware(next http.Handler) http.Handler {
+	if !tracingEnabled.Load() {
+		return suppressedHandler(next, &suppressed.spans, 1)
+	}
+	return tracingHandler(next, nil)
+}
+
+// tracingHandler implements TracingMiddleware with tp, or the global tracer
+// provider when tp is nil.
+func tracingHandler(next http.Handler, tp trace.TracerProvider) http.Handler {
+	annotated := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(requestSpanAttributes(r)...)
+		r = withRouteHolder(r)
+		next.ServeHTTP(w, r)
+		recordRoute(r)
+
+		// The span was named before routing; ServeMux sets the pattern while
+		// routing, possibly on a copy of r made by an inner middleware.
+		if route := patternRoute(matchedPattern(r)); route != "" {
+			span.SetName(serverSpanName(r))
+			span.SetAttributes(semconv.HTTPRouteKey.String(route))
+		}
+	})
+
+	opts := []otelhttp.Option{
+		// otelx records its own request metrics in MetricsMiddleware.
+		otelhttp.WithMeterProvider(metricnoop.NewMeterProvider()),
+		otelhttp.WithSpanOptions(trace.WithSpanKind(trace.SpanKindServer)),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return serverSpanName(r)
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return settings().syntheticMode != SyntheticExclude || !isSynthetic(r)
+		}),
+	}
+	if tp != nil {
+		opts = append(opts, otelhttp.WithTracerProvider(tp))
+	}
+
+	return otelhttp.NewHandler(annotated, "http.server", opts...)
+}
+
+// serverSpanName returns the name of the SERVER span of r: the method and the
+// route of the matched ServeMux pattern, or only the method when no pattern
+// matched.
+func serverSpanName(r *http.Request) string {
+	if route := patternRoute(matchedPattern(r)); route != "" {
+		return r.Method + " " + route
+	}
+	return r.Method
+}
+
+// routeHolder carries the ServeMux pattern matched for a request up to the
+// otelx middleware wrapping the handler that passed a copy of the request
+// (r.WithContext) down the chain, since ServeMux only sets the pattern on the
+// request it receives.
+type routeHolder struct {
+	pattern string
+}
+
+type routeHolderKey struct{}
+
+// withRouteHolder returns r with a routeHolder in its context, unless an
+// outer middleware already added one.
+func withRouteHolder(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(routeHolderKey{}).(*routeHolder); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), routeHolderKey{}, &routeHolder{}))
+}
+
+// recordRoute records the pattern set on r while routing into the
+// routeHolder of its context. Middleware call it after serving the request
+// they passed to the next handler.
+func recordRoute(r *http.Request) {
+	if r.Pattern == "" {
+		return
+	}
+	if h, ok := r.Context().Value(routeHolderKey{}).(*routeHolder); ok {
+		h.pattern = r.Pattern
+	}
+}
+
+// matchedPattern returns the ServeMux pattern that matched r, either set on r
+// itself or recorded on a copy of it further down the chain.
+func matchedPattern(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	if h, ok := r.Context().Value(routeHolderKey{}).(*routeHolder); ok {
+		return h.pattern
+	}
+	return ""
+}
+
+// RecoveryMiddleware recovers from panics in next, records the panic and its
+// stack trace as an exception event on the active span, increments
+// panics_total (with method and path), logs it and responds with 500 Internal
+// Server Error if nothing has been written yet.
+//
+// http.ErrAbortHandler is re-panicked so the server can abort the response as
+// intended.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := NewResponseWriter(w)
+
+		defer recordRoute(r)
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			recordPanic(r.Context(), rec,
+				attribute.String("method", r.Method),
+				attribute.String("path", r.URL.Path),
+			)
+
+			if !rw.Written() {
+				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// serveConfig holds the settings used by Serve.
+type serveConfig struct {
+	ctx             context.Context
+	shutdownTimeout time.Duration
+	configure       []func(*http.Server)
+}
+
+// ServeOption customizes Serve.
+type ServeOption func(*serveConfig)
+
+// WithShutdownTimeout sets how long Serve waits for in-flight requests and the
+// telemetry flush during graceful shutdown. The default is 15 seconds.
+func WithShutdownTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.shutdownTimeout = d
+	}
+}
+
+// WithServeContext makes Serve shut down gracefully when ctx is canceled, in
+// addition to SIGINT and SIGTERM.
+func WithServeContext(ctx context.Context) ServeOption {
+	return func(c *serveConfig) {
+		c.ctx = ctx
+	}
+}
+
+// WithServer lets callers adjust the underlying *http.Server (timeouts,
+// TLS configuration, error log) before it starts.
+func WithServer(fn func(*http.Server)) ServeOption {
+	return func(c *serveConfig) {
+		c.configure = append(c.configure, fn)
+	}
+}
+
+// Serve runs an HTTP server on addr with otelx's instrumentation wired in the
+// correct order:
+//
+//	TracingMiddleware -> RequestIDMiddleware -> MetricsMiddleware -> RecoveryMiddleware -> handler
+//
+// It blocks until the server fails or receives SIGINT/SIGTERM (or the context
+// given through WithServeContext is canceled). On shutdown it stops accepting
+// connections, waits for in-flight requests and flushes pending spans and
+// metrics before returning.
+//
+// Connection metrics are recorded through ConnStateHook, chained after any
+// ConnState callback set by WithServer. With WithTLSMetrics, the TLS
+// configuration set by WithServer is wrapped with InstrumentTLSConfig.
+//
+// Serve must be called after NewTraceProvider() and NewMeterProvider(). It
+// returns nil after a graceful shutdown.
+//
+// Example:
+//
+//	tp, cleanupTrace := otelx.NewTraceProvider(ctx, "auth-service")
+//	defer cleanupTrace()
+//	cleanupMetrics := otelx.NewMeterProvider(ctx, "auth-service")
+//	defer cleanupMetrics()
+//
+//	if err := otelx.Serve(":8080", mux); err != nil {
+//	    log.Fatal(err)
+//	}
+func Serve(addr string, handler http.Handler, opts ...ServeOption) error {
+	cfg := serveConfig{
+		ctx:             context.Background(),
+		shutdownTimeout: 15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           TracingMiddleware(RequestIDMiddleware(MetricsMiddleware(RecoveryMiddleware(handler)))),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	for _, fn := range cfg.configure {
+		fn(srv)
+	}
+	srv.ConnState = ConnStateHook(srv.ConnState)
+	if settings().tlsMetrics && srv.TLSConfig != nil {
+		srv.TLSConfig = InstrumentTLSConfig(srv.TLSConfig)
+	}
+
+	ctx, stop := signal.NotifyContext(cfg.ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	flushTelemetry(shutdownCtx)
+	return err
+}
+
+// flusher is implemented by the SDK tracer and meter providers.
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// flushTelemetry flushes the global tracer and meter providers, if they
+// support it.
+func flushTelemetry(ctx context.Context) {
+	if f, ok := otel.GetTracerProvider().(flusher); ok {
+		if err := f.ForceFlush(ctx); err != nil {
+			logf("error flushing tracer provider: %v", err)
+		}
+	}
+	if f, ok := otel.GetMeterProvider().(flusher); ok {
+		if err := f.ForceFlush(ctx); err != nil {
+			logf("error flushing meter provider: %v", err)
+		}
+	}
+}
+
+// requestSpanAttributes returns the otelx-specific attributes recorded on
+// SERVER spans for r.
+func requestSpanAttributes(r *http.Request) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if ip := ClientIP(r); ip != "" {
+		attrs = append(attrs, attribute.String("client.address", ip))
+	}
+	if settings().userAgent {
+		attrs = append(attrs, attribute.String("user_agent.original", r.UserAgent()))
+		if settings().normalizeUserAgent {
+			attrs = append(attrs, attribute.String("user_agent.name", normalizeUserAgent(r.UserAgent())))
+		}
+	}
+	if settings().clientVersionHeader != "" {
+		if v := r.Header.Get(settings().clientVersionHeader); v != "" {
+			attrs = append(attrs, attribute.String("client.version", v))
+		}
+	}
+	if settings().tlsMetrics && r.TLS != nil {
+		attrs = append(attrs, tlsAttributes(*r.TLS)...)
+	}
+	if settings().syntheticMode == SyntheticLabel {
+		attrs = append(attrs, attribute.Bool("synthetic", isSynthetic(r)))
+	}
+	attrs = append(attrs, featureFlagAttributes(r.Context())...)
+	return append(attrs, capturedHeaderAttributes(r)...)
+}
@@ -0,0 +1,115 @@
+package otelx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestServerSpanName(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		pattern string
+		want    string
+	}{
+		{name: "method pattern", method: http.MethodGet, path: "/orders/42", pattern: "GET /orders/{id}", want: "GET /orders/{id}"},
+		{name: "host pattern", method: http.MethodPost, path: "/orders", pattern: "POST api.example.com/orders", want: "POST /orders"},
+		{name: "path pattern", method: http.MethodDelete, path: "/orders/42", pattern: "/orders/", want: "DELETE /orders/"},
+		{name: "no pattern", method: http.MethodGet, path: "/orders/42", want: "GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Pattern = tt.pattern
+			if got := serverSpanName(r); got != tt.want {
+				t.Errorf("serverSpanName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeNamesSpansAfterRoute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	savedTP, savedEnabled := otel.GetTracerProvider(), tracingEnabled.Load()
+	otel.SetTracerProvider(tp)
+	tracingEnabled.Store(true)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(savedTP)
+		tracingEnabled.Store(savedEnabled)
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	// Wait for the connection to be closed, so its ConnStateHook call does
+	// not outlive the test.
+	var conns sync.WaitGroup
+	trackConns := WithServer(func(srv *http.Server) {
+		srv.ConnState = func(_ net.Conn, state http.ConnState) {
+			switch state {
+			case http.StateNew:
+				conns.Add(1)
+			case http.StateClosed, http.StateHijacked:
+				conns.Done()
+			}
+		}
+	})
+	go func() { done <- Serve(addr, mux, WithServeContext(ctx), trackConns) }()
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + addr + "/orders/42"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	conns.Wait()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	if got := spans[0].Name(); got != "GET /orders/{id}" {
+		t.Errorf("span name = %q, want %q", got, "GET /orders/{id}")
+	}
+	var route string
+	for _, kv := range spans[0].Attributes() {
+		if kv.Key == semconv.HTTPRouteKey {
+			route = kv.Value.AsString()
+		}
+	}
+	if route != "/orders/{id}" {
+		t.Errorf("http.route = %q, want %q", route, "/orders/{id}")
+	}
+}
@@ -0,0 +1,246 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+
+	api "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// servicesEnabled reports whether at least one Service was created. The
+// middleware and interceptors then stay active even when the global providers
+// are not initialized, so they can record into the Service found in the
+// request context.
+var servicesEnabled atomic.Bool
+
+// serviceKey is the context key holding the active Service.
+type serviceKey struct{}
+
+// Service is a named otelx instance with its own service.name resource,
+// TracerProvider and MeterProvider, sharing the collector connection with the
+// rest of the process.
+//
+// It lets a modular monolith host several logical services in one binary:
+// requests entering through a Service's middleware or interceptors carry it in
+// their context, and otelx's instrumentation (spans, request metrics, SLO and
+// slow request tracking, StartSpan) records into that Service instead of the
+// global providers.
+//
+// Example:
+//
+//	billing, err := otelx.NewService(ctx, "billing")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer billing.Shutdown(ctx)
+//
+//	mux.Handle("/billing/", billing.Middleware(billingHandler))
+type Service struct {
+	name    string
+	tp      *sdktrace.TracerProvider
+	mp      *sdkmetric.MeterProvider
+	tracer  trace.Tracer
+	meter   api.Meter
+	metrics Metrics
+}
+
+// NewService creates a named instance on the shared collector connection.
+//
+// The Options configure this Service's providers only (exporters, readers,
+// span processors, scrubbing rules, ID generator...), starting from the
+// defaults rather than the options given to the global providers, which they
+// do not change. Span processors, readers and exporters passed here belong to
+// the Service and are shut down with it. The sampler, span filtering and the
+// process-wide settings (clock, instrument names) are shared with the global
+// providers; connection options only take effect if the collector connection
+// is not established yet.
+//
+// It returns an error when telemetry is disabled or the collector connection
+// cannot be created.
+func NewService(ctx context.Context, name string, opts ...Option) (*Service, error) {
+	cfg := serviceConfig(opts)
+
+	conn, err := connectCollector(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("creating resource: %w", err)
+	}
+
+	// Without global providers, initialize the shared span filtering and
+	// reloadable settings like NewTraceProvider does.
+	if !tracingEnabled.Load() {
+		baseDropRules = settings().spanDropRules
+		setDropRules(settings().spanDropRules)
+		if err := Reload(); err != nil {
+			logf("invalid telemetry configuration: %v\n", err)
+		}
+	}
+
+	tpOpts, err := tracerProviderOptions(ctx, conn, cfg, res)
+	if err != nil {
+		return nil, fmt.Errorf("creating span exporter: %w", err)
+	}
+	mpOpts, err := meterProviderOptions(ctx, conn, cfg, res)
+	if err != nil {
+		return nil, fmt.Errorf("creating metric exporter: %w", err)
+	}
+
+	s := &Service{
+		name: name,
+		tp:   sdktrace.NewTracerProvider(tpOpts...),
+		mp:   sdkmetric.NewMeterProvider(mpOpts...),
+	}
+	s.tracer = s.tp.Tracer(name, tracerOptions()...)
+	s.meter = s.mp.Meter(name, meterOptions()...)
+	if s.metrics, err = newMetrics(s.meter); err != nil {
+		return nil, errors.Join(fmt.Errorf("creating instruments: %w", err), s.Shutdown(ctx))
+	}
+
+	setPropagator()
+	servicesEnabled.Store(true)
+	return s, nil
+}
+
+// serviceConfig returns the defaults with opts applied, leaving the package
+// settings untouched.
+func serviceConfig(opts []Option) config {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return cfg
+}
+
+// Name returns the service name.
+func (s *Service) Name() string {
+	return s.name
+}
+
+// TracerProvider returns the Service's TracerProvider.
+func (s *Service) TracerProvider() *sdktrace.TracerProvider {
+	return s.tp
+}
+
+// MeterProvider returns the Service's MeterProvider.
+func (s *Service) MeterProvider() *sdkmetric.MeterProvider {
+	return s.mp
+}
+
+// Tracer returns the Service's tracer.
+func (s *Service) Tracer() trace.Tracer {
+	return s.tracer
+}
+
+// StartSpan is like the package-level StartSpan but always uses the
+// Service's tracer.
+func (s *Service) StartSpan(ctx context.Context, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	pc, _, line, _ := runtime.Caller(1)
+	fn := runtime.FuncForPC(pc)
+
+	ctx = ContextWithService(ctx, s)
+	name := fmt.Sprintf("%s:%d", fn.Name(), line)
+	spanCtx, span := s.tracer.Start(ctx, name, extractedAttributes(ctx, opts)...)
+	return withProfilerLabels(ctx, spanCtx, span, name)
+}
+
+// Middleware instruments next for this Service: it traces requests like
+// TracingMiddleware and records request metrics like MetricsMiddleware, both
+// tagged with the Service's resource.
+func (s *Service) Middleware(next http.Handler) http.Handler {
+	traced := tracingHandler(MetricsMiddleware(next), s.tp)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(ContextWithService(r.Context(), s))
+		traced.ServeHTTP(w, r)
+		recordRoute(r)
+	})
+}
+
+// UnaryServerInterceptor tags incoming RPCs with the Service. Place it first
+// in the chain so the otelx tracing and metrics interceptors that follow
+// record into the Service:
+//
+//	grpc.ChainUnaryInterceptor(
+//	    billing.UnaryServerInterceptor(),
+//	    otelx.UnaryServerTracingInterceptor(),
+//	    otelx.UnaryServerMetricsInterceptor(),
+//	)
+func (s *Service) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(ContextWithService(ctx, s), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func (s *Service) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := ContextWithService(ss.Context(), s)
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// Shutdown flushes and shuts down the Service's providers.
+func (s *Service) Shutdown(ctx context.Context) error {
+	return errors.Join(s.tp.Shutdown(ctx), s.mp.Shutdown(ctx))
+}
+
+// ContextWithService returns a copy of ctx whose telemetry is recorded into s.
+func ContextWithService(ctx context.Context, s *Service) context.Context {
+	return context.WithValue(ctx, serviceKey{}, s)
+}
+
+// ServiceFromContext returns the Service carried by ctx, or nil.
+func ServiceFromContext(ctx context.Context) *Service {
+	s, _ := ctx.Value(serviceKey{}).(*Service)
+	return s
+}
+
+// instrumentsFor returns the request instruments to record into for ctx: the
+// Service's if ctx carries one, otherwise the global ones. It returns nil when
+// neither is available.
+func instrumentsFor(ctx context.Context) *Metrics {
+	if s := ServiceFromContext(ctx); s != nil {
+		return &s.metrics
+	}
+	if metricsEnabled.Load() {
+		return &metrics
+	}
+	return nil
+}
+
+// tracerFor returns the tracer to use for ctx: the Service's if ctx carries
+// one, otherwise the global tracer. It returns nil when neither is available.
+func tracerFor(ctx context.Context) trace.Tracer {
+	if s := ServiceFromContext(ctx); s != nil {
+		return s.tracer
+	}
+	if tracingEnabled.Load() {
+		return tracer
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package otelx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// testService returns a Service named name recording into the returned span
+// recorder and metric reader, as NewService would without a collector.
+func testService(t *testing.T, name string) (*Service, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	res := resource.NewSchemaless(semconv.ServiceNameKey.String(name))
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	s := &Service{
+		name: name,
+		tp:   sdktrace.NewTracerProvider(sdktrace.WithResource(res), sdktrace.WithSpanProcessor(spans)),
+		mp:   sdkmetric.NewMeterProvider(sdkmetric.WithResource(res), sdkmetric.WithReader(reader)),
+	}
+	s.tracer = s.tp.Tracer(name)
+	s.meter = s.mp.Meter(name)
+
+	var err error
+	if s.metrics, err = newMetrics(s.meter); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := servicesEnabled.Load()
+	servicesEnabled.Store(true)
+	t.Cleanup(func() {
+		servicesEnabled.Store(saved)
+		s.Shutdown(context.Background())
+	})
+	return s, spans, reader
+}
+
+func TestServiceMiddleware(t *testing.T) {
+	billing, billingSpans, billingMetrics := testService(t, "billing")
+	shipping, shippingSpans, shippingMetrics := testService(t, "shipping")
+
+	var seen *Service
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		seen = ServiceFromContext(r.Context())
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /billing/{id}", billing.Middleware(http.HandlerFunc(handler)))
+	mux.Handle("GET /shipping/{id}", shipping.Middleware(http.HandlerFunc(handler)))
+
+	tests := []struct {
+		name    string
+		path    string
+		service *Service
+		spans   *tracetest.SpanRecorder
+		metrics *sdkmetric.ManualReader
+		span    string
+	}{
+		{name: "billing", path: "/billing/7", service: billing, spans: billingSpans, metrics: billingMetrics, span: "GET /billing/{id}"},
+		{name: "shipping", path: "/shipping/9", service: shipping, spans: shippingSpans, metrics: shippingMetrics, span: "GET /shipping/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if seen != tt.service {
+				t.Errorf("handler saw Service %v, want %s", seen, tt.service.Name())
+			}
+
+			ended := tt.spans.Ended()
+			if len(ended) != 1 {
+				t.Fatalf("%d spans recorded, want 1", len(ended))
+			}
+			if got := ended[0].Name(); got != tt.span {
+				t.Errorf("span name = %q, want %q", got, tt.span)
+			}
+			if got, _ := ended[0].Resource().Set().Value(semconv.ServiceNameKey); got.AsString() != tt.name {
+				t.Errorf("span service.name = %q, want %q", got.AsString(), tt.name)
+			}
+
+			var rm metricdata.ResourceMetrics
+			if err := tt.metrics.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+			if got := requestCount(rm, tt.path); got != 1 {
+				t.Errorf("%d requests counted for %s, want 1", got, tt.path)
+			}
+		})
+	}
+}
+
+func TestServiceStartSpan(t *testing.T) {
+	s, spans, _ := testService(t, "billing")
+
+	ctx, span := s.StartSpan(context.Background())
+	span.End()
+
+	if ServiceFromContext(ctx) != s {
+		t.Error("StartSpan() context does not carry the Service")
+	}
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("%d spans recorded, want 1", len(ended))
+	}
+	if want := "TestServiceStartSpan"; !strings.Contains(ended[0].Name(), want) {
+		t.Errorf("span name = %q, want it to name the caller %s", ended[0].Name(), want)
+	}
+	if tracerFor(ctx) != s.tracer {
+		t.Error("tracerFor() does not return the Service's tracer")
+	}
+}
+
+func TestServiceConfig(t *testing.T) {
+	useSettings(t, func(c *config) { c.metricAttributes = map[attribute.Key]bool{"method": true} })
+
+	cfg := serviceConfig([]Option{WithMetricCardinalityLimit(5), nil})
+	if cfg.attributeValueLimit != 5 {
+		t.Errorf("attributeValueLimit = %d, want 5", cfg.attributeValueLimit)
+	}
+	if cfg.metricAttributes != nil {
+		t.Error("serviceConfig() inherited the shared metric allowlist")
+	}
+	if settings().attributeValueLimit == 5 {
+		t.Error("serviceConfig() changed the shared settings")
+	}
+}
+
+func TestNewServiceDisabled(t *testing.T) {
+	t.Setenv("OTEL_ENABLE", "false")
+	t.Setenv("ENV", "")
+
+	if s, err := NewService(context.Background(), "billing"); err == nil {
+		s.Shutdown(context.Background())
+		t.Fatal("NewService() succeeded with telemetry disabled")
+	}
+}
+
+// requestCount returns the http_requests_total count of path in rm.
+func requestCount(rm metricdata.ResourceMetrics, path string) int64 {
+	var n int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != instrumentName("http_requests_total") {
+				continue
+			}
+			for _, p := range sum.DataPoints {
+				if v, _ := p.Attributes.Value("path"); v.AsString() == path {
+					n += p.Value
+				}
+			}
+		}
+	}
+	return n
+}
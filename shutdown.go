@@ -0,0 +1,86 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// shutdownOrder is the order in which Shutdown stops the providers. Metrics
+// go last so the export self-telemetry of the other signals is flushed too.
+var shutdownOrder = []string{"traces", "logs", "metrics"}
+
+// shutdownHooks holds the shutdown functions of the providers created by
+// NewTraceProvider, NewLoggerProvider and NewMeterProvider, by signal.
+var shutdownHooks struct {
+	mu    sync.Mutex
+	hooks map[string]func(context.Context) error
+}
+
+// registerShutdown records fn as the shutdown of signal's provider for
+// Shutdown and returns fn wrapped to run at most once, so the cleanup
+// functions returned to callers and Shutdown can both be used.
+func registerShutdown(signal string, fn func(context.Context) error) func(context.Context) error {
+	var (
+		once sync.Once
+		err  error
+	)
+	wrapped := func(ctx context.Context) error {
+		once.Do(func() { err = fn(ctx) })
+		return err
+	}
+
+	shutdownHooks.mu.Lock()
+	defer shutdownHooks.mu.Unlock()
+	if shutdownHooks.hooks == nil {
+		shutdownHooks.hooks = make(map[string]func(context.Context) error)
+	}
+	shutdownHooks.hooks[signal] = wrapped
+	return wrapped
+}
+
+// Shutdown flushes and shuts down the tracer, logger and meter providers
+// created by otelx, in that order, then closes the collector connection. It
+// replaces calling each cleanup function in the right order, and keeps
+// short-lived jobs from leaking the connection.
+//
+// A connection given with WithCollectorConn is left open for its owner to
+// close. Providers created by NewService are shut down by Service.Shutdown.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "nightly-report")
+//	otelx.NewMeterProvider(ctx, "nightly-report")
+//	defer func() {
+//	    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	    defer cancel()
+//	    if err := otelx.Shutdown(ctx); err != nil {
+//	        log.Println(err)
+//	    }
+//	}()
+func Shutdown(ctx context.Context) error {
+	shutdownHooks.mu.Lock()
+	hooks := shutdownHooks.hooks
+	shutdownHooks.hooks = nil
+	shutdownHooks.mu.Unlock()
+
+	var errs []error
+	for _, signal := range shutdownOrder {
+		if fn, ok := hooks[signal]; ok {
+			if err := fn(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("shutting down %s provider: %w", signal, err))
+			}
+		}
+	}
+
+	if conn := grpcConnection; conn != nil {
+		grpcConnection = nil
+		if conn != settings().collectorConn {
+			if err := conn.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing collector connection: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package otelx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// routeThresholds maps HTTP paths or gRPC methods to latency thresholds.
+//
+// Keys are matched exactly; a key ending in "*" matches every route with that
+// prefix, the longest prefix winning.
+type routeThresholds map[string]time.Duration
+
+// lookup returns the threshold configured for route.
+func (t routeThresholds) lookup(route string) (time.Duration, bool) {
+	if len(t) == 0 {
+		return 0, false
+	}
+	if d, ok := t[route]; ok {
+		return d, true
+	}
+
+	best := -1
+	var found time.Duration
+	for key, d := range t {
+		prefix, ok := strings.CutSuffix(key, "*")
+		if ok && strings.HasPrefix(route, prefix) && len(prefix) > best {
+			best, found = len(prefix), d
+		}
+	}
+	return found, best >= 0
+}
+
+// set adds a threshold, allocating the map on first use.
+func (t *routeThresholds) set(route string, d time.Duration) {
+	if *t == nil {
+		*t = make(routeThresholds)
+	}
+	(*t)[route] = d
+}
+
+// WithSLO declares a latency objective for an HTTP route or gRPC full method.
+// HTTP routes are matched against the route of the http.ServeMux pattern
+// serving the request, such as "/orders/{id}", or against the path for
+// requests no pattern matched.
+//
+// Requests slower than the objective increment slo_breaches_total (with the
+// same method/path/status_code attributes as the request metrics) and mark
+// the active span with slo.breached=true, enabling burn-rate alerts without
+// PromQL gymnastics. A route ending in "*" matches every route with that
+// prefix.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "shop",
+//	    otelx.WithSLO("/checkout", 300*time.Millisecond),
+//	    otelx.WithSLO("/orders/{id}", 200*time.Millisecond),
+//	    otelx.WithSLO("/shop.v1.Cart/*", 100*time.Millisecond),
+//	)
+func WithSLO(route string, objective time.Duration) Option {
+	return func(c *config) {
+		c.slos.set(route, objective)
+	}
+}
+
+// checkSLO records an SLO breach for route into m if duration (in seconds)
+// exceeds its objective.
+func checkSLO(ctx context.Context, m *Metrics, route string, duration float64, attrs metric.MeasurementOption) {
+	objective, ok := settings().slos.lookup(route)
+	if !ok {
+		return
+	}
+
+	breached := duration > objective.Seconds()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.Bool("slo.breached", breached),
+		attribute.Float64("slo.objective_seconds", objective.Seconds()),
+	)
+
+	if breached {
+		m.SLOBreachCounter.Add(ctx, 1, attrs)
+	}
+}
@@ -0,0 +1,77 @@
+package otelx
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithSlowThreshold declares the latency above which requests to an HTTP route
+// or gRPC full method are considered slow. HTTP routes are matched like those
+// of WithSLO, against the matched http.ServeMux pattern such as
+// "/orders/{id}" or else the path. A route ending in "*" matches every route
+// with that prefix, so "*" alone sets a default for all routes.
+//
+// Slow requests are marked with slow=true on the active span, get a
+// "slow_request" span event with the timing breakdown and increment
+// slow_requests_total (with the request metric attributes), powering
+// "slowest endpoints" triage views.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "shop",
+//	    otelx.WithSlowThreshold("*", time.Second),
+//	    otelx.WithSlowThreshold("/search", 3*time.Second),
+//	)
+func WithSlowThreshold(route string, threshold time.Duration) Option {
+	return func(c *config) {
+		c.slowThresholds.set(route, threshold)
+	}
+}
+
+// activeSlowThresholds, when set, replaces the thresholds configured through
+// WithSlowThreshold with those merged from OTELX_CONFIG_FILE.
+var activeSlowThresholds atomic.Pointer[routeThresholds]
+
+// checkSlow annotates the span in ctx and counts the request in m if duration (in
+// seconds) exceeds the slow threshold of route. breakdown adds
+// transport-specific timings to the span event.
+func checkSlow(ctx context.Context, m *Metrics, route string, duration float64, attrs metric.MeasurementOption, breakdown ...attribute.KeyValue) {
+	thresholds := settings().slowThresholds
+	if active := activeSlowThresholds.Load(); active != nil {
+		thresholds = *active
+	}
+
+	threshold, ok := thresholds.lookup(route)
+	if !ok || duration <= threshold.Seconds() {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Bool("slow", true))
+	span.AddEvent("slow_request", trace.WithAttributes(append([]attribute.KeyValue{
+		attribute.Float64("duration_seconds", duration),
+		attribute.Float64("threshold_seconds", threshold.Seconds()),
+	}, breakdown...)...))
+
+	m.SlowRequestCounter.Add(ctx, 1, attrs)
+}
+
+// httpTimingBreakdown splits an HTTP request duration into the time spent
+// before the response started and the time spent writing it. It is only
+// available for writers created by otelx.
+func httpTimingBreakdown(start time.Time, w ResponseWriter, duration float64) []attribute.KeyValue {
+	rw, ok := w.(*responseWriter)
+	if !ok || rw.firstWrite.IsZero() {
+		return nil
+	}
+	ttfb := rw.firstWrite.Sub(start).Seconds()
+	return []attribute.KeyValue{
+		attribute.Float64("time_to_first_byte_seconds", ttfb),
+		attribute.Float64("response_write_seconds", duration-ttfb),
+	}
+}
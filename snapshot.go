@@ -0,0 +1,91 @@
+package otelx
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanSnapshot is a finished span built outside the SDK, such as a span
+// replayed from the disk buffer or the test span sent by Verify, that can be
+// handed to a SpanExporter.
+type spanSnapshot struct {
+	// ReadOnlySpan is never set. Embedding it provides the interface's
+	// unexported method; every other method is implemented below.
+	sdktrace.ReadOnlySpan
+
+	name              string
+	spanContext       trace.SpanContext
+	parent            trace.SpanContext
+	kind              trace.SpanKind
+	start, end        time.Time
+	attributes        []attribute.KeyValue
+	links             []sdktrace.Link
+	events            []sdktrace.Event
+	status            sdktrace.Status
+	scope             instrumentation.Scope
+	resource          *resource.Resource
+	droppedAttributes int
+	droppedLinks      int
+	droppedEvents     int
+	childSpanCount    int
+}
+
+var _ sdktrace.ReadOnlySpan = (*spanSnapshot)(nil)
+
+// Name returns the span name.
+func (s *spanSnapshot) Name() string { return s.name }
+
+// SpanContext returns the span's context.
+func (s *spanSnapshot) SpanContext() trace.SpanContext { return s.spanContext }
+
+// Parent returns the parent's span context.
+func (s *spanSnapshot) Parent() trace.SpanContext { return s.parent }
+
+// SpanKind returns the span kind.
+func (s *spanSnapshot) SpanKind() trace.SpanKind { return s.kind }
+
+// StartTime returns the span start time.
+func (s *spanSnapshot) StartTime() time.Time { return s.start }
+
+// EndTime returns the span end time.
+func (s *spanSnapshot) EndTime() time.Time { return s.end }
+
+// Attributes returns the span attributes.
+func (s *spanSnapshot) Attributes() []attribute.KeyValue { return s.attributes }
+
+// Links returns the span links.
+func (s *spanSnapshot) Links() []sdktrace.Link { return s.links }
+
+// Events returns the span events.
+func (s *spanSnapshot) Events() []sdktrace.Event { return s.events }
+
+// Status returns the span status.
+func (s *spanSnapshot) Status() sdktrace.Status { return s.status }
+
+// InstrumentationScope returns the scope that created the span.
+func (s *spanSnapshot) InstrumentationScope() instrumentation.Scope { return s.scope }
+
+// InstrumentationLibrary returns the scope that created the span.
+//
+//nolint:staticcheck // Required by sdktrace.ReadOnlySpan.
+func (s *spanSnapshot) InstrumentationLibrary() instrumentation.Library { return s.scope }
+
+// Resource returns the resource of the span.
+func (s *spanSnapshot) Resource() *resource.Resource { return s.resource }
+
+// DroppedAttributes returns the number of dropped attributes.
+func (s *spanSnapshot) DroppedAttributes() int { return s.droppedAttributes }
+
+// DroppedLinks returns the number of dropped links.
+func (s *spanSnapshot) DroppedLinks() int { return s.droppedLinks }
+
+// DroppedEvents returns the number of dropped events.
+func (s *spanSnapshot) DroppedEvents() int { return s.droppedEvents }
+
+// ChildSpanCount returns the number of child spans.
+func (s *spanSnapshot) ChildSpanCount() int { return s.childSpanCount }
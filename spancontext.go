@@ -0,0 +1,105 @@
+package otelx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// w3cPropagator encodes persisted and raw span contexts. It is fixed to the
+// W3C formats, independently of the global propagator, so rows written by one
+// deployment can be read by another.
+var w3cPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// MarshalSpanContext serializes the span context and baggage of ctx to a
+// small JSON document (W3C traceparent, tracestate and baggage), so producers
+// can persist it in job rows or outbox tables and consumers can resume the
+// trace hours later with UnmarshalSpanContext.
+//
+// It returns an error if ctx carries no valid span context.
+//
+// Example:
+//
+//	data, err := otelx.MarshalSpanContext(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	_, err = db.ExecContext(ctx, "INSERT INTO jobs (payload, trace) VALUES ($1, $2)", payload, data)
+func MarshalSpanContext(ctx context.Context) ([]byte, error) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil, errors.New("context carries no valid span context")
+	}
+
+	carrier := propagation.MapCarrier{}
+	w3cPropagator.Inject(ctx, carrier)
+	return json.Marshal(carrier)
+}
+
+// UnmarshalSpanContext restores a span context serialized by
+// MarshalSpanContext into ctx, as a remote parent along with its baggage.
+//
+// Start a span from the returned context to continue the trace as a child,
+// or link to it when the delayed work should be a trace of its own:
+//
+//	restored, err := otelx.UnmarshalSpanContext(ctx, row.Trace)
+//	if err != nil {
+//	    log.Printf("ignoring invalid trace context: %v", err)
+//	}
+//
+//	// As a child:
+//	ctx, span := otelx.StartConsumerSpan(restored, "jobs", "emails")
+//
+//	// Or as a link:
+//	ctx, span := otelx.StartSpan(ctx, trace.WithLinks(trace.LinkFromContext(restored)))
+//
+// On error, ctx is returned unchanged.
+func UnmarshalSpanContext(ctx context.Context, data []byte) (context.Context, error) {
+	var carrier propagation.MapCarrier
+	if err := json.Unmarshal(data, &carrier); err != nil {
+		return ctx, fmt.Errorf("decoding span context: %w", err)
+	}
+
+	restored := w3cPropagator.Extract(ctx, carrier)
+	if !trace.SpanContextFromContext(restored).IsValid() {
+		return ctx, errors.New("decoding span context: no valid traceparent")
+	}
+	return restored, nil
+}
+
+// ContextFromRemote returns a copy of ctx whose remote parent is the raw W3C
+// traceparent header value, for protocols that only hand over the header as a
+// string (webhooks, CSV batch files, third-party callbacks). tracestate and
+// baggage are optional and may be empty.
+//
+// Spans started from the returned context continue the remote trace:
+//
+//	remote, err := otelx.ContextFromRemote(ctx, row["traceparent"], "", "")
+//	if err != nil {
+//	    remote = ctx // start a new trace instead
+//	}
+//	ctx, span := otelx.StartSpan(remote)
+//	defer span.End()
+//
+// It returns ctx unchanged and an error if traceparent is invalid.
+func ContextFromRemote(ctx context.Context, traceparent, tracestate, baggage string) (context.Context, error) {
+	carrier := propagation.MapCarrier{"traceparent": traceparent}
+	if tracestate != "" {
+		carrier["tracestate"] = tracestate
+	}
+	if baggage != "" {
+		carrier["baggage"] = baggage
+	}
+
+	remote := w3cPropagator.Extract(ctx, carrier)
+	if !trace.SpanContextFromContext(remote).IsValid() {
+		return ctx, fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+	return remote, nil
+}
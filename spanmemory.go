@@ -0,0 +1,65 @@
+package otelx
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// WithSpanMemoryLimit bounds the approximate memory held by spans waiting in
+// each exporter's batch queue. Once the budget is reached, newly ended spans
+// are dropped and counted in otelx_spans_dropped_total{reason="memory_limit"}
+// until exports free space, so a collector outage cannot grow the queue
+// until the process is OOM killed.
+//
+// The size of a span is estimated from its name, attributes, events and
+// links; it is not an exact heap measurement. Without this option only the
+// queue length (OTEL_BSP_MAX_QUEUE_SIZE) is bounded.
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "ingest", otelx.WithSpanMemoryLimit(64<<20))
+func WithSpanMemoryLimit(bytes int64) Option {
+	return func(c *config) {
+		c.spanMemoryLimit = bytes
+	}
+}
+
+// spanOverhead approximates the fixed size of a span: its identifiers,
+// timestamps, status, resource and scope references.
+const spanOverhead = 256
+
+// spanSize estimates the memory held by s in bytes.
+func spanSize(s sdktrace.ReadOnlySpan) int64 {
+	size := int64(spanOverhead + len(s.Name()))
+	size += attributesSize(s.Attributes())
+	for _, e := range s.Events() {
+		size += int64(64+len(e.Name)) + attributesSize(e.Attributes)
+	}
+	for _, l := range s.Links() {
+		size += 64 + attributesSize(l.Attributes)
+	}
+	return size
+}
+
+// attributesSize estimates the memory held by attrs in bytes.
+func attributesSize(attrs []attribute.KeyValue) int64 {
+	var size int64
+	for _, kv := range attrs {
+		size += int64(32 + len(kv.Key))
+		switch kv.Value.Type() {
+		case attribute.STRING:
+			size += int64(len(kv.Value.AsString()))
+		case attribute.STRINGSLICE:
+			for _, v := range kv.Value.AsStringSlice() {
+				size += int64(16 + len(v))
+			}
+		case attribute.BOOLSLICE:
+			size += int64(len(kv.Value.AsBoolSlice()))
+		case attribute.INT64SLICE:
+			size += int64(8 * len(kv.Value.AsInt64Slice()))
+		case attribute.FLOAT64SLICE:
+			size += int64(8 * len(kv.Value.AsFloat64Slice()))
+		}
+	}
+	return size
+}
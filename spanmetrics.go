@@ -0,0 +1,105 @@
+package otelx
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	api "go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// WithSpanMetrics enables in-process RED metrics derived from finished spans,
+// similar to the collector's spanmetrics connector:
+//
+//	span_calls_total{span_name, span_kind, status_code}      (counter)
+//	span_errors_total{span_name, span_kind}                  (counter)
+//	span_duration_seconds{span_name, span_kind, status_code} (histogram)
+//
+// This is useful for teams running traces-only backends that still want RED
+// dashboards. Spans dropped by the span filter are not counted.
+//
+// The metrics are recorded through the global MeterProvider, so
+// NewMeterProvider must also be called for them to be exported.
+func WithSpanMetrics() Option {
+	return func(c *config) {
+		c.spanMetrics = true
+	}
+}
+
+// spanMetricsProcessor is an sdktrace.SpanProcessor that records call count,
+// error count and duration for every finished span.
+type spanMetricsProcessor struct {
+	calls    api.Int64Counter
+	errors   api.Int64Counter
+	duration api.Float64Histogram
+}
+
+var _ sdktrace.SpanProcessor = (*spanMetricsProcessor)(nil)
+
+// newSpanMetricsProcessor creates the span metrics instruments. It returns nil
+// if they cannot be created.
+func newSpanMetricsProcessor() *spanMetricsProcessor {
+	meter := otel.Meter(instrumentationName)
+
+	calls, err := meter.Int64Counter(
+		instrumentName("span_calls_total"),
+		instrumentDescription("span_calls_total", "Total number of finished spans"),
+		api.WithUnit("{span}"),
+	)
+	if err != nil {
+		logf("failed to create span calls counter: %v\n", err)
+		return nil
+	}
+
+	errs, err := meter.Int64Counter(
+		instrumentName("span_errors_total"),
+		instrumentDescription("span_errors_total", "Total number of finished spans with error status"),
+		api.WithUnit("{span}"),
+	)
+	if err != nil {
+		logf("failed to create span errors counter: %v\n", err)
+		return nil
+	}
+
+	duration, err := meter.Float64Histogram(
+		instrumentName("span_duration_seconds"),
+		instrumentDescription("span_duration_seconds", "Span duration in seconds"),
+		api.WithUnit("s"),
+		api.WithExplicitBucketBoundaries(
+			0.005, 0.01, 0.025, 0.05, 0.1,
+			0.25, 0.5, 1.0, 2.5, 5.0, 10.0,
+		),
+	)
+	if err != nil {
+		logf("failed to create span duration histogram: %v\n", err)
+		return nil
+	}
+
+	return &spanMetricsProcessor{calls: calls, errors: errs, duration: duration}
+}
+
+// OnStart is a no-op.
+func (p *spanMetricsProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd records the metrics for s.
+func (p *spanMetricsProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	ctx := context.Background()
+	name := attribute.String("span_name", s.Name())
+	kind := attribute.String("span_kind", s.SpanKind().String())
+
+	attrs := api.WithAttributes(name, kind, attribute.String("status_code", s.Status().Code.String()))
+	p.calls.Add(ctx, 1, attrs)
+	p.duration.Record(ctx, s.EndTime().Sub(s.StartTime()).Seconds(), attrs)
+
+	if s.Status().Code == codes.Error {
+		p.errors.Add(ctx, 1, api.WithAttributes(name, kind))
+	}
+}
+
+// Shutdown is a no-op.
+func (p *spanMetricsProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush is a no-op.
+func (p *spanMetricsProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,95 @@
+package otelx
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// statusDetailEvent is the name of the span events describing gRPC status
+// details.
+const statusDetailEvent = "rpc.grpc.status_detail"
+
+// recordStatusDetails adds a span event per detail attached to s, so the
+// structured reasons behind a failed call (ErrorInfo, BadRequest field
+// violations, RetryInfo, ...) can be read from the trace. BadRequest and
+// PreconditionFailure get an event per violation. Details of other types are
+// recorded with their type only.
+func recordStatusDetails(span trace.Span, s *status.Status) {
+	if len(s.Proto().GetDetails()) == 0 {
+		return
+	}
+
+	for _, d := range s.Details() {
+		msg, ok := d.(proto.Message)
+		if !ok {
+			// Details that failed to unmarshal are returned as errors.
+			continue
+		}
+		typ := attribute.String("rpc.grpc.status_detail.type", string(msg.ProtoReflect().Descriptor().FullName()))
+
+		switch detail := msg.(type) {
+		case *errdetails.ErrorInfo:
+			attrs := []attribute.KeyValue{typ,
+				attribute.String("error_info.reason", detail.GetReason()),
+				attribute.String("error_info.domain", detail.GetDomain()),
+			}
+			for k, v := range detail.GetMetadata() {
+				attrs = append(attrs, attribute.String("error_info.metadata."+k, v))
+			}
+			span.AddEvent(statusDetailEvent, trace.WithAttributes(attrs...))
+
+		case *errdetails.BadRequest:
+			for _, v := range detail.GetFieldViolations() {
+				span.AddEvent(statusDetailEvent, trace.WithAttributes(typ,
+					attribute.String("bad_request.field", v.GetField()),
+					attribute.String("bad_request.description", v.GetDescription()),
+					attribute.String("bad_request.reason", v.GetReason()),
+				))
+			}
+
+		case *errdetails.PreconditionFailure:
+			for _, v := range detail.GetViolations() {
+				span.AddEvent(statusDetailEvent, trace.WithAttributes(typ,
+					attribute.String("precondition_failure.type", v.GetType()),
+					attribute.String("precondition_failure.subject", v.GetSubject()),
+					attribute.String("precondition_failure.description", v.GetDescription()),
+				))
+			}
+
+		case *errdetails.QuotaFailure:
+			var subjects []string
+			for _, v := range detail.GetViolations() {
+				subjects = append(subjects, v.GetSubject())
+			}
+			span.AddEvent(statusDetailEvent, trace.WithAttributes(typ,
+				attribute.String("quota_failure.subjects", strings.Join(subjects, ",")),
+			))
+
+		case *errdetails.RetryInfo:
+			span.AddEvent(statusDetailEvent, trace.WithAttributes(typ,
+				attribute.Float64("retry_info.retry_delay", detail.GetRetryDelay().AsDuration().Seconds()),
+			))
+
+		case *errdetails.ResourceInfo:
+			span.AddEvent(statusDetailEvent, trace.WithAttributes(typ,
+				attribute.String("resource_info.type", detail.GetResourceType()),
+				attribute.String("resource_info.name", detail.GetResourceName()),
+				attribute.String("resource_info.description", detail.GetDescription()),
+			))
+
+		case *errdetails.LocalizedMessage:
+			span.AddEvent(statusDetailEvent, trace.WithAttributes(typ,
+				attribute.String("localized_message.locale", detail.GetLocale()),
+				attribute.String("localized_message.message", detail.GetMessage()),
+			))
+
+		default:
+			span.AddEvent(statusDetailEvent, trace.WithAttributes(typ))
+		}
+	}
+}
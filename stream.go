@@ -0,0 +1,87 @@
+package otelx
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+)
+
+// WithStreamMessageLatency enables a per-message latency histogram on the
+// streams wrapped by StreamServerMetricsInterceptor, in addition to the
+// whole-stream duration:
+//
+//	rpc_stream_message_interval_seconds{method, direction}
+//
+// direction is "received" for the time between consecutive messages read from
+// the client, and "sent" for the time between consecutive responses; the first
+// message of each direction is measured from the start of the stream. Long
+// gaps show up directly, whereas stream session length says nothing about how
+// responsive a bidirectional stream was.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "chat", otelx.WithStreamMessageLatency())
+func WithStreamMessageLatency() Option {
+	return func(c *config) {
+		c.streamMessageLatency = true
+	}
+}
+
+// measuredServerStream records the interval between messages of a server
+// stream in Metrics.StreamMessageHistogram.
+type measuredServerStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	metrics *Metrics
+
+	lastRecv, lastSend time.Time
+	recvAttrs          api.MeasurementOption
+	sendAttrs          api.MeasurementOption
+}
+
+// newMeasuredServerStream wraps ss for method, measuring from start.
+func newMeasuredServerStream(ss grpc.ServerStream, m *Metrics, method string, start time.Time) *measuredServerStream {
+	return &measuredServerStream{
+		ServerStream: ss,
+		ctx:          ss.Context(),
+		metrics:      m,
+		lastRecv:     start,
+		lastSend:     start,
+		recvAttrs: api.WithAttributes(
+			attribute.String("method", method),
+			attribute.String("direction", "received"),
+		),
+		sendAttrs: api.WithAttributes(
+			attribute.String("method", method),
+			attribute.String("direction", "sent"),
+		),
+	}
+}
+
+// RecvMsg records the time since the previous received message.
+func (s *measuredServerStream) RecvMsg(msg any) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.lastRecv = s.record(s.lastRecv, s.recvAttrs)
+	}
+	return err
+}
+
+// SendMsg records the time since the previous sent message.
+func (s *measuredServerStream) SendMsg(msg any) error {
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.lastSend = s.record(s.lastSend, s.sendAttrs)
+	}
+	return err
+}
+
+// record records the interval since last and returns the current time.
+func (s *measuredServerStream) record(last time.Time, attrs api.MeasurementOption) time.Time {
+	now := settings().clock.Now()
+	s.metrics.StreamMessageHistogram.Record(s.ctx, now.Sub(last).Seconds(), attrs)
+	return now
+}
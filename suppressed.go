@@ -0,0 +1,97 @@
+package otelx
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// requestMeasurements is the number of measurements otelx records for a
+// request that succeeds: the request counter and the duration histogram.
+const requestMeasurements = 2
+
+// suppressed counts the telemetry that would have been emitted while otelx
+// runs disabled, when WithSuppressedTelemetryAccounting is set.
+var suppressed struct {
+	spans        atomic.Int64
+	measurements atomic.Int64
+}
+
+// WithSuppressedTelemetryAccounting keeps local counters of the spans and
+// metric measurements the middleware, interceptors and StartSpan would have
+// produced while telemetry is disabled (OTEL_ENABLE is not true or the
+// collector is not configured). HealthHandler reports them, so the
+// collector can be sized before enabling telemetry on a high-traffic
+// service.
+//
+// Counting costs an atomic increment per request; without this option the
+// disabled middleware and interceptors add nothing to the request path.
+//
+// Example:
+//
+//	// OTEL_ENABLE=false in production for now.
+//	otelx.NewMeterProvider(ctx, "checkout", otelx.WithSuppressedTelemetryAccounting())
+//	mux.Handle("/health/telemetry", otelx.HealthHandler())
+func WithSuppressedTelemetryAccounting() Option {
+	return func(c *config) {
+		c.countSuppressed = true
+	}
+}
+
+// suppressedReport returns the suppressed telemetry counts, or nil when
+// accounting is disabled.
+func suppressedReport() map[string]int64 {
+	if !settings().countSuppressed {
+		return nil
+	}
+	return map[string]int64{
+		"spans":        suppressed.spans.Load(),
+		"measurements": suppressed.measurements.Load(),
+	}
+}
+
+// countSuppressedSpan counts a span that was not created because tracing is
+// disabled.
+func countSuppressedSpan() {
+	if settings().countSuppressed {
+		suppressed.spans.Add(1)
+	}
+}
+
+// suppressedHandler returns next wrapped to count spans or request
+// measurements that are not recorded, or next itself when accounting is
+// disabled.
+func suppressedHandler(next http.Handler, counter *atomic.Int64, n int64) http.Handler {
+	if !settings().countSuppressed {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.Add(n)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// suppressedUnary returns the unary interceptor used when telemetry is
+// disabled, counting n into counter per call when accounting is enabled.
+func suppressedUnary(counter *atomic.Int64, n int64) grpc.UnaryServerInterceptor {
+	if !settings().countSuppressed {
+		return passthroughUnary
+	}
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		counter.Add(n)
+		return handler(ctx, req)
+	}
+}
+
+// suppressedStream is the streaming counterpart of suppressedUnary.
+func suppressedStream(counter *atomic.Int64, n int64) grpc.StreamServerInterceptor {
+	if !settings().countSuppressed {
+		return passthroughStream
+	}
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		counter.Add(n)
+		return handler(srv, ss)
+	}
+}
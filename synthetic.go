@@ -0,0 +1,106 @@
+package otelx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SyntheticMode selects how requests from synthetic monitors and bots are
+// treated.
+type SyntheticMode int
+
+const (
+	// SyntheticLabel records synthetic requests normally but marks them with
+	// synthetic="true" on request metrics (synthetic="false" for real users)
+	// and with the synthetic span attribute.
+	SyntheticLabel SyntheticMode = iota + 1
+
+	// SyntheticExclude records neither request metrics nor spans for
+	// synthetic requests.
+	SyntheticExclude
+)
+
+// DefaultSyntheticUserAgents are the case-insensitive User-Agent substrings
+// identifying common uptime checkers, load balancer probes and crawlers.
+var DefaultSyntheticUserAgents = []string{
+	"pingdom", "uptimerobot", "statuscake", "site24x7", "newrelicpinger",
+	"datadogsynthetics", "checkly", "kube-probe", "elb-healthchecker",
+	"googlehc", "bot", "spider", "crawler",
+}
+
+// WithSyntheticTraffic enables detection of synthetic monitors and bots in
+// the HTTP middleware, so uptime checkers do not skew real-user latency. A
+// request is synthetic when its User-Agent contains one of the configured
+// substrings (DefaultSyntheticUserAgents unless WithSyntheticUserAgents is
+// used) or it carries one of the WithSyntheticHeaders markers.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "storefront",
+//	    otelx.WithSyntheticTraffic(otelx.SyntheticExclude),
+//	    otelx.WithSyntheticHeaders("X-Synthetic-Check"),
+//	)
+func WithSyntheticTraffic(mode SyntheticMode) Option {
+	return func(c *config) {
+		c.syntheticMode = mode
+	}
+}
+
+// WithSyntheticUserAgents replaces the User-Agent substrings identifying
+// synthetic requests. Matching is case-insensitive.
+func WithSyntheticUserAgents(substrings ...string) Option {
+	return func(c *config) {
+		c.syntheticUserAgents = make([]string, len(substrings))
+		for i, s := range substrings {
+			c.syntheticUserAgents[i] = strings.ToLower(s)
+		}
+	}
+}
+
+// WithSyntheticHeaders marks requests carrying any of headers, whatever their
+// value, as synthetic.
+func WithSyntheticHeaders(headers ...string) Option {
+	return func(c *config) {
+		c.syntheticHeaders = make([]string, len(headers))
+		for i, h := range headers {
+			c.syntheticHeaders[i] = http.CanonicalHeaderKey(h)
+		}
+	}
+}
+
+// isSynthetic reports whether r comes from a synthetic monitor or bot. It is
+// always false when detection is disabled.
+func isSynthetic(r *http.Request) bool {
+	if settings().syntheticMode == 0 {
+		return false
+	}
+
+	for _, h := range settings().syntheticHeaders {
+		if _, ok := r.Header[h]; ok {
+			return true
+		}
+	}
+
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return false
+	}
+	for _, s := range settings().syntheticUserAgents {
+		if strings.Contains(ua, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// syntheticDimension returns the synthetic metric dimension for r: "true" or
+// "false" in SyntheticLabel mode, and empty otherwise.
+func syntheticDimension(r *http.Request) string {
+	if settings().syntheticMode != SyntheticLabel {
+		return ""
+	}
+	if isSynthetic(r) {
+		return "true"
+	}
+	return "false"
+}
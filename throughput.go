@@ -0,0 +1,129 @@
+package otelx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// throughputWindow is how often the throughput sampler re-estimates each
+// endpoint's rate and adjusts its probability.
+const throughputWindow = time.Second
+
+// maxThroughputEndpoints bounds the endpoints tracked individually by the
+// throughput sampler. Further span names share a single budget.
+const maxThroughputEndpoints = 1024
+
+// endpointRate is the throughput sampler's state for one endpoint.
+type endpointRate struct {
+	windowStart time.Time
+	seen        float64 // root spans seen in the current window
+	rate        float64 // smoothed root spans per second
+	probability float64
+}
+
+// throughputSampler samples root spans so that each endpoint, identified by
+// its span name, yields about target sampled traces per second.
+type throughputSampler struct {
+	target float64
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointRate
+	overflow  endpointRate
+}
+
+var _ sdktrace.Sampler = (*throughputSampler)(nil)
+
+// NewThroughputSampler returns a sampler targeting perSecond sampled traces per
+// second for every endpoint (span name). Each endpoint's probability is
+// re-estimated every second from its smoothed traffic, so quiet endpoints
+// keep every trace while hot endpoints are sampled down as traffic grows.
+//
+// The decision is derived from the trace ID, like TraceIDRatioBased. The
+// sampler decides for root spans; wrap it in sdktrace.ParentBased (as
+// WithThroughputSampling does) to keep traces complete.
+func NewThroughputSampler(perSecond float64) sdktrace.Sampler {
+	return &throughputSampler{
+		target:    perSecond,
+		endpoints: make(map[string]*endpointRate),
+	}
+}
+
+// WithThroughputSampling replaces the ratio-based sampler with a parent-based
+// NewThroughputSampler(perSecond).
+//
+// Example:
+//
+//	otelx.NewTraceProvider(ctx, "checkout", otelx.WithThroughputSampling(5))
+func WithThroughputSampling(perSecond float64) Option {
+	return WithSampler(sdktrace.ParentBased(NewThroughputSampler(perSecond)))
+}
+
+// ShouldSample counts p against its endpoint and samples it with the
+// endpoint's current probability.
+func (s *throughputSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	probability := s.observe(p.Name)
+
+	decision := sdktrace.Drop
+	// Same mapping as TraceIDRatioBased: the low 63 bits of the trace ID
+	// compared against the probability scaled to that range.
+	x := binary.BigEndian.Uint64(p.TraceID[8:16]) >> 1
+	if probability >= 1 || x < uint64(probability*(1<<63)) {
+		decision = sdktrace.RecordAndSample
+	}
+
+	return sdktrace.SamplingResult{
+		Decision:   decision,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+}
+
+// observe records a root span for endpoint and returns the endpoint's
+// sampling probability.
+func (s *throughputSampler) observe(endpoint string) float64 {
+	now := settings().clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.endpoints[endpoint]
+	if !ok {
+		if len(s.endpoints) >= maxThroughputEndpoints {
+			e = &s.overflow
+		} else {
+			e = &endpointRate{}
+			s.endpoints[endpoint] = e
+		}
+	}
+
+	if e.windowStart.IsZero() {
+		e.windowStart = now
+		e.probability = 1
+	} else if elapsed := now.Sub(e.windowStart); elapsed >= throughputWindow {
+		observed := e.seen / elapsed.Seconds()
+		if e.rate == 0 {
+			e.rate = observed
+		} else {
+			e.rate = (e.rate + observed) / 2
+		}
+
+		e.probability = 1
+		if e.rate > s.target {
+			e.probability = s.target / e.rate
+		}
+		e.seen = 0
+		e.windowStart = now
+	}
+
+	e.seen++
+	return e.probability
+}
+
+// Description identifies the sampler in DebugHandler.
+func (s *throughputSampler) Description() string {
+	return fmt.Sprintf("ThroughputSampler{%g/s per endpoint}", s.target)
+}
@@ -0,0 +1,141 @@
+package otelx
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// WithTLSMetrics records inbound TLS details for services terminating TLS
+// themselves:
+//
+//   - tls_handshake_duration_seconds{tls_version, cipher}, from servers whose
+//     tls.Config is wrapped with InstrumentTLSConfig (Serve does it for the
+//     configuration set through WithServer)
+//   - tls.protocol.version, tls.cipher and tls.resumed attributes on the
+//     request spans of HTTPS requests
+//
+// Grouping handshakes by version finds the clients still on deprecated TLS
+// versions before they are turned off.
+//
+// Example:
+//
+//	otelx.NewMeterProvider(ctx, "edge", otelx.WithTLSMetrics())
+//	otelx.Serve(":443", mux, otelx.WithServer(func(s *http.Server) {
+//	    s.TLSConfig = tlsConfig
+//	}))
+func WithTLSMetrics() Option {
+	return func(c *config) {
+		c.tlsMetrics = true
+	}
+}
+
+// InstrumentTLSConfig returns a copy of cfg recording the duration, protocol
+// version and cipher suite of each inbound handshake into
+// tls_handshake_duration_seconds. The duration runs from the ClientHello to
+// the verification of the connection, covering the key exchange round trips
+// and certificate checks. A GetConfigForClient or VerifyConnection callback
+// set on cfg still runs.
+//
+// Each handshake uses a shallow copy of the configuration; session tickets
+// keep working since the copies use cfg's ticket keys.
+//
+// Example:
+//
+//	srv := &http.Server{Addr: ":443", Handler: handler}
+//	srv.TLSConfig = otelx.InstrumentTLSConfig(tlsConfig)
+func InstrumentTLSConfig(cfg *tls.Config) *tls.Config {
+	base := cfg.Clone()
+	getConfig := cfg.GetConfigForClient
+
+	base.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		start := time.Now()
+
+		conf := cfg
+		if getConfig != nil {
+			c, err := getConfig(hello)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				conf = c
+			}
+		}
+
+		conf = conf.Clone()
+		conf.GetConfigForClient = nil
+		verify := conf.VerifyConnection
+		conf.VerifyConnection = func(cs tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(cs); err != nil {
+					return err
+				}
+			}
+			recordTLSHandshake(cs, time.Since(start))
+			return nil
+		}
+		return conf, nil
+	}
+	return base
+}
+
+// recordTLSHandshake records a completed handshake of duration d.
+func recordTLSHandshake(cs tls.ConnectionState, d time.Duration) {
+	if !metricsEnabled.Load() || telemetryPaused.Load() {
+		return
+	}
+	metrics.TLSHandshakeHistogram.Record(context.Background(), d.Seconds(), tlsMetricAttrs(cs))
+}
+
+// tlsKey identifies a protocol version and cipher suite pair.
+type tlsKey struct {
+	version, cipher uint16
+}
+
+// tlsAttrSets caches the metric attribute option of each tlsKey; clients
+// negotiate a handful of combinations.
+var tlsAttrSets sync.Map // tlsKey -> api.MeasurementOption
+
+// tlsMetricAttrs returns the tls_version and cipher attribute option of cs.
+func tlsMetricAttrs(cs tls.ConnectionState) api.MeasurementOption {
+	key := tlsKey{version: cs.Version, cipher: cs.CipherSuite}
+	if opt, ok := tlsAttrSets.Load(key); ok {
+		return opt.(api.MeasurementOption)
+	}
+	opt := api.WithAttributeSet(attribute.NewSet(
+		attribute.String("tls_version", tlsVersion(cs.Version)),
+		attribute.String("cipher", tls.CipherSuiteName(cs.CipherSuite)),
+	))
+	tlsAttrSets.Store(key, opt)
+	return opt
+}
+
+// tlsAttributes returns the semantic convention span attributes of cs.
+func tlsAttributes(cs tls.ConnectionState) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("tls.protocol.name", "tls"),
+		attribute.String("tls.protocol.version", tlsVersion(cs.Version)),
+		attribute.String("tls.cipher", tls.CipherSuiteName(cs.CipherSuite)),
+		attribute.Bool("tls.resumed", cs.DidResume),
+	}
+}
+
+// tlsVersion returns the protocol version as the semantic conventions spell
+// it ("1.2", "1.3").
+func tlsVersion(v uint16) string {
+	switch v {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	}
+	return tls.VersionName(v)
+}
@@ -0,0 +1,167 @@
+package otelx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInstrumentTLSConfig(t *testing.T) {
+	reader := useTestMetrics(t)
+
+	// Borrow the test certificate of httptest.
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	certs := ts.TLS.Certificates
+	ts.Close()
+
+	errRejected := errors.New("client rejected")
+	tests := []struct {
+		name       string
+		maxVersion uint16
+		reject     bool
+		want       map[string]uint64
+	}{
+		{name: "tls 1.3", maxVersion: tls.VersionTLS13, want: map[string]uint64{"1.3": 1}},
+		{name: "tls 1.2", maxVersion: tls.VersionTLS12, want: map[string]uint64{"1.3": 1, "1.2": 1}},
+		{name: "verification failed", maxVersion: tls.VersionTLS13, reject: true, want: map[string]uint64{"1.3": 1, "1.2": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified bool
+			cfg := InstrumentTLSConfig(&tls.Config{
+				Certificates: certs,
+				VerifyConnection: func(tls.ConnectionState) error {
+					verified = true
+					if tt.reject {
+						return errRejected
+					}
+					return nil
+				},
+			})
+
+			err := handshake(cfg, &tls.Config{InsecureSkipVerify: true, MaxVersion: tt.maxVersion})
+			if tt.reject != (err != nil) {
+				t.Fatalf("handshake() = %v, want rejected = %v", err, tt.reject)
+			}
+			if !verified {
+				t.Error("VerifyConnection of the configuration was not called")
+			}
+
+			got := handshakeCounts(t, reader)
+			if len(got) != len(tt.want) {
+				t.Fatalf("handshakes by version = %v, want %v", got, tt.want)
+			}
+			for v, n := range tt.want {
+				if got[v] != n {
+					t.Errorf("handshakes by version = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// handshake runs a TLS handshake between a server using server and a client
+// using client over a loopback connection, returning the server's error.
+func handshake(server, client *tls.Config) error {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c, err := tls.Dial("tcp", l.Addr().String(), client)
+		if err == nil {
+			c.Close()
+		}
+	}()
+
+	conn, err := l.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = tls.Server(conn, server).Handshake()
+	conn.Close()
+	<-done
+	return err
+}
+
+// handshakeCounts returns the number of recorded handshakes by TLS version.
+func handshakeCounts(t *testing.T, reader sdkmetric.Reader) map[string]uint64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]uint64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != instrumentName("tls_handshake_duration_seconds") {
+				continue
+			}
+			for _, p := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				v, _ := p.Attributes.Value("tls_version")
+				counts[v.AsString()] += p.Count
+			}
+		}
+	}
+	return counts
+}
+
+func TestTLSAttributes(t *testing.T) {
+	cs := tls.ConnectionState{
+		Version:     tls.VersionTLS12,
+		CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		DidResume:   true,
+	}
+	want := []attribute.KeyValue{
+		attribute.String("tls.protocol.name", "tls"),
+		attribute.String("tls.protocol.version", "1.2"),
+		attribute.String("tls.cipher", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"),
+		attribute.Bool("tls.resumed", true),
+	}
+
+	got := tlsAttributes(cs)
+	if len(got) != len(want) {
+		t.Fatalf("tlsAttributes() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tlsAttributes()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTLSVersion(t *testing.T) {
+	tests := []struct {
+		version uint16
+		want    string
+	}{
+		{version: tls.VersionTLS10, want: "1.0"},
+		{version: tls.VersionTLS11, want: "1.1"},
+		{version: tls.VersionTLS12, want: "1.2"},
+		{version: tls.VersionTLS13, want: "1.3"},
+		{version: 0x0300, want: "SSLv3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tlsVersion(tt.version); got != tt.want {
+				t.Errorf("tlsVersion(%#x) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
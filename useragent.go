@@ -0,0 +1,140 @@
+package otelx
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// maxClientVersions bounds the distinct client versions recorded as a metric
+// dimension. Further versions are grouped under "other".
+const maxClientVersions = 50
+
+// overflowValue replaces attribute values beyond a cardinality limit.
+const overflowValue = "other"
+
+// WithUserAgent records the request User-Agent on SERVER spans as
+// user_agent.original.
+//
+// When normalize is true, the User-Agent is also reduced to a small set of
+// client families (chrome, firefox, safari, edge, android, ios, okhttp, curl,
+// go, python, bot, other), recorded as user_agent.name on spans and as the
+// user_agent dimension on request metrics.
+func WithUserAgent(normalize bool) Option {
+	return func(c *config) {
+		c.userAgent = true
+		c.normalizeUserAgent = normalize
+	}
+}
+
+// WithClientVersionHeader records the value of header (e.g. "X-App-Version")
+// as the client.version span attribute and as the client_version dimension on
+// request metrics, so latency and errors can be tracked per mobile app
+// version.
+//
+// The metric dimension is bounded: after 50 distinct versions, new ones are
+// recorded as "other".
+func WithClientVersionHeader(header string) Option {
+	return func(c *config) {
+		c.clientVersionHeader = http.CanonicalHeaderKey(header)
+	}
+}
+
+// userAgentFamilies maps User-Agent substrings to normalized families. Order
+// matters: more specific tokens come first.
+var userAgentFamilies = []struct {
+	token  string
+	family string
+}{
+	{"bot", "bot"},
+	{"spider", "bot"},
+	{"crawler", "bot"},
+	{"okhttp", "okhttp"},
+	{"cfnetwork", "ios"},
+	{"iphone", "ios"},
+	{"ipad", "ios"},
+	{"dalvik", "android"},
+	{"android", "android"},
+	{"curl/", "curl"},
+	{"go-http-client", "go"},
+	{"python", "python"},
+	{"edg/", "edge"},
+	{"firefox/", "firefox"},
+	{"chrome/", "chrome"},
+	{"safari/", "safari"},
+}
+
+// normalizeUserAgent reduces ua to a low-cardinality client family.
+func normalizeUserAgent(ua string) string {
+	if ua == "" {
+		return "unknown"
+	}
+	lower := strings.ToLower(ua)
+	for _, f := range userAgentFamilies {
+		if strings.Contains(lower, f.token) {
+			return f.family
+		}
+	}
+	return overflowValue
+}
+
+// valueLimiter admits at most max distinct values, replacing any further
+// value with "other". It keeps metric dimensions derived from client input
+// bounded.
+type valueLimiter struct {
+	max int
+
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+// newValueLimiter returns a valueLimiter admitting max distinct values.
+func newValueLimiter(max int) *valueLimiter {
+	return &valueLimiter{max: max, seen: make(map[string]struct{})}
+}
+
+// admit returns v if it is already known or there is room for it, and
+// "other" otherwise.
+func (l *valueLimiter) admit(v string) string {
+	l.mu.RLock()
+	_, ok := l.seen[v]
+	full := len(l.seen) >= l.max
+	l.mu.RUnlock()
+	if ok {
+		return v
+	}
+	if full {
+		return overflowValue
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[v]; ok {
+		return v
+	}
+	if len(l.seen) >= l.max {
+		return overflowValue
+	}
+	l.seen[v] = struct{}{}
+	return v
+}
+
+// clientVersions bounds the client_version metric dimension.
+var clientVersions = newValueLimiter(maxClientVersions)
+
+// clientDimensions returns the user agent family and client version
+// dimensions for r, according to the configured options. Empty values mean
+// the dimension is disabled.
+func clientDimensions(r *http.Request) (userAgent, clientVersion string) {
+	if settings().userAgent && settings().normalizeUserAgent {
+		userAgent = normalizeUserAgent(r.UserAgent())
+	}
+	if settings().clientVersionHeader != "" {
+		if v := r.Header.Get(settings().clientVersionHeader); v != "" {
+			clientVersion = clientVersions.admit(v)
+		} else {
+			clientVersion = "unknown"
+		}
+	}
+	return userAgent, clientVersion
+}
@@ -20,7 +20,13 @@ import "os"
 //
 // This prevents silent misconfiguration where OTEL is enabled but no collector
 // endpoint is provided.
+//
+// In the local profile without a collector, where telemetry is only written
+// to stdout, it reports true unless OTEL_ENABLE=false (see WithProfile).
 func IsEnabled() bool {
+	if localStandalone(*settings()) {
+		return true
+	}
 	_, ok := os.LookupEnv("OTEL_COLLECTOR_ENDPOINT")
 	return os.Getenv("OTEL_ENABLE") == "true" && ok
 }
@@ -0,0 +1,237 @@
+package otelx
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// defaultVerifyTimeout bounds Verify when ctx has no deadline.
+const defaultVerifyTimeout = 10 * time.Second
+
+// VerifyCheck is the result of a single step performed by Verify.
+type VerifyCheck struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// VerifyReport is the structured result returned by Verify.
+type VerifyReport struct {
+	OK     bool          `json:"ok"`
+	Checks []VerifyCheck `json:"checks"`
+}
+
+// Err returns an error describing the failed checks, or nil if all checks
+// passed.
+func (r VerifyReport) Err() error {
+	var errs []error
+	for _, c := range r.Checks {
+		if !c.OK {
+			errs = append(errs, fmt.Errorf("%s: %s", c.Name, c.Detail))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String renders the report as one line per check.
+func (r VerifyReport) String() string {
+	var b strings.Builder
+	for _, c := range r.Checks {
+		mark := "ok"
+		if !c.OK {
+			mark = "FAIL"
+		}
+		fmt.Fprintf(&b, "[%s] %s (%s)", mark, c.Name, c.Duration.Round(time.Millisecond))
+		if c.Detail != "" {
+			fmt.Fprintf(&b, ": %s", c.Detail)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Verify validates the telemetry pipeline end to end and returns a structured
+// report. It checks that:
+//
+//  1. telemetry is enabled and OTEL_COLLECTOR_ENDPOINT is configured
+//  2. the collector is reachable and the connection handshake succeeds
+//  3. a test span is accepted by the collector
+//  4. a test metric is accepted by the collector
+//
+// Later checks are skipped when an earlier one fails. Verify is meant for CI
+// smoke tests and operators confirming a service's pipeline before it takes
+// traffic:
+//
+//	report := otelx.Verify(ctx)
+//	if !report.OK {
+//	    log.Fatalf("telemetry pipeline broken:\n%s", report)
+//	}
+//
+// If ctx has no deadline, Verify gives up after 10 seconds.
+func Verify(ctx context.Context) VerifyReport {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultVerifyTimeout)
+		defer cancel()
+	}
+
+	report := VerifyReport{OK: true}
+	run := func(name string, fn func() (string, error)) bool {
+		start := time.Now()
+		detail, err := fn()
+		check := VerifyCheck{Name: name, OK: err == nil, Detail: detail, Duration: time.Since(start)}
+		if err != nil {
+			check.Detail = err.Error()
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, check)
+		return err == nil
+	}
+
+	var conn *grpc.ClientConn
+	ok := run("configuration", func() (string, error) {
+		var err error
+		conn, err = initCollector(*settings())
+		if err != nil {
+			return "", err
+		}
+		return "endpoint " + os.Getenv("OTEL_COLLECTOR_ENDPOINT"), nil
+	})
+	if !ok {
+		return report
+	}
+
+	ok = run("connection", func() (string, error) {
+		return waitReady(ctx, conn)
+	})
+	if !ok {
+		return report
+	}
+
+	res := verifyResource(ctx)
+
+	run("export span", func() (string, error) {
+		return "", exportTestSpan(ctx, conn, res)
+	})
+	run("export metric", func() (string, error) {
+		return "", exportTestMetric(ctx, conn, res)
+	})
+
+	return report
+}
+
+// waitReady connects conn and waits until it is ready or ctx expires. gRPC
+// passes through TransientFailure between reconnection attempts, so only a
+// connection that was shut down fails before ctx does.
+func waitReady(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return "connection ready", nil
+		case connectivity.Shutdown:
+			return "", fmt.Errorf("collector connection closed (state %s)", state)
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			if state == connectivity.TransientFailure {
+				return "", fmt.Errorf("collector unreachable (state %s): %w", state, ctx.Err())
+			}
+			return "", fmt.Errorf("timed out waiting for collector (state %s): %w", state, ctx.Err())
+		}
+	}
+}
+
+// verifyResource returns the resource used for test telemetry, preferring the
+// one built by the running providers.
+func verifyResource(ctx context.Context) *resource.Resource {
+	pipelineInfo.mu.RLock()
+	res := pipelineInfo.resource
+	pipelineInfo.mu.RUnlock()
+	if res != nil {
+		return res
+	}
+
+	res, err := newResource(ctx, "otelx-verify")
+	if err != nil {
+		return resource.Default()
+	}
+	return res
+}
+
+// exportTestSpan sends a single span named otelx.verify through conn.
+func exportTestSpan(ctx context.Context, conn *grpc.ClientConn, res *resource.Resource) error {
+	exp, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = exp.Shutdown(context.Background()) }()
+
+	var tid trace.TraceID
+	var sid trace.SpanID
+	_, _ = rand.Read(tid[:])
+	_, _ = rand.Read(sid[:])
+
+	now := time.Now()
+	span := &spanSnapshot{
+		name: "otelx.verify",
+		spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    tid,
+			SpanID:     sid,
+			TraceFlags: trace.FlagsSampled,
+		}),
+		kind:     trace.SpanKindInternal,
+		start:    now,
+		end:      now,
+		resource: res,
+		scope:    instrumentation.Scope{Name: instrumentationName},
+	}
+
+	return exp.ExportSpans(ctx, []sdktrace.ReadOnlySpan{span})
+}
+
+// exportTestMetric sends a single otelx_verify gauge point through conn.
+func exportTestMetric(ctx context.Context, conn *grpc.ClientConn, res *resource.Resource) error {
+	exp, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = exp.Shutdown(context.Background()) }()
+
+	now := time.Now()
+	rm := &metricdata.ResourceMetrics{
+		Resource: res,
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope: instrumentation.Scope{Name: instrumentationName},
+			Metrics: []metricdata.Metrics{{
+				Name:        "otelx_verify",
+				Description: "Test metric emitted by otelx.Verify",
+				Data: metricdata.Gauge[int64]{
+					DataPoints: []metricdata.DataPoint[int64]{{
+						StartTime: now,
+						Time:      now,
+						Value:     1,
+					}},
+				},
+			}},
+		}},
+	}
+
+	return exp.Export(ctx, rm)
+}